@@ -9,7 +9,6 @@ import (
 	"syscall"
 	"time"
 
-	_ "github.com/go-modkit/modkit/examples/hello-mysql/docs"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/httpserver"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/lifecycle"
 	configmodule "github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/config"
@@ -18,10 +17,6 @@ import (
 	"github.com/go-modkit/modkit/modkit/module"
 )
 
-// @title hello-mysql API
-// @version 0.1
-// @description Example modkit service with MySQL.
-// @BasePath /api/v1
 func main() {
 	boot, handler, err := httpserver.BuildAppHandler()
 	if err != nil {
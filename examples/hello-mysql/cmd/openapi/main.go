@@ -0,0 +1,24 @@
+// Command openapi writes the OpenAPI document generated from the registered
+// routes. CI regenerates it and fails when the checked-in copy differs.
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/go-modkit/modkit/examples/hello-mysql/internal/httpserver"
+	"github.com/go-modkit/modkit/modkit/http/openapi"
+)
+
+func main() {
+	out := flag.String("o", "docs/openapi.json", "output file")
+	flag.Parse()
+
+	doc, err := httpserver.BuildOpenAPI()
+	if err != nil {
+		log.Fatalf("build openapi: %v", err)
+	}
+	if err := openapi.WriteFile(*out, doc); err != nil {
+		log.Fatalf("write openapi: %v", err)
+	}
+}
@@ -1,29 +0,0 @@
-package httpapi
-
-import (
-	"encoding/json"
-	"fmt"
-	"net/http"
-)
-
-type Problem struct {
-	Type     string `json:"type"`
-	Title    string `json:"title"`
-	Status   int    `json:"status"`
-	Detail   string `json:"detail"`
-	Instance string `json:"instance"`
-}
-
-func WriteProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
-	p := Problem{
-		Type:     fmt.Sprintf("https://httpstatuses.com/%d", status),
-		Title:    http.StatusText(status),
-		Status:   status,
-		Detail:   detail,
-		Instance: r.URL.Path,
-	}
-
-	w.Header().Set("Content-Type", "application/problem+json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(p)
-}
@@ -1,24 +1,66 @@
 package httpserver
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/go-modkit/modkit/examples/hello-mysql/internal/lifecycle"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/app"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/platform/logging"
 	modkithttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/http/openapi"
 	"github.com/go-modkit/modkit/modkit/kernel"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
-var registerRoutes = modkithttp.RegisterRoutes
+var registerApp = modkithttp.RegisterApp
+
+// OpenAPIConfig describes the API in the generated OpenAPI document.
+var OpenAPIConfig = openapi.Config{
+	Title:       "hello-mysql API",
+	Version:     "0.1",
+	Description: "Example modkit service with MySQL.",
+	Path:        "/openapi.json",
+}
 
 func BuildAppHandler() (*kernel.App, http.Handler, error) {
+	boot, router, _, err := buildRouter()
+	if err != nil {
+		return boot, nil, err
+	}
+	return boot, router, nil
+}
+
+// BuildOpenAPI generates the OpenAPI document from the registered routes. The
+// app bootstrapped to collect them is shut down before it returns.
+func BuildOpenAPI() (doc *openapi.Document, err error) {
+	boot, _, root, err := buildRouter()
+	if boot != nil {
+		defer func() {
+			err = errors.Join(err, closeApp(context.Background(), boot))
+		}()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return openapi.Build(modkithttp.Routes(root), OpenAPIConfig), nil
+}
+
+// closeApp runs the app's cleanup hooks and closes its providers, in the same
+// order as the api command on shutdown.
+func closeApp(ctx context.Context, boot *kernel.App) error {
+	hooks := lifecycle.FromFuncs(boot.CleanupHooks())
+	return lifecycle.RunCleanup(ctx, append([]lifecycle.CleanupHook{boot.CloseContext}, hooks...))
+}
+
+func buildRouter() (*kernel.App, chi.Router, modkithttp.Router, error) {
 	mod := app.NewModule()
 	boot, err := kernel.Bootstrap(mod)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	logger := logging.New().With(slog.String("scope", "httpserver"))
@@ -26,52 +68,19 @@ func BuildAppHandler() (*kernel.App, http.Handler, error) {
 	root := modkithttp.AsRouter(router)
 	root.Use(modkithttp.RequestLogger(logger))
 
-	corsAny, err := boot.Get(app.CorsMiddlewareToken)
-	if err != nil {
-		return boot, nil, err
-	}
-	cors, ok := corsAny.(func(http.Handler) http.Handler)
-	if !ok {
-		return boot, nil, fmt.Errorf("cors middleware: expected func(http.Handler) http.Handler, got %T", corsAny)
-	}
-
-	rateLimitAny, err := boot.Get(app.RateLimitMiddlewareToken)
-	if err != nil {
-		return boot, nil, err
-	}
-	rateLimit, ok := rateLimitAny.(func(http.Handler) http.Handler)
-	if !ok {
-		return boot, nil, fmt.Errorf("rate limit middleware: expected func(http.Handler) http.Handler, got %T", rateLimitAny)
+	if err := registerApp(root, boot); err != nil {
+		return boot, nil, nil, err
 	}
 
-	timingAny, err := boot.Get(app.TimingMiddlewareToken)
-	if err != nil {
-		return boot, nil, err
-	}
-	timing, ok := timingAny.(func(http.Handler) http.Handler)
-	if !ok {
-		return boot, nil, fmt.Errorf("timing middleware: expected func(http.Handler) http.Handler, got %T", timingAny)
-	}
-
-	var registerErr error
-	root.Group("/api/v1", func(r modkithttp.Router) {
-		r.Use(cors)
-		r.Use(rateLimit)
-		r.Use(timing)
-		if err := registerRoutes(r, boot.Controllers); err != nil {
-			registerErr = err
-		}
-	})
-	if registerErr != nil {
-		return boot, nil, registerErr
-	}
-	router.Get("/swagger/*", httpSwagger.WrapHandler)
-	router.Get("/docs/*", httpSwagger.WrapHandler)
+	openapi.Serve(root, OpenAPIConfig)
+	swaggerUI := httpSwagger.Handler(httpSwagger.URL(OpenAPIConfig.Path))
+	router.Get("/swagger/*", swaggerUI)
+	router.Get("/docs/*", swaggerUI)
 	router.Get("/docs", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/docs/index.html", http.StatusMovedPermanently)
 	}))
 
-	return boot, router, nil
+	return boot, router, root, nil
 }
 
 func BuildHandler() (http.Handler, error) {
@@ -2,14 +2,20 @@ package httpserver
 
 import (
 	"bytes"
+	"database/sql"
 	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/database"
 	modkithttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/http/openapi"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
 )
 
 func TestBuildHandler_LogsRequest(t *testing.T) {
@@ -68,11 +74,11 @@ func TestBuildAppHandler_ReturnsAppAndHandler(t *testing.T) {
 }
 
 func TestBuildAppHandler_ReturnsBootOnRouteError(t *testing.T) {
-	origRegister := registerRoutes
-	registerRoutes = func(_ modkithttp.Router, _ map[string]any) error {
+	origRegister := registerApp
+	registerApp = func(_ modkithttp.Router, _ *kernel.App) error {
 		return errors.New("routes failed")
 	}
-	defer func() { registerRoutes = origRegister }()
+	defer func() { registerApp = origRegister }()
 
 	boot, handler, err := BuildAppHandler()
 	if err == nil {
@@ -102,8 +108,15 @@ func TestBuildHandler_UsesMiddlewareProviders(t *testing.T) {
 	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "http://localhost:3000" {
 		t.Fatalf("expected Access-Control-Allow-Origin header, got %q", got)
 	}
-	if got := rec.Header().Get("Access-Control-Allow-Methods"); got == "" {
-		t.Fatalf("expected Access-Control-Allow-Methods header, got empty")
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodOptions, "/api/v1/health", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	h.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "GET" {
+		t.Fatalf("expected Access-Control-Allow-Methods header on preflight, got %q", got)
 	}
 }
 
@@ -124,3 +137,61 @@ func TestBuildHandler_DocsRedirect(t *testing.T) {
 		t.Fatalf("expected redirect to /docs/index.html, got %q", got)
 	}
 }
+
+func TestBuildHandler_ServesOpenAPI(t *testing.T) {
+	h, err := BuildHandler()
+	if err != nil {
+		t.Fatalf("build handler: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`"/api/v1/users/{id}"`)) {
+		t.Fatalf("expected users routes in document, got %s", rec.Body.String())
+	}
+}
+
+func TestOpenAPIDocumentIsCurrent(t *testing.T) {
+	doc, err := BuildOpenAPI()
+	if err != nil {
+		t.Fatalf("build openapi: %v", err)
+	}
+	var generated bytes.Buffer
+	if err := openapi.Write(&generated, doc); err != nil {
+		t.Fatalf("write openapi: %v", err)
+	}
+
+	checkedIn, err := os.ReadFile("../../docs/openapi.json")
+	if err != nil {
+		t.Fatalf("read docs/openapi.json: %v", err)
+	}
+	if !bytes.Equal(generated.Bytes(), checkedIn) {
+		t.Fatalf("docs/openapi.json is out of date; run make openapi")
+	}
+}
+
+func TestBuildOpenAPI_ClosesApp(t *testing.T) {
+	origRegister := registerApp
+	var boot *kernel.App
+	registerApp = func(r modkithttp.Router, app *kernel.App) error {
+		boot = app
+		return origRegister(r, app)
+	}
+	defer func() { registerApp = origRegister }()
+
+	if _, err := BuildOpenAPI(); err != nil {
+		t.Fatalf("build openapi: %v", err)
+	}
+	db, err := module.Get[*sql.DB](boot, database.TokenDB)
+	if err != nil {
+		t.Fatalf("get db: %v", err)
+	}
+	if err := db.Ping(); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Fatalf("expected database to be closed, got %v", err)
+	}
+}
@@ -1,60 +0,0 @@
-package middleware
-
-import (
-	"net/http"
-	"strings"
-)
-
-type CORSConfig struct {
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
-}
-
-func NewCORS(cfg CORSConfig) func(http.Handler) http.Handler {
-	origins := normalize(cfg.AllowedOrigins)
-	methods := normalize(cfg.AllowedMethods)
-	headers := normalize(cfg.AllowedHeaders)
-	allowMethods := strings.Join(methods, ", ")
-	allowHeaders := strings.Join(headers, ", ")
-
-	return func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			origin := r.Header.Get("Origin")
-			if origin != "" && contains(origins, origin) {
-				h := w.Header()
-				h.Set("Access-Control-Allow-Origin", origin)
-				h.Add("Vary", "Origin")
-				h.Set("Access-Control-Allow-Methods", allowMethods)
-				h.Set("Access-Control-Allow-Headers", allowHeaders)
-			}
-
-			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
-				w.WriteHeader(http.StatusNoContent)
-				return
-			}
-
-			next.ServeHTTP(w, r)
-		})
-	}
-}
-
-func normalize(values []string) []string {
-	out := make([]string, 0, len(values))
-	for _, v := range values {
-		trimmed := strings.TrimSpace(v)
-		if trimmed != "" {
-			out = append(out, trimmed)
-		}
-	}
-	return out
-}
-
-func contains(values []string, target string) bool {
-	for _, value := range values {
-		if value == target {
-			return true
-		}
-	}
-	return false
-}
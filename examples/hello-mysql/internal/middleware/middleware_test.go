@@ -29,7 +29,7 @@ func (t *testLogger) With(...any) modkitlogging.Logger {
 }
 
 func TestCORS_AddsHeaders(t *testing.T) {
-	cors := NewCORS(CORSConfig{
+	cors := modkithttp.CORS(modkithttp.CORSConfig{
 		AllowedOrigins: []string{"http://localhost:3000"},
 		AllowedMethods: []string{"GET", "POST"},
 		AllowedHeaders: []string{"Content-Type", "Authorization"},
@@ -48,16 +48,16 @@ func TestCORS_AddsHeaders(t *testing.T) {
 	if rec.Header().Get("Access-Control-Allow-Origin") != "http://localhost:3000" {
 		t.Fatalf("expected allow origin header to be set")
 	}
-	if rec.Header().Get("Access-Control-Allow-Methods") != "GET, POST" {
-		t.Fatalf("expected allow methods header to be set")
+	if rec.Header().Get("Vary") != "Origin" {
+		t.Fatalf("expected Vary: Origin, got %q", rec.Header().Get("Vary"))
 	}
-	if got := rec.Header().Get("Access-Control-Allow-Headers"); got != "Content-Type, Authorization" {
-		t.Fatalf("expected allow headers header to be set, got %q", got)
+	if rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Fatalf("expected allow methods only on preflight responses")
 	}
 }
 
 func TestCORS_PreflightShortCircuits(t *testing.T) {
-	cors := NewCORS(CORSConfig{
+	cors := modkithttp.CORS(modkithttp.CORSConfig{
 		AllowedOrigins: []string{"http://localhost:3000"},
 		AllowedMethods: []string{"GET", "POST"},
 		AllowedHeaders: []string{"Content-Type"},
@@ -94,7 +94,7 @@ func TestCORS_PreflightShortCircuits(t *testing.T) {
 }
 
 func TestRateLimit_BlocksAfterBurst(t *testing.T) {
-	limiter := NewRateLimit(RateLimitConfig{
+	limiter := modkithttp.RateLimit(modkithttp.RateLimitConfig{
 		RequestsPerSecond: 1,
 		Burst:             2,
 	})
@@ -222,12 +222,12 @@ func TestGroupScope_OnlyAppliesMiddlewareToGroup(t *testing.T) {
 }
 
 func TestMiddlewareOrdering_CORSBeforeRateLimit(t *testing.T) {
-	cors := NewCORS(CORSConfig{
+	cors := modkithttp.CORS(modkithttp.CORSConfig{
 		AllowedOrigins: []string{"http://localhost:3000"},
 		AllowedMethods: []string{"GET"},
 		AllowedHeaders: []string{"Content-Type"},
 	})
-	limiter := NewRateLimit(RateLimitConfig{
+	limiter := modkithttp.RateLimit(modkithttp.RateLimitConfig{
 		RequestsPerSecond: 1,
 		Burst:             1,
 	})
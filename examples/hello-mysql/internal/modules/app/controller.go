@@ -14,15 +14,9 @@ func NewController() *Controller {
 }
 
 func (c *Controller) RegisterRoutes(router modkithttp.Router) {
-	router.Handle(http.MethodGet, "/health", http.HandlerFunc(c.handleHealth))
+	router.Handle(http.MethodGet, "/health", modkithttp.Route(http.HandlerFunc(c.handleHealth), modkithttp.Summary("Health check")))
 }
 
-// @Summary Health check
-// @Description Returns service health status.
-// @Tags health
-// @Produce json
-// @Success 200 {object} map[string]string
-// @Router /api/v1/health [get]
 func (c *Controller) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
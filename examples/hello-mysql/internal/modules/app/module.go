@@ -8,6 +8,7 @@ import (
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/database"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/users"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/platform/logging"
+	modkithttp "github.com/go-modkit/modkit/modkit/http"
 	"github.com/go-modkit/modkit/modkit/module"
 )
 
@@ -36,6 +37,10 @@ func (m *Module) Definition() module.ModuleDef {
 	return module.ModuleDef{
 		Name:    "app",
 		Imports: []module.Module{cfgModule, dbModule, authModule, usersModule, auditModule},
+		HTTP: module.HTTPDef{
+			Prefix:     "/api/v1",
+			Middleware: []module.Token{CorsMiddlewareToken, RateLimitMiddlewareToken, TimingMiddlewareToken},
+		},
 		Providers: []module.ProviderDef{
 			{
 				Token: CorsMiddlewareToken,
@@ -53,7 +58,7 @@ func (m *Module) Definition() module.ModuleDef {
 						return nil, err
 					}
 
-					return middleware.NewCORS(middleware.CORSConfig{
+					return modkithttp.CORS(modkithttp.CORSConfig{
 						AllowedOrigins: origins,
 						AllowedMethods: methods,
 						AllowedHeaders: headers,
@@ -72,7 +77,7 @@ func (m *Module) Definition() module.ModuleDef {
 						return nil, err
 					}
 
-					return middleware.NewRateLimit(middleware.RateLimitConfig{
+					return modkithttp.RateLimit(modkithttp.RateLimitConfig{
 						RequestsPerSecond: perSecond,
 						Burst:             burst,
 					}), nil
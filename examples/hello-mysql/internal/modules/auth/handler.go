@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/httpapi"
+	modkithttp "github.com/go-modkit/modkit/modkit/http"
 )
 
 type Handler struct {
@@ -32,18 +32,18 @@ func (h *Handler) handleLogin(w http.ResponseWriter, r *http.Request) {
 	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
 	var input loginRequest
 	if err := decodeJSON(r, &input); err != nil {
-		httpapi.WriteProblem(w, r, http.StatusBadRequest, "invalid body")
+		modkithttp.WriteProblem(w, r, modkithttp.Problem{Status: http.StatusBadRequest, Detail: "invalid body"})
 		return
 	}
 
 	if input.Username != h.cfg.Username || input.Password != h.cfg.Password {
-		httpapi.WriteProblem(w, r, http.StatusUnauthorized, "invalid credentials")
+		modkithttp.WriteProblem(w, r, modkithttp.Problem{Status: http.StatusUnauthorized, Detail: "invalid credentials"})
 		return
 	}
 
 	token, err := IssueToken(h.cfg, User{ID: input.Username, Email: input.Username})
 	if err != nil {
-		httpapi.WriteProblem(w, r, http.StatusInternalServerError, "internal error")
+		modkithttp.WriteProblem(w, r, modkithttp.Problem{Status: http.StatusInternalServerError, Detail: "internal error"})
 		return
 	}
 
@@ -10,9 +10,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/httpapi"
 	"github.com/go-modkit/modkit/examples/hello-mysql/internal/modules/auth"
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/validation"
 	modkithttp "github.com/go-modkit/modkit/modkit/http"
 )
 
@@ -110,7 +108,7 @@ func TestController_CreateUser_Conflict(t *testing.T) {
 	if rec.Code != http.StatusConflict {
 		t.Fatalf("expected status 409, got %d", rec.Code)
 	}
-	var problem httpapi.Problem
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -139,7 +137,7 @@ func TestController_CreateUser_InvalidJSON(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -170,7 +168,7 @@ func TestController_CreateUser_InternalError(t *testing.T) {
 	if rec.Code != http.StatusInternalServerError {
 		t.Fatalf("expected status 500, got %d", rec.Code)
 	}
-	var problem httpapi.Problem
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -201,7 +199,7 @@ func TestController_GetUser_NotFound(t *testing.T) {
 	if rec.Code != http.StatusNotFound {
 		t.Fatalf("expected status 404, got %d", rec.Code)
 	}
-	var problem httpapi.Problem
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -230,7 +228,7 @@ func TestController_GetUser_InvalidID(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem httpapi.Problem
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -431,7 +429,7 @@ func TestController_ListUsers_InvalidPage(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -459,7 +457,7 @@ func TestController_ListUsers_InvalidLimit(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -487,7 +485,7 @@ func TestController_ListUsers_PageLessThanOne(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -515,7 +513,7 @@ func TestController_ListUsers_LimitLessThanOne(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -691,7 +689,7 @@ func TestController_DeleteUser_InternalError(t *testing.T) {
 	if rec.Code != http.StatusInternalServerError {
 		t.Fatalf("expected status 500, got %d", rec.Code)
 	}
-	var problem httpapi.Problem
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
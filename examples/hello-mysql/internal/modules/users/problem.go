@@ -1,9 +0,0 @@
-package users
-
-import (
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/httpapi"
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/validation"
-)
-
-type Problem = httpapi.Problem
-type ProblemDetails = validation.ProblemDetails
@@ -11,7 +11,7 @@ type CreateUserInput struct {
 	Email string `json:"email"`
 }
 
-func (i CreateUserInput) Validate() validation.ValidationErrors {
+func (i CreateUserInput) Validate() error {
 	var errs validation.ValidationErrors
 	if strings.TrimSpace(i.Name) == "" {
 		errs.Add("name", "is required")
@@ -19,7 +19,7 @@ func (i CreateUserInput) Validate() validation.ValidationErrors {
 	if strings.TrimSpace(i.Email) == "" {
 		errs.Add("email", "is required")
 	}
-	return errs
+	return errs.Err()
 }
 
 type UpdateUserInput struct {
@@ -27,7 +27,7 @@ type UpdateUserInput struct {
 	Email string `json:"email"`
 }
 
-func (i UpdateUserInput) Validate() validation.ValidationErrors {
+func (i UpdateUserInput) Validate() error {
 	var errs validation.ValidationErrors
 	if strings.TrimSpace(i.Name) == "" {
 		errs.Add("name", "is required")
@@ -35,5 +35,5 @@ func (i UpdateUserInput) Validate() validation.ValidationErrors {
 	if strings.TrimSpace(i.Email) == "" {
 		errs.Add("email", "is required")
 	}
-	return errs
+	return errs.Err()
 }
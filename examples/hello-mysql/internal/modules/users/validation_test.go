@@ -8,7 +8,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/go-modkit/modkit/examples/hello-mysql/internal/validation"
 	modkithttp "github.com/go-modkit/modkit/modkit/http"
 )
 
@@ -32,7 +31,7 @@ func TestController_CreateUser_Validation(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -64,7 +63,7 @@ func TestController_CreateUser_Validation_Whitespace(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -93,7 +92,7 @@ func TestController_UpdateUser_Validation(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -125,7 +124,7 @@ func TestController_UpdateUser_Validation_Whitespace(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -153,7 +152,7 @@ func TestController_CreateUser_InvalidJSONBody(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -181,7 +180,7 @@ func TestController_UpdateUser_InvalidID(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -209,7 +208,7 @@ func TestController_UpdateUser_InvalidJSONBody(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -237,7 +236,7 @@ func TestController_ListUsers_Validation(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
@@ -265,7 +264,7 @@ func TestController_ListUsers_Validation_NonInteger(t *testing.T) {
 	if rec.Code != http.StatusBadRequest {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
-	var problem validation.ProblemDetails
+	var problem modkithttp.Problem
 	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
 		t.Fatalf("decode: %v", err)
 	}
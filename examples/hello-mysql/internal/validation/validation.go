@@ -1,10 +1,18 @@
 package validation
 
+import (
+	"strings"
+
+	modkithttp "github.com/go-modkit/modkit/modkit/http"
+)
+
 type FieldError struct {
 	Name   string `json:"name"`
 	Reason string `json:"reason"`
 }
 
+// ValidationErrors collects field errors. It implements modkithttp.InvalidParamsError
+// so typed handlers report each field in the problem response.
 type ValidationErrors struct {
 	Fields []FieldError `json:"fields"`
 }
@@ -16,3 +24,27 @@ func (v *ValidationErrors) Add(name, reason string) {
 func (v ValidationErrors) HasErrors() bool {
 	return len(v.Fields) > 0
 }
+
+// Err returns v as an error, or nil when there are no field errors.
+func (v ValidationErrors) Err() error {
+	if !v.HasErrors() {
+		return nil
+	}
+	return v
+}
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		parts = append(parts, f.Name+" "+f.Reason)
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+func (v ValidationErrors) InvalidParams() []modkithttp.InvalidParam {
+	params := make([]modkithttp.InvalidParam, 0, len(v.Fields))
+	for _, f := range v.Fields {
+		params = append(params, modkithttp.InvalidParam{Name: f.Name, Reason: f.Reason})
+	}
+	return params
+}
@@ -1,10 +1,10 @@
 package validation
 
 import (
-	"encoding/json"
-	"net/http"
-	"net/http/httptest"
+	"errors"
 	"testing"
+
+	modkithttp "github.com/go-modkit/modkit/modkit/http"
 )
 
 func TestValidationErrors_AddAndHasErrors(t *testing.T) {
@@ -29,82 +29,24 @@ func TestValidationErrors_EmptyHasErrorsFalse(t *testing.T) {
 	}
 }
 
-func TestProblemDetails_MapsMultipleFields(t *testing.T) {
+func TestValidationErrors_ErrNilWhenEmpty(t *testing.T) {
 	var errs ValidationErrors
-	errs.Add("name", "is required")
-	errs.Add("email", "is required")
-
-	pd := NewProblemDetails("/users", errs)
-
-	if len(pd.InvalidParams) != 2 {
-		t.Fatalf("expected 2 invalid params, got %d", len(pd.InvalidParams))
-	}
-	if pd.InvalidParams[0].Name != "name" || pd.InvalidParams[1].Name != "email" {
-		t.Fatalf("unexpected invalid params: %+v", pd.InvalidParams)
+	if err := errs.Err(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
 	}
 }
 
-func TestWriteProblemDetails_WritesResponse(t *testing.T) {
+func TestValidationErrors_InvalidParams(t *testing.T) {
 	var errs ValidationErrors
 	errs.Add("name", "is required")
+	errs.Add("email", "is required")
 
-	req := httptest.NewRequest(http.MethodPost, "/users", nil)
-	rec := httptest.NewRecorder()
-
-	WriteProblemDetails(rec, req, errs)
-
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("expected status 400, got %d", rec.Code)
-	}
-	if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
-		t.Fatalf("expected content-type application/problem+json, got %q", ct)
-	}
-
-	var pd ProblemDetails
-	if err := json.NewDecoder(rec.Body).Decode(&pd); err != nil {
-		t.Fatalf("decode: %v", err)
-	}
-	if len(pd.InvalidParams) != 1 || pd.InvalidParams[0].Name != "name" {
-		t.Fatalf("unexpected invalid params: %+v", pd.InvalidParams)
-	}
-}
-
-func TestProblemDetails_FromValidationErrors(t *testing.T) {
-	err := ValidationErrors{}
-	err.Add("email", "is required")
-
-	pd := NewProblemDetails("/users", err)
-
-	if pd.Status != 400 {
-		t.Fatalf("expected status 400, got %d", pd.Status)
-	}
-	if len(pd.InvalidParams) != 1 {
-		t.Fatalf("expected 1 invalid param, got %d", len(pd.InvalidParams))
-	}
-	if pd.InvalidParams[0].Name != "email" {
-		t.Fatalf("unexpected invalid param: %+v", pd.InvalidParams[0])
-	}
-}
-
-func TestProblemDetails_IncludesMetadata(t *testing.T) {
-	errs := ValidationErrors{}
-	errs.Add("email", "invalid")
-
-	pd := NewProblemDetails("/users", errs)
-
-	if pd.Type != "https://httpstatuses.com/400" {
-		t.Fatalf("expected type url for 400, got %q", pd.Type)
-	}
-	if pd.Title != http.StatusText(http.StatusBadRequest) {
-		t.Fatalf("expected title %q, got %q", http.StatusText(http.StatusBadRequest), pd.Title)
-	}
-	if pd.Detail != "validation failed" {
-		t.Fatalf("expected detail %q, got %q", "validation failed", pd.Detail)
-	}
-	if pd.Instance != "/users" {
-		t.Fatalf("expected instance %q, got %q", "/users", pd.Instance)
+	var paramsErr modkithttp.InvalidParamsError
+	if !errors.As(errs.Err(), &paramsErr) {
+		t.Fatalf("expected InvalidParamsError")
 	}
-	if len(pd.InvalidParams) != 1 || pd.InvalidParams[0].Reason != "invalid" {
-		t.Fatalf("unexpected invalid params: %+v", pd.InvalidParams)
+	params := paramsErr.InvalidParams()
+	if len(params) != 2 || params[0].Name != "name" || params[1].Name != "email" {
+		t.Fatalf("unexpected invalid params: %+v", params)
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"flag"
 	"log"
 	"net/http"
+	"os"
 	"strings"
 
 	mkhttp "github.com/go-modkit/modkit/modkit/http"
@@ -132,9 +133,13 @@ func main() {
 
 	// Create router and register controllers
 	router := mkhttp.NewRouter()
-	if err := mkhttp.RegisterRoutes(mkhttp.AsRouter(router), app.Controllers); err != nil {
+	routes := mkhttp.AsRouter(router)
+	if err := mkhttp.RegisterRoutes(routes, app.Controllers); err != nil {
 		log.Fatalf("Failed to register routes: %v", err)
 	}
+	if err := mkhttp.WriteRoutes(os.Stdout, mkhttp.Routes(routes)); err != nil {
+		log.Fatalf("Failed to print routes: %v", err)
+	}
 
 	if *graphFormat != "" {
 		graph, err := exportGraphForExample(app, *graphFormat)
@@ -3,6 +3,7 @@ package main
 
 import (
 	"errors"
+	"net/http"
 	"testing"
 
 	"github.com/go-modkit/modkit/modkit/kernel"
@@ -155,3 +156,14 @@ func TestAppModule_TestKitOverrideGreeting(t *testing.T) {
 	controller := testkit.Controller[*GreetingController](t, h, "app", "GreetingController")
 	assert.Equal(t, "fake", controller.message)
 }
+
+func TestGreetingController_HTTP(t *testing.T) {
+	h := testkit.NewHTTP(t, NewAppModule("hello"))
+
+	h.Get("/greet").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON(`{"message": "hello", "count": 1}`)
+	h.Get("/health").Do().
+		ExpectStatus(http.StatusOK).
+		ExpectJSON(map[string]string{"status": "ok"})
+}
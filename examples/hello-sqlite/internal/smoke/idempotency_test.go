@@ -0,0 +1,107 @@
+package smoke
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlite"
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/module"
+	"github.com/go-modkit/modkit/modkit/testkit"
+)
+
+type idempotencyRoot struct{}
+
+func (*idempotencyRoot) Definition() module.ModuleDef {
+	return module.ModuleDef{
+		Name: "root",
+		Imports: []module.Module{mkhttp.NewIdempotencyModule(mkhttp.IdempotencyOptions{
+			Database:    sqlite.NewModule(sqlite.Options{}),
+			CreateTable: true,
+		})},
+	}
+}
+
+func TestSmoke_SQLite_IdempotencyStore(t *testing.T) {
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "app.db"))
+	t.Setenv("SQLITE_CONNECT_TIMEOUT", "2s")
+
+	h := testkit.New(t, &idempotencyRoot{})
+	if _, ok := testkit.Get[mkhttp.IdempotencyStore](t, h, mkhttp.TokenIdempotencyStore).(*mkhttp.SQLIdempotencyStore); !ok {
+		t.Fatalf("expected SQL idempotency store")
+	}
+	mw := testkit.Get[func(http.Handler) http.Handler](t, h, mkhttp.TokenIdempotencyMiddleware)
+
+	var calls atomic.Int32
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+	serve := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		req.Header.Set(mkhttp.IdempotencyKeyHeader, "order-1")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(`{"sku":"a"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+	rec := serve(`{"sku":"a"}`)
+	if rec.Code != http.StatusCreated || rec.Body.String() != `{"id":1}` ||
+		rec.Header().Get("Content-Type") != "application/json" ||
+		rec.Header().Get(mkhttp.IdempotentReplayedHeader) != "true" {
+		t.Fatalf("unexpected replay: %d %v %q", rec.Code, rec.Header(), rec.Body.String())
+	}
+	if rec := serve(`{"sku":"b"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", rec.Code)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+}
+
+func TestSmoke_SQLite_IdempotencyStoreChecksLockOwner(t *testing.T) {
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "app.db"))
+	t.Setenv("SQLITE_CONNECT_TIMEOUT", "2s")
+
+	h := testkit.New(t, &idempotencyRoot{})
+	store := testkit.Get[mkhttp.IdempotencyStore](t, h, mkhttp.TokenIdempotencyStore)
+	ctx := context.Background()
+
+	_, stale, err := store.Lock(ctx, "order-2", "f", time.Millisecond)
+	if err != nil || stale == "" {
+		t.Fatalf("expected lock, got owner=%q err=%v", stale, err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	_, current, err := store.Lock(ctx, "order-2", "f", time.Minute)
+	if err != nil || current == "" || current == stale {
+		t.Fatalf("expected expired lock to be reclaimed, got owner=%q err=%v", current, err)
+	}
+
+	if err := store.Unlock(ctx, "order-2", stale); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	record := mkhttp.IdempotencyRecord{Fingerprint: "f", Status: http.StatusCreated, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := store.Save(ctx, "order-2", stale, record); !errors.Is(err, mkhttp.ErrIdempotencyLockLost) {
+		t.Fatalf("expected ErrIdempotencyLockLost for a stale owner, got %v", err)
+	}
+	if err := store.Save(ctx, "order-2", current, record); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if existing, owner, err := store.Lock(ctx, "order-2", "f", time.Minute); err != nil || owner != "" || existing.Status != http.StatusCreated {
+		t.Fatalf("expected stored response, got owner=%q record=%+v err=%v", owner, existing, err)
+	}
+}
@@ -6,6 +6,17 @@ import (
 	"github.com/go-modkit/modkit/modkit/module"
 )
 
+// Stable error codes returned by the Code methods of config errors. They match
+// kernel.CodeOf and never change once published.
+const (
+	// CodeMissingRequired reports a required key that was unset.
+	CodeMissingRequired = "MODKIT_E_CONFIG_MISSING_REQUIRED"
+	// CodeParse reports a value that could not be parsed.
+	CodeParse = "MODKIT_E_CONFIG_PARSE"
+	// CodeInvalidSpec reports an invalid ValueSpec.
+	CodeInvalidSpec = "MODKIT_E_CONFIG_INVALID_SPEC"
+)
+
 // MissingRequiredError reports a required key that was unset.
 type MissingRequiredError struct {
 	Key       string
@@ -43,3 +54,42 @@ type InvalidSpecError struct {
 func (e *InvalidSpecError) Error() string {
 	return fmt.Sprintf("invalid config spec: token=%q reason=%s", e.Token, e.Reason)
 }
+
+// Code returns the stable error code.
+func (e *MissingRequiredError) Code() string { return CodeMissingRequired }
+
+// Fields returns structured error fields.
+func (e *MissingRequiredError) Fields() map[string]any {
+	return map[string]any{"key": e.Key, "token": string(e.Token), "sensitive": e.Sensitive}
+}
+
+// Hint returns a remediation hint.
+func (e *MissingRequiredError) Hint() string {
+	return fmt.Sprintf("set %s in the environment (or the configured Source), or give the ValueSpec a Default", e.Key)
+}
+
+// Code returns the stable error code.
+func (e *ParseError) Code() string { return CodeParse }
+
+// Fields returns structured error fields. Values are never included.
+func (e *ParseError) Fields() map[string]any {
+	return map[string]any{"key": e.Key, "token": string(e.Token), "type": e.Type, "sensitive": e.Sensitive}
+}
+
+// Hint returns a remediation hint.
+func (e *ParseError) Hint() string {
+	return fmt.Sprintf("set %s to a valid %s value", e.Key, e.Type)
+}
+
+// Code returns the stable error code.
+func (e *InvalidSpecError) Code() string { return CodeInvalidSpec }
+
+// Fields returns structured error fields.
+func (e *InvalidSpecError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token), "reason": e.Reason}
+}
+
+// Hint returns a remediation hint.
+func (e *InvalidSpecError) Hint() string {
+	return fmt.Sprintf("fix the ValueSpec registered for %q: %s", e.Token, e.Reason)
+}
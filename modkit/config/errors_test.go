@@ -45,4 +45,30 @@ func TestErrors(t *testing.T) {
 			t.Fatalf("unexpected error string: %q", err.Error())
 		}
 	})
+
+	t.Run("codes and hints", func(t *testing.T) {
+		tests := []struct {
+			err interface {
+				Code() string
+				Hint() string
+				Fields() map[string]any
+			}
+			code string
+		}{
+			{&config.MissingRequiredError{Key: "K", Token: "t"}, "MODKIT_E_CONFIG_MISSING_REQUIRED"},
+			{&config.ParseError{Key: "K", Token: "t", Type: "int", Err: errors.New("x")}, "MODKIT_E_CONFIG_PARSE"},
+			{&config.InvalidSpecError{Token: "t", Reason: "bad"}, "MODKIT_E_CONFIG_INVALID_SPEC"},
+		}
+		for _, tc := range tests {
+			if tc.err.Code() != tc.code {
+				t.Fatalf("unexpected code: %q", tc.err.Code())
+			}
+			if tc.err.Hint() == "" {
+				t.Fatalf("missing hint for %s", tc.code)
+			}
+			if tc.err.Fields()["token"] != "t" {
+				t.Fatalf("missing token field for %s", tc.code)
+			}
+		}
+	})
 }
@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/go-modkit/modkit/modkit/config"
@@ -74,3 +75,33 @@ func TestIntegration_FirstResolutionFailsForMissingRequired(t *testing.T) {
 		t.Fatalf("expected MissingRequiredError, got %T", err)
 	}
 }
+
+func TestIntegration_DiagnoseMissingRequired(t *testing.T) {
+	const token module.Token = "config.required"
+
+	cfgModule := config.NewModule(
+		config.WithSource(mapSource{}),
+		config.WithTyped(token, config.ValueSpec[string]{
+			Key:      "REQUIRED_KEY",
+			Required: true,
+			Parse:    config.ParseString,
+		}, true),
+	)
+
+	app, err := kernel.Bootstrap(mod("root", []module.Module{cfgModule}, nil))
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+
+	_, err = app.Get(token)
+	out, diagErr := kernel.Diagnose(err, kernel.DiagnosticFormatText)
+	if diagErr != nil {
+		t.Fatalf("diagnose failed: %v", diagErr)
+	}
+	if !strings.Contains(out, "[MODKIT_E_CONFIG_MISSING_REQUIRED]") {
+		t.Fatalf("expected config code in diagnosis:\n%s", out)
+	}
+	if !strings.Contains(out, "hint: set REQUIRED_KEY") {
+		t.Fatalf("expected config hint in diagnosis:\n%s", out)
+	}
+}
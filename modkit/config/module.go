@@ -23,9 +23,11 @@ type builder struct {
 }
 
 type entry struct {
-	token  module.Token
-	export bool
-	build  func(src Source) module.ProviderDef
+	token     module.Token
+	export    bool
+	key       string
+	sensitive bool
+	build     func(src Source) module.ProviderDef
 }
 
 // ValueSpec defines how to resolve and parse a typed config value.
@@ -39,7 +41,9 @@ type ValueSpec[T any] struct {
 }
 
 type mod struct {
-	def module.ModuleDef
+	def             module.ModuleDef
+	sensitive       []string
+	sensitiveTokens []module.Token
 }
 
 func (m *mod) Definition() module.ModuleDef {
@@ -57,18 +61,82 @@ func NewModule(opts ...Option) module.Module {
 
 	providers := make([]module.ProviderDef, 0, len(b.entries))
 	exports := make([]module.Token, 0, len(b.entries))
+	var (
+		sensitive       []string
+		sensitiveTokens []module.Token
+	)
 	for _, e := range b.entries {
 		providers = append(providers, e.build(b.source))
 		if e.export {
 			exports = append(exports, e.token)
 		}
+		if e.sensitive && e.key != "" {
+			sensitive = append(sensitive, e.key)
+			sensitiveTokens = append(sensitiveTokens, e.token)
+		}
+	}
+
+	return &mod{
+		def: module.ModuleDef{
+			Name:      moduleNameForBuilder(b),
+			Providers: providers,
+			Exports:   exports,
+		},
+		sensitive:       sensitive,
+		sensitiveTokens: sensitiveTokens,
+	}
+}
+
+// SensitiveKeys returns the source keys, such as environment variable names,
+// of ValueSpecs marked Sensitive in config modules built by NewModule. Other
+// modules are ignored. To keep the values out of logs use SensitiveValues.
+func SensitiveKeys(modules ...module.Module) []string {
+	var keys []string
+	for _, m := range modules {
+		if configMod, ok := m.(*mod); ok {
+			keys = append(keys, configMod.sensitive...)
+		}
 	}
+	return keys
+}
 
-	return &mod{def: module.ModuleDef{
-		Name:      moduleNameForBuilder(b),
-		Providers: providers,
-		Exports:   exports,
-	}}
+// SensitiveValues resolves the ValueSpecs marked Sensitive in config modules
+// built by NewModule through r, which must see their tokens, and returns the
+// non-empty values as strings, for example to pass as logging.Options
+// RedactValues. Other modules are ignored.
+func SensitiveValues(r module.Resolver, modules ...module.Module) ([]string, error) {
+	var values []string
+	for _, m := range modules {
+		configMod, ok := m.(*mod)
+		if !ok {
+			continue
+		}
+		for _, token := range configMod.sensitiveTokens {
+			value, err := r.Get(token)
+			if err != nil {
+				return nil, err
+			}
+			if text := valueString(value); text != "" {
+				values = append(values, text)
+			}
+		}
+	}
+	return values, nil
+}
+
+func valueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
 // WithModuleName sets an explicit module name.
@@ -89,8 +157,10 @@ func WithSource(src Source) Option {
 func WithTyped[T any](token module.Token, spec ValueSpec[T], export bool) Option {
 	return func(b *builder) {
 		b.entries = append(b.entries, entry{
-			token:  token,
-			export: export,
+			token:     token,
+			export:    export,
+			key:       spec.Key,
+			sensitive: spec.Sensitive,
 			build: func(src Source) module.ProviderDef {
 				return module.ProviderDef{
 					Token: token,
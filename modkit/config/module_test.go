@@ -1,14 +1,17 @@
 package config_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/go-modkit/modkit/modkit/config"
 	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
 	"github.com/go-modkit/modkit/modkit/module"
 )
 
@@ -304,6 +307,69 @@ func TestWithTyped_SensitiveErrorDoesNotLeakValue(t *testing.T) {
 	}
 }
 
+func TestSensitiveKeys(t *testing.T) {
+	cfgModule := config.NewModule(
+		config.WithTyped(module.Token("config.dsn"), config.ValueSpec[string]{
+			Key:       "DB_DSN",
+			Sensitive: true,
+			Parse:     config.ParseString,
+		}, true),
+		config.WithTyped(module.Token("config.port"), config.ValueSpec[int]{
+			Key:   "PORT",
+			Parse: config.ParseInt,
+		}, true),
+	)
+
+	keys := config.SensitiveKeys(cfgModule, mod("other", nil, nil))
+	if len(keys) != 1 || keys[0] != "DB_DSN" {
+		t.Fatalf("expected [DB_DSN], got %v", keys)
+	}
+}
+
+func TestSensitiveValues_RedactedInLogs(t *testing.T) {
+	const dsnToken module.Token = "config.dsn"
+	const dsn = "postgres://app:s3cret@db/app"
+
+	cfgModule := config.NewModule(
+		config.WithSource(mapSource{"DB_DSN": dsn, "PORT": "8080"}),
+		config.WithTyped(dsnToken, config.ValueSpec[string]{
+			Key:       "DB_DSN",
+			Sensitive: true,
+			Parse:     config.ParseString,
+		}, true),
+		config.WithTyped(module.Token("config.port"), config.ValueSpec[int]{
+			Key:   "PORT",
+			Parse: config.ParseInt,
+		}, true),
+	)
+	app, err := kernel.Bootstrap(mod("root", []module.Module{cfgModule}, nil))
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+
+	values, err := config.SensitiveValues(app, cfgModule, mod("other", nil, nil))
+	if err != nil {
+		t.Fatalf("SensitiveValues failed: %v", err)
+	}
+	if len(values) != 1 || values[0] != dsn {
+		t.Fatalf("expected [%s], got %v", dsn, values)
+	}
+
+	resolved, err := module.Get[string](app, dsnToken)
+	if err != nil {
+		t.Fatalf("get dsn: %v", err)
+	}
+	var buf bytes.Buffer
+	logger := logging.NewSlogLoggerWithOptions(slog.New(slog.NewJSONHandler(&buf, nil)), logging.Options{
+		RedactValues: values,
+	})
+	logger.Info("connecting", "dsn", resolved, "error", fmt.Errorf("dial %s: refused", resolved))
+
+	if out := buf.String(); strings.Contains(out, "s3cret") || strings.Count(out, logging.Redacted) != 2 {
+		t.Fatalf("expected the DSN to be redacted, got %s", out)
+	}
+}
+
 func TestWithSourceNil(t *testing.T) {
 	const token module.Token = "config.foo" //nolint:gosec
 
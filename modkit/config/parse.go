@@ -16,6 +16,11 @@ func ParseInt(raw string) (int, error) {
 	return strconv.Atoi(raw)
 }
 
+// ParseInt64 parses an int64 value.
+func ParseInt64(raw string) (int64, error) {
+	return strconv.ParseInt(raw, 10, 64)
+}
+
 // ParseFloat64 parses a float64 value.
 func ParseFloat64(raw string) (float64, error) {
 	return strconv.ParseFloat(raw, 64)
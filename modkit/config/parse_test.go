@@ -33,6 +33,16 @@ func TestParseHelpers(t *testing.T) {
 		}
 	})
 
+	t.Run("int64", func(t *testing.T) {
+		got, err := config.ParseInt64("8589934592")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != 8589934592 {
+			t.Fatalf("got %d", got)
+		}
+	})
+
 	t.Run("float64", func(t *testing.T) {
 		got, err := config.ParseFloat64("3.5")
 		if err != nil {
@@ -2,6 +2,7 @@ package sqlmodule
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
@@ -20,6 +21,23 @@ const (
 	StagePing BuildStage = "ping"
 )
 
+// Stable error codes returned by the Code methods of SQL module errors. They
+// match kernel.CodeOf and never change once published.
+const (
+	// CodeBuildFailed reports a BuildError without a stage.
+	CodeBuildFailed = "MODKIT_E_DATA_BUILD_FAILED"
+	// CodeResolveConfig reports a BuildError at StageResolveConfig.
+	CodeResolveConfig = "MODKIT_E_DATA_RESOLVE_CONFIG"
+	// CodeInvalidConfig reports a BuildError at StageInvalidConfig.
+	CodeInvalidConfig = "MODKIT_E_DATA_INVALID_CONFIG"
+	// CodeOpen reports a BuildError at StageOpen.
+	CodeOpen = "MODKIT_E_DATA_OPEN"
+	// CodePing reports a BuildError at StagePing.
+	CodePing = "MODKIT_E_DATA_PING"
+	// CodeInvalidName reports an InvalidNameError.
+	CodeInvalidName = "MODKIT_E_DATA_INVALID_NAME"
+)
+
 // BuildError is returned when a SQL provider fails to build.
 type BuildError struct {
 	Provider string
@@ -38,3 +56,44 @@ func (e *BuildError) Error() string {
 func (e *BuildError) Unwrap() error {
 	return e.Err
 }
+
+// Code returns the stable error code for the failed build stage, for example
+// CodePing. Stages without a constant get MODKIT_E_DATA_ and the upper-cased
+// stage.
+func (e *BuildError) Code() string {
+	switch e.Stage {
+	case "":
+		return CodeBuildFailed
+	case StageResolveConfig:
+		return CodeResolveConfig
+	case StageInvalidConfig:
+		return CodeInvalidConfig
+	case StageOpen:
+		return CodeOpen
+	case StagePing:
+		return CodePing
+	default:
+		return "MODKIT_E_DATA_" + strings.ToUpper(string(e.Stage))
+	}
+}
+
+// Fields returns structured error fields.
+func (e *BuildError) Fields() map[string]any {
+	return map[string]any{"provider": e.Provider, "token": string(e.Token), "stage": string(e.Stage)}
+}
+
+// Hint returns a remediation hint for the failed build stage.
+func (e *BuildError) Hint() string {
+	switch e.Stage {
+	case StageResolveConfig:
+		return "import the provider's config module (or DefaultConfigModule) and set its required keys"
+	case StageInvalidConfig:
+		return "fix the database config values; durations and pool sizes must not be negative"
+	case StageOpen:
+		return "register the SQL driver with a blank import and check the DSN"
+	case StagePing:
+		return "make sure the database is reachable, or set the connect timeout to 0 to skip the startup ping"
+	default:
+		return "check the database provider configuration"
+	}
+}
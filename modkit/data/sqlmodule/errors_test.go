@@ -34,3 +34,35 @@ func TestBuildErrorWithEmptyProviderUsesGenericPrefix(t *testing.T) {
 		t.Fatalf("expected generic prefix, got %q", msg)
 	}
 }
+
+func TestBuildErrorCodePerStage(t *testing.T) {
+	tests := map[BuildStage]string{
+		StageResolveConfig: "MODKIT_E_DATA_RESOLVE_CONFIG",
+		StageInvalidConfig: "MODKIT_E_DATA_INVALID_CONFIG",
+		StageOpen:          "MODKIT_E_DATA_OPEN",
+		StagePing:          "MODKIT_E_DATA_PING",
+		"":                 "MODKIT_E_DATA_BUILD_FAILED",
+	}
+	for stage, code := range tests {
+		be := &BuildError{Provider: "sqlite", Token: TokenDB, Stage: stage, Err: errors.New("boom")}
+		if be.Code() != code {
+			t.Fatalf("stage %q: code = %q, want %q", stage, be.Code(), code)
+		}
+		if be.Hint() == "" {
+			t.Fatalf("stage %q: expected hint", stage)
+		}
+		if be.Fields()["stage"] != string(stage) {
+			t.Fatalf("stage %q: unexpected fields %v", stage, be.Fields())
+		}
+	}
+}
+
+func TestInvalidNameErrorDiagnostics(t *testing.T) {
+	err := &InvalidNameError{Name: "a b", Reason: "name must not contain spaces"}
+	if err.Code() != "MODKIT_E_DATA_INVALID_NAME" {
+		t.Fatalf("unexpected code: %q", err.Code())
+	}
+	if err.Hint() == "" || err.Fields()["name"] != "a b" {
+		t.Fatalf("unexpected diagnostics: %q %v", err.Hint(), err.Fields())
+	}
+}
@@ -44,6 +44,19 @@ func (e *InvalidNameError) Error() string {
 	return fmt.Sprintf("invalid sql module name: %q reason=%s", e.Name, e.Reason)
 }
 
+// Code returns the stable error code.
+func (e *InvalidNameError) Code() string { return CodeInvalidName }
+
+// Fields returns structured error fields.
+func (e *InvalidNameError) Fields() map[string]any {
+	return map[string]any{"name": e.Name, "reason": e.Reason}
+}
+
+// Hint returns a remediation hint.
+func (e *InvalidNameError) Hint() string {
+	return "use a non-empty module name without whitespace, for example \"analytics\""
+}
+
 // NamedTokens returns deterministic tokens for a SQL module instance name.
 func NamedTokens(name string) (Tokens, error) {
 	if name == "" {
@@ -0,0 +1,7 @@
+// Package grpc serves the gRPC services of modkit controllers. Controllers
+// implement ServiceRegistrar; NewServer builds a server from a bootstrapped
+// App with logging, panic recovery, and error-to-status interceptors, plus a
+// grpc.health.v1 service backed by the App's health checks. Server implements
+// module.Runnable and stops gracefully, so it can run next to HTTP servers in
+// http.ServeWithOptions, which shuts the App down once both have drained.
+package grpc
@@ -0,0 +1,30 @@
+package grpc
+
+import "fmt"
+
+// DuplicateServiceError indicates two controllers registered the same gRPC
+// service.
+type DuplicateServiceError struct {
+	Service     string
+	Controllers []string
+}
+
+func (e *DuplicateServiceError) Error() string {
+	return fmt.Sprintf("duplicate grpc service: %s registered by controllers %q and %q",
+		e.Service, e.Controllers[0], e.Controllers[1])
+}
+
+// ServerError wraps a listen, serve, or shutdown failure of a Server.
+type ServerError struct {
+	Name string
+	Addr string
+	Err  error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("grpc server failed: name=%q addr=%q: %v", e.Name, e.Addr, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,121 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
+)
+
+// DefaultHealthWatchInterval is how often Watch re-runs the App's health checks
+// when Options.HealthWatchInterval is zero.
+const DefaultHealthWatchInterval = 5 * time.Second
+
+// healthService implements grpc.health.v1.Health with App.CheckHealth. Every
+// registered service shares the App's status, and everything reports
+// NOT_SERVING once shutdown starts.
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+
+	app      *kernel.App
+	server   *grpc.Server
+	logger   logging.Logger
+	interval time.Duration
+
+	shutdownOnce sync.Once
+	shuttingDown chan struct{}
+}
+
+func newHealthService(app *kernel.App, server *grpc.Server, logger logging.Logger, interval time.Duration) *healthService {
+	if interval <= 0 {
+		interval = DefaultHealthWatchInterval
+	}
+	return &healthService{
+		app:          app,
+		server:       server,
+		logger:       logger,
+		interval:     interval,
+		shuttingDown: make(chan struct{}),
+	}
+}
+
+// shutdown reports NOT_SERVING from now on and ends Watch streams, which would
+// otherwise hold up GracefulStop.
+func (h *healthService) shutdown() {
+	h.shutdownOnce.Do(func() { close(h.shuttingDown) })
+}
+
+func (h *healthService) known(service string) bool {
+	if service == "" {
+		return true
+	}
+	_, ok := h.server.GetServiceInfo()[service]
+	return ok
+}
+
+func (h *healthService) status(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	select {
+	case <-h.shuttingDown:
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	default:
+	}
+	if err := h.app.CheckHealth(ctx); err != nil {
+		h.logger.Warn("grpc health check failed", "error", err)
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+func (h *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	if !h.known(req.GetService()) {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: h.status(ctx)}, nil
+}
+
+func (h *healthService) List(ctx context.Context, _ *healthpb.HealthListRequest) (*healthpb.HealthListResponse, error) {
+	st := h.status(ctx)
+	statuses := map[string]*healthpb.HealthCheckResponse{"": {Status: st}}
+	for name := range h.server.GetServiceInfo() {
+		statuses[name] = &healthpb.HealthCheckResponse{Status: st}
+	}
+	return &healthpb.HealthListResponse{Statuses: statuses}, nil
+}
+
+func (h *healthService) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		st := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if h.known(req.GetService()) {
+			st = h.status(ctx)
+		}
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-h.shuttingDown:
+			if last != healthpb.HealthCheckResponse_NOT_SERVING {
+				return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+			}
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/go-modkit/modkit/modkit/logging"
+	"github.com/go-modkit/modkit/modkit/tracing"
+)
+
+// UnaryLogger returns an interceptor that logs each call with method, code, and
+// duration, plus the trace and span IDs when the context carries a span.
+func UnaryLogger(logger logging.Logger) grpc.UnaryServerInterceptor {
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, err, time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamLogger is the streaming counterpart of UnaryLogger.
+func StreamLogger(logger logging.Logger) grpc.StreamServerInterceptor {
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), logger, info.FullMethod, err, time.Since(start))
+		return err
+	}
+}
+
+func logCall(ctx context.Context, logger logging.Logger, method string, err error, duration time.Duration) {
+	args := []any{
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration", duration,
+	}
+	if sc, ok := tracing.SpanContextFromContext(ctx); ok {
+		args = append(args, "trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String())
+	}
+	logger.Info("grpc request", args...)
+}
+
+// UnaryRecovery returns an interceptor that turns a panicking handler into an
+// Internal status and logs the panic with its stack.
+func UnaryRecovery(logger logging.Logger) grpc.UnaryServerInterceptor {
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer recoverCall(logger, info.FullMethod, &err)
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecovery is the streaming counterpart of UnaryRecovery.
+func StreamRecovery(logger logging.Logger) grpc.StreamServerInterceptor {
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverCall(logger, info.FullMethod, &err)
+		return handler(srv, ss)
+	}
+}
+
+func recoverCall(logger logging.Logger, method string, err *error) {
+	if rec := recover(); rec != nil {
+		logger.Error("grpc panic", "method", method, "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, "internal error")
+	}
+}
+
+// UnaryErrorStatus returns an interceptor that converts handler errors into gRPC
+// statuses with mapper, falling back to DefaultErrorMapper.
+func UnaryErrorStatus(mapper ErrorMapper) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, mapError(ctx, mapper, err)
+		}
+		return resp, nil
+	}
+}
+
+// StreamErrorStatus is the streaming counterpart of UnaryErrorStatus.
+func StreamErrorStatus(mapper ErrorMapper) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return mapError(ss.Context(), mapper, handler(srv, ss))
+	}
+}
@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+)
+
+// ServiceRegistrar is implemented by controllers that expose gRPC services,
+// typically by calling the generated RegisterXxxServer functions. Such
+// controllers are skipped by http.RegisterApp, so one module can serve the same
+// providers over REST and gRPC.
+type ServiceRegistrar interface {
+	RegisterServices(registrar grpc.ServiceRegistrar)
+}
+
+type serviceRegistration struct {
+	desc *grpc.ServiceDesc
+	impl any
+}
+
+// serviceRecorder collects the services of one controller at a time and
+// reports services registered twice, which grpc.Server treats as fatal.
+type serviceRecorder struct {
+	controller    string
+	owners        map[string]string
+	registrations []serviceRegistration
+	err           error
+}
+
+func (r *serviceRecorder) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	if r.err != nil {
+		return
+	}
+	if owner, ok := r.owners[desc.ServiceName]; ok {
+		r.err = &DuplicateServiceError{Service: desc.ServiceName, Controllers: []string{owner, r.controller}}
+		return
+	}
+	r.owners[desc.ServiceName] = r.controller
+	r.registrations = append(r.registrations, serviceRegistration{desc: desc, impl: impl})
+}
+
+// RegisterServices registers the services of every controller implementing
+// ServiceRegistrar on s, in module graph order. Nothing is registered when two
+// controllers register the same service.
+func RegisterServices(s grpc.ServiceRegistrar, app *kernel.App) error {
+	if app == nil {
+		return kernel.ErrNilApp
+	}
+	recorder := &serviceRecorder{owners: make(map[string]string)}
+	for _, ref := range app.ControllerRefs() {
+		registrar, ok := ref.Controller.(ServiceRegistrar)
+		if !ok {
+			continue
+		}
+		recorder.controller = ref.Key
+		registrar.RegisterServices(recorder)
+		if recorder.err != nil {
+			return recorder.err
+		}
+	}
+	for _, reg := range recorder.registrations {
+		s.RegisterService(reg.desc, reg.impl)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
+)
+
+// Options configures NewServer.
+type Options struct {
+	// Name identifies the server in errors and logs.
+	Name string
+	// Addr is the TCP address to listen on when Listener is nil.
+	Addr string
+	// Listener, when set, is used instead of listening on Addr, for example a
+	// bufconn listener in tests.
+	Listener net.Listener
+	// ShutdownTimeout bounds the graceful stop; pending calls are then
+	// canceled. Zero uses http.ShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// OnReady is called with the bound address once the server is listening.
+	OnReady func(addr net.Addr)
+
+	// Logger receives call logs and recovered panics (default no-op).
+	Logger logging.Logger
+	// ErrorMapper runs before DefaultErrorMapper.
+	ErrorMapper ErrorMapper
+	// UnaryInterceptors and StreamInterceptors run after the built-in logging,
+	// status, and recovery interceptors, so their errors and panics are handled
+	// too.
+	UnaryInterceptors  []grpc.UnaryServerInterceptor
+	StreamInterceptors []grpc.StreamServerInterceptor
+	// ServerOptions are passed to grpc.NewServer, for example credentials or
+	// message size limits.
+	ServerOptions []grpc.ServerOption
+
+	// DisableHealth skips the grpc.health.v1 service.
+	DisableHealth bool
+	// HealthWatchInterval is how often health Watch streams re-check the App
+	// (default DefaultHealthWatchInterval).
+	HealthWatchInterval time.Duration
+}
+
+// Server is a gRPC server for the controllers of an App. It implements
+// module.Runnable.
+type Server struct {
+	opts   Options
+	server *grpc.Server
+	health *healthService
+}
+
+// NewServer builds a gRPC server with the services of every controller of app
+// implementing ServiceRegistrar and, unless disabled, a health service reporting
+// app.CheckHealth.
+func NewServer(app *kernel.App, opts Options) (*Server, error) {
+	if app == nil {
+		return nil, kernel.ErrNilApp
+	}
+	if opts.Logger == nil {
+		opts.Logger = logging.NewNopLogger()
+	}
+
+	unary := append([]grpc.UnaryServerInterceptor{
+		UnaryLogger(opts.Logger),
+		UnaryErrorStatus(opts.ErrorMapper),
+		UnaryRecovery(opts.Logger),
+	}, opts.UnaryInterceptors...)
+	stream := append([]grpc.StreamServerInterceptor{
+		StreamLogger(opts.Logger),
+		StreamErrorStatus(opts.ErrorMapper),
+		StreamRecovery(opts.Logger),
+	}, opts.StreamInterceptors...)
+	serverOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}, opts.ServerOptions...)
+
+	s := &Server{opts: opts, server: grpc.NewServer(serverOpts...)}
+	if err := RegisterServices(s.server, app); err != nil {
+		return nil, err
+	}
+	if !opts.DisableHealth {
+		s.health = newHealthService(app, s.server, opts.Logger, opts.HealthWatchInterval)
+		healthpb.RegisterHealthServer(s.server, s.health)
+	}
+	return s, nil
+}
+
+// GRPC returns the underlying server, for registering services outside the
+// controller graph, such as reflection.
+func (s *Server) GRPC() *grpc.Server {
+	return s.server
+}
+
+// Run listens and serves until ctx is canceled, then stops gracefully: health
+// checks report NOT_SERVING, new calls are refused, and pending calls get
+// ShutdownTimeout to finish before they are canceled. It returns nil on a clean
+// shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	ln := s.opts.Listener
+	if ln == nil {
+		var err error
+		ln, err = net.Listen("tcp", s.opts.Addr)
+		if err != nil {
+			return &ServerError{Name: s.opts.Name, Addr: s.opts.Addr, Err: err}
+		}
+	}
+	if s.opts.OnReady != nil {
+		s.opts.OnReady(ln.Addr())
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- s.server.Serve(ln) }()
+
+	select {
+	case err := <-errCh:
+		if err == nil || errors.Is(err, grpc.ErrServerStopped) {
+			return nil
+		}
+		return &ServerError{Name: s.opts.Name, Addr: ln.Addr().String(), Err: err}
+	case <-ctx.Done():
+	}
+
+	s.Stop(context.WithoutCancel(ctx))
+	if err := <-errCh; err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return &ServerError{Name: s.opts.Name, Addr: ln.Addr().String(), Err: err}
+	}
+	return nil
+}
+
+// Stop stops the server gracefully, waiting for pending calls until ctx is done
+// or ShutdownTimeout elapses, whichever comes first, and then cancels them.
+func (s *Server) Stop(ctx context.Context) {
+	if s.health != nil {
+		s.health.shutdown()
+	}
+	timeout := s.opts.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = mkhttp.ShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.opts.Logger.Warn("grpc graceful stop timed out; canceling pending calls", "name", s.opts.Name)
+		s.server.Stop()
+		<-stopped
+	}
+}
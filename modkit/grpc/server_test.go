@@ -0,0 +1,452 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
+)
+
+// echoService is a hand-written service so the tests need no generated code.
+type echoService struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func blockingEcho() *echoService {
+	return &echoService{entered: make(chan struct{}, 1), release: make(chan struct{})}
+}
+
+type echoServer interface {
+	echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error)
+}
+
+func (s *echoService) echo(ctx context.Context, in *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	switch value := in.GetValue(); value {
+	case "not found":
+		return nil, mkhttp.NewProblem(404, "user not found")
+	case "invalid":
+		return nil, &mkhttp.BindError{Params: []mkhttp.InvalidParam{{Name: "email", Reason: "is required"}}}
+	case "secret":
+		return nil, errors.New("secret failure")
+	case "status":
+		return nil, status.Error(codes.Aborted, "try again")
+	case "panic":
+		panic("boom")
+	case "block":
+		s.entered <- struct{}{}
+		select {
+		case <-s.release:
+			return wrapperspb.String("released"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	default:
+		return wrapperspb.String(strings.ToUpper(value)), nil
+	}
+}
+
+func (s *echoService) RegisterServices(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&echoDesc, s)
+}
+
+var echoDesc = grpc.ServiceDesc{
+	ServiceName: "test.Echo",
+	HandlerType: (*echoServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Echo",
+		Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			in := new(wrapperspb.StringValue)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				return srv.(echoServer).echo(ctx, req.(*wrapperspb.StringValue))
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/test.Echo/Echo"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Repeat",
+		ServerStreams: true,
+		Handler: func(_ any, stream grpc.ServerStream) error {
+			in := new(wrapperspb.StringValue)
+			if err := stream.RecvMsg(in); err != nil {
+				return err
+			}
+			if in.GetValue() == "panic" {
+				panic("stream boom")
+			}
+			for range 3 {
+				if err := stream.SendMsg(in); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}},
+}
+
+type healthController struct {
+	err atomic.Pointer[error]
+}
+
+func (c *healthController) CheckHealth(context.Context) error {
+	if err := c.err.Load(); err != nil {
+		return *err
+	}
+	return nil
+}
+
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) record(msg string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := 0; i+1 < len(args); i += 2 {
+		if args[i] == "method" || args[i] == "code" {
+			msg += " " + args[i+1].(string)
+		}
+	}
+	l.messages = append(l.messages, msg)
+}
+
+func (l *recordingLogger) Debug(msg string, args ...any) { l.record(msg, args...) }
+func (l *recordingLogger) Info(msg string, args ...any)  { l.record(msg, args...) }
+func (l *recordingLogger) Warn(msg string, args ...any)  { l.record(msg, args...) }
+func (l *recordingLogger) Error(msg string, args ...any) { l.record(msg, args...) }
+func (l *recordingLogger) With(...any) logging.Logger    { return l }
+
+func (l *recordingLogger) contains(substr string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, msg := range l.messages {
+		if strings.Contains(msg, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+type harness struct {
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+	done   chan error
+}
+
+func startServer(t *testing.T, app *kernel.App, opts Options) *harness {
+	t.Helper()
+	ln := bufconn.Listen(1 << 20)
+	opts.Listener = ln
+	server, err := NewServer(app, opts)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &harness{cancel: cancel, done: make(chan error, 1)}
+	go func() { h.done <- server.Run(ctx) }()
+
+	h.conn, err = grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = h.conn.Close()
+		cancel()
+		<-h.done
+	})
+	return h
+}
+
+func (h *harness) stop(t *testing.T) {
+	t.Helper()
+	h.cancel()
+	select {
+	case err := <-h.done:
+		if err != nil {
+			t.Fatalf("expected clean shutdown, got %v", err)
+		}
+		h.done <- nil
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+func (h *harness) echo(value string) (string, error) {
+	out := new(wrapperspb.StringValue)
+	err := h.conn.Invoke(context.Background(), "/test.Echo/Echo", wrapperspb.String(value), out)
+	return out.GetValue(), err
+}
+
+func echoApp(controllers ...any) *kernel.App {
+	app := &kernel.App{Controllers: map[string]any{}}
+	for i, c := range controllers {
+		app.Controllers["test:"+string(rune('a'+i))] = c
+	}
+	return app
+}
+
+func TestServer_ServesControllerServices(t *testing.T) {
+	logger := &recordingLogger{}
+	h := startServer(t, echoApp(&echoService{}), Options{Logger: logger})
+
+	got, err := h.echo("hello")
+	if err != nil || got != "HELLO" {
+		t.Fatalf("expected HELLO, got %q (%v)", got, err)
+	}
+	if !logger.contains("grpc request /test.Echo/Echo OK") {
+		t.Fatalf("expected call log, got %v", logger.messages)
+	}
+}
+
+func TestServer_MapsErrorsToStatus(t *testing.T) {
+	logger := &recordingLogger{}
+	h := startServer(t, echoApp(&echoService{}), Options{Logger: logger})
+
+	tests := []struct {
+		input   string
+		code    codes.Code
+		message string
+	}{
+		{"not found", codes.NotFound, "user not found"},
+		{"invalid", codes.InvalidArgument, "invalid request"},
+		{"secret", codes.Internal, "internal error"},
+		{"status", codes.Aborted, "try again"},
+		{"panic", codes.Internal, "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := h.echo(tt.input)
+			st := status.Convert(err)
+			if st.Code() != tt.code || st.Message() != tt.message {
+				t.Fatalf("expected %s %q, got %s %q", tt.code, tt.message, st.Code(), st.Message())
+			}
+		})
+	}
+
+	_, err := h.echo("invalid")
+	details := status.Convert(err).Details()
+	badRequest, ok := details[0].(*errdetails.BadRequest)
+	if len(details) != 1 || !ok || badRequest.GetFieldViolations()[0].GetField() != "email" {
+		t.Fatalf("expected BadRequest details for email, got %v", details)
+	}
+	if !logger.contains("grpc panic /test.Echo/Echo") {
+		t.Fatalf("expected panic to be logged, got %v", logger.messages)
+	}
+}
+
+func TestServer_CustomErrorMapper(t *testing.T) {
+	mapper := ErrorMapperFunc(func(_ context.Context, err error) (*status.Status, bool) {
+		if err.Error() == "secret failure" {
+			return status.New(codes.Unavailable, "retry later"), true
+		}
+		return nil, false
+	})
+	h := startServer(t, echoApp(&echoService{}), Options{ErrorMapper: mapper})
+
+	if _, err := h.echo("secret"); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected mapped Unavailable, got %v", err)
+	}
+	if _, err := h.echo("not found"); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected default mapping for unhandled errors, got %v", err)
+	}
+}
+
+func TestServer_StreamInterceptors(t *testing.T) {
+	h := startServer(t, echoApp(&echoService{}), Options{})
+	desc := &echoDesc.Streams[0]
+
+	stream, err := h.conn.NewStream(context.Background(), desc, "/test.Echo/Repeat")
+	if err != nil {
+		t.Fatalf("NewStream: %v", err)
+	}
+	_ = stream.SendMsg(wrapperspb.String("hi"))
+	_ = stream.CloseSend()
+	count := 0
+	for {
+		if err := stream.RecvMsg(new(wrapperspb.StringValue)); err != nil {
+			break
+		}
+		count++
+	}
+	if count != 3 {
+		t.Fatalf("expected 3 messages, got %d", count)
+	}
+
+	stream, _ = h.conn.NewStream(context.Background(), desc, "/test.Echo/Repeat")
+	_ = stream.SendMsg(wrapperspb.String("panic"))
+	_ = stream.CloseSend()
+	err = stream.RecvMsg(new(wrapperspb.StringValue))
+	if st := status.Convert(err); st.Code() != codes.Internal || st.Message() != "internal error" {
+		t.Fatalf("expected recovered stream panic, got %v", err)
+	}
+}
+
+func TestServer_HealthReflectsAppChecks(t *testing.T) {
+	checker := &healthController{}
+	h := startServer(t, echoApp(&echoService{}, checker), Options{HealthWatchInterval: 10 * time.Millisecond})
+	client := healthpb.NewHealthClient(h.conn)
+	ctx := context.Background()
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", service, err)
+		}
+		return resp.GetStatus()
+	}
+
+	if got := check(""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING, got %s", got)
+	}
+	if got := check("test.Echo"); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING for registered service, got %s", got)
+	}
+	if _, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound for unknown service, got %v", err)
+	}
+
+	watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	expectWatch := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		resp, err := watch.Recv()
+		if err != nil || resp.GetStatus() != want {
+			t.Fatalf("expected watch status %s, got %v (%v)", want, resp.GetStatus(), err)
+		}
+	}
+	expectWatch(healthpb.HealthCheckResponse_SERVING)
+
+	down := errors.New("database down")
+	checker.err.Store(&down)
+	if got := check(""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING, got %s", got)
+	}
+	expectWatch(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	checker.err.Store(nil)
+	expectWatch(healthpb.HealthCheckResponse_SERVING)
+
+	h.stop(t)
+	expectWatch(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+func TestServer_GracefulStopWaitsForPendingCalls(t *testing.T) {
+	service := blockingEcho()
+	h := startServer(t, echoApp(service), Options{})
+
+	result := make(chan error, 1)
+	go func() {
+		got, err := h.echo("block")
+		if err == nil && got != "released" {
+			err = errors.New("unexpected reply " + got)
+		}
+		result <- err
+	}()
+	<-service.entered
+
+	h.cancel()
+	select {
+	case err := <-h.done:
+		t.Fatalf("Run returned before the pending call finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(service.release)
+	if err := <-result; err != nil {
+		t.Fatalf("expected pending call to complete, got %v", err)
+	}
+	h.stop(t)
+}
+
+func TestServer_ShutdownTimeoutCancelsPendingCalls(t *testing.T) {
+	service := blockingEcho()
+	h := startServer(t, echoApp(service), Options{ShutdownTimeout: 50 * time.Millisecond})
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := h.echo("block")
+		result <- err
+	}()
+	<-service.entered
+
+	h.stop(t)
+	if err := <-result; err == nil {
+		t.Fatal("expected pending call to be canceled")
+	}
+}
+
+func TestRegisterServices_RejectsDuplicateServices(t *testing.T) {
+	server := grpc.NewServer()
+	app := echoApp(&echoService{}, &echoService{})
+
+	err := RegisterServices(server, app)
+
+	var dup *DuplicateServiceError
+	if !errors.As(err, &dup) || dup.Service != "test.Echo" {
+		t.Fatalf("expected DuplicateServiceError, got %v", err)
+	}
+	if len(server.GetServiceInfo()) != 0 {
+		t.Fatal("expected nothing registered")
+	}
+	if err := RegisterServices(server, nil); !errors.Is(err, kernel.ErrNilApp) {
+		t.Fatalf("expected ErrNilApp, got %v", err)
+	}
+}
+
+func TestNewServer_DisableHealth(t *testing.T) {
+	server, err := NewServer(echoApp(&echoService{}), Options{DisableHealth: true})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	if _, ok := server.GRPC().GetServiceInfo()[healthpb.Health_ServiceDesc.ServiceName]; ok {
+		t.Fatal("expected no health service")
+	}
+}
+
+func TestCodeFromHTTPStatus(t *testing.T) {
+	tests := map[int]codes.Code{
+		200: codes.OK,
+		400: codes.InvalidArgument,
+		401: codes.Unauthenticated,
+		403: codes.PermissionDenied,
+		404: codes.NotFound,
+		409: codes.AlreadyExists,
+		418: codes.FailedPrecondition,
+		429: codes.ResourceExhausted,
+		500: codes.Internal,
+		503: codes.Unavailable,
+	}
+	for httpStatus, want := range tests {
+		if got := CodeFromHTTPStatus(httpStatus); got != want {
+			t.Fatalf("CodeFromHTTPStatus(%d) = %s, want %s", httpStatus, got, want)
+		}
+	}
+}
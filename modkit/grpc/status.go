@@ -0,0 +1,113 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+// ErrorMapper converts an error returned by a handler into a gRPC status. It
+// returns false when it does not handle err, so the default mapping applies.
+type ErrorMapper interface {
+	MapError(ctx context.Context, err error) (*status.Status, bool)
+}
+
+// ErrorMapperFunc adapts a function to ErrorMapper.
+type ErrorMapperFunc func(ctx context.Context, err error) (*status.Status, bool)
+
+// MapError calls f(ctx, err).
+func (f ErrorMapperFunc) MapError(ctx context.Context, err error) (*status.Status, bool) {
+	return f(ctx, err)
+}
+
+// DefaultErrorMapper keeps errors that already carry a gRPC status, maps
+// context cancellation and deadlines to their codes, and maps everything else
+// like http.DefaultErrorMapper: a *http.Problem, binding, and validation errors
+// keep their meaning (with invalid parameters as BadRequest details), and other
+// errors become Internal without leaking their text.
+var DefaultErrorMapper ErrorMapper = ErrorMapperFunc(defaultMapError)
+
+func defaultMapError(_ context.Context, err error) (*status.Status, bool) {
+	if st, ok := status.FromError(err); ok {
+		return st, true
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, "canceled"), true
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, "deadline exceeded"), true
+	}
+
+	problem, _ := mkhttp.DefaultErrorMapper.MapError(nil, err)
+	message := problem.Detail
+	if message == "" {
+		message = http.StatusText(problem.Status)
+	}
+	st := status.New(CodeFromHTTPStatus(problem.Status), message)
+	if len(problem.InvalidParams) == 0 {
+		return st, true
+	}
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(problem.InvalidParams))
+	for _, param := range problem.InvalidParams {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: param.Name, Description: param.Reason})
+	}
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+		st = detailed
+	}
+	return st, true
+}
+
+// CodeFromHTTPStatus returns the gRPC code closest to an HTTP status, so errors
+// shared with REST handlers map consistently.
+func CodeFromHTTPStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout, http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	}
+	switch {
+	case httpStatus >= 200 && httpStatus < 300:
+		return codes.OK
+	case httpStatus >= 400 && httpStatus < 500:
+		return codes.FailedPrecondition
+	default:
+		return codes.Internal
+	}
+}
+
+// mapError applies mapper and falls back to DefaultErrorMapper.
+func mapError(ctx context.Context, mapper ErrorMapper, err error) error {
+	if err == nil {
+		return nil
+	}
+	if mapper != nil {
+		if st, ok := mapper.MapError(ctx, err); ok {
+			return st.Err()
+		}
+	}
+	st, _ := DefaultErrorMapper.MapError(ctx, err)
+	return st.Err()
+}
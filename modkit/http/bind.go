@@ -0,0 +1,237 @@
+package http
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Bind decodes a JSON body into target and then fills fields tagged with
+// `path:"name"`, `query:"name"`, or `header:"Name"` from the request. Supported
+// field types are strings, bools, integers, floats, time.Duration,
+// encoding.TextUnmarshaler, and pointers or slices of those. Failures are
+// returned as a *BindError listing every invalid parameter.
+func Bind(r *http.Request, target any) error {
+	return bind(r, target, false)
+}
+
+func bind(r *http.Request, target any, allowUnknown bool) error {
+	if hasBody(r) {
+		decoder := json.NewDecoder(r.Body)
+		if !allowUnknown {
+			decoder.DisallowUnknownFields()
+		}
+		if err := decoder.Decode(target); err != nil && !errors.Is(err, io.EOF) {
+			return &BindError{Params: []InvalidParam{{Name: "body", Reason: "invalid JSON"}}, Err: err}
+		}
+	}
+
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return nil
+	}
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var bindErr *BindError
+	for _, field := range fieldBindings(value.Type()) {
+		raw := field.values(r)
+		if len(raw) == 0 {
+			continue
+		}
+		if err := setField(value.FieldByIndex(field.index), raw); err != nil {
+			if bindErr == nil {
+				bindErr = &BindError{Err: err}
+			}
+			bindErr.Params = append(bindErr.Params, InvalidParam{Name: field.name, Reason: reasonFor(value.FieldByIndex(field.index).Type())})
+		}
+	}
+	if bindErr != nil {
+		return bindErr
+	}
+	return nil
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// PathParam returns a path parameter from chi routing, ServeMux, or the standard
+// library mux. The name "*" returns the remainder matched by a trailing wildcard.
+func PathParam(r *http.Request, name string) string {
+	if value := chi.URLParam(r, name); value != "" {
+		return value
+	}
+	if name == "*" {
+		return r.PathValue(wildcardParam)
+	}
+	return r.PathValue(name)
+}
+
+type fieldBinding struct {
+	index  []int
+	source string
+	name   string
+}
+
+func (f fieldBinding) values(r *http.Request) []string {
+	switch f.source {
+	case "path":
+		if value := PathParam(r, f.name); value != "" {
+			return []string{value}
+		}
+		return nil
+	case "query":
+		return r.URL.Query()[f.name]
+	default:
+		return r.Header.Values(f.name)
+	}
+}
+
+var bindingCache sync.Map // reflect.Type -> []fieldBinding
+
+func fieldBindings(t reflect.Type) []fieldBinding {
+	if cached, ok := bindingCache.Load(t); ok {
+		return cached.([]fieldBinding)
+	}
+	bindings := collectBindings(t, nil)
+	bindingCache.Store(t, bindings)
+	return bindings
+}
+
+func collectBindings(t reflect.Type, parent []int) []fieldBinding {
+	var bindings []fieldBinding
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			bindings = append(bindings, collectBindings(field.Type, index)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		for _, source := range []string{"path", "query", "header"} {
+			if name, ok := field.Tag.Lookup(source); ok && name != "" && name != "-" {
+				bindings = append(bindings, fieldBinding{index: index, source: source, name: name})
+				break
+			}
+		}
+	}
+	return bindings
+}
+
+func paramInfos(t reflect.Type) []ParamInfo {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	bindings := fieldBindings(t)
+	params := make([]ParamInfo, 0, len(bindings))
+	for _, binding := range bindings {
+		field := t.FieldByIndex(binding.index)
+		params = append(params, ParamInfo{Name: binding.name, In: binding.source, Type: field.Type, Field: field})
+	}
+	return params
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func setField(field reflect.Value, raw []string) error {
+	if field.Kind() == reflect.Slice && !field.Type().Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(field.Type(), len(raw), len(raw))
+		for i, value := range raw {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, raw[0])
+}
+
+func setValue(field reflect.Value, raw string) error {
+	if field.Kind() == reflect.Pointer {
+		elem := reflect.New(field.Type().Elem())
+		if err := setValue(elem.Elem(), raw); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(v)
+	default:
+		return &UnsupportedBindTypeError{Type: field.Type().String()}
+	}
+	return nil
+}
+
+func reasonFor(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t == durationType {
+		return "must be a duration"
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "must be a number"
+	case reflect.Bool:
+		return "must be a boolean"
+	default:
+		return "invalid value"
+	}
+}
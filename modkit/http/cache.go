@@ -0,0 +1,280 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy is the HTTP caching policy of a route, set with CacheControl.
+type CachePolicy struct {
+	// MaxAge is how long clients may reuse a response.
+	MaxAge time.Duration `json:"maxAge,omitempty"`
+	// SharedMaxAge overrides MaxAge for shared caches such as CDNs.
+	SharedMaxAge time.Duration `json:"sharedMaxAge,omitempty"`
+	// StaleWhileRevalidate is how long a stale response may be served while it
+	// is revalidated in the background.
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate,omitempty"`
+	Public               bool          `json:"public,omitempty"`
+	Private              bool          `json:"private,omitempty"`
+	NoCache              bool          `json:"noCache,omitempty"`
+	NoStore              bool          `json:"noStore,omitempty"`
+	MustRevalidate       bool          `json:"mustRevalidate,omitempty"`
+	Immutable            bool          `json:"immutable,omitempty"`
+	// ETag answers conditional requests with 304 like Conditional.
+	ETag bool `json:"etag,omitempty"`
+	// Vary lists request headers that select the representation. They are sent
+	// in the Vary header and key the server-side cache.
+	Vary []string `json:"vary,omitempty"`
+
+	// Server, when set, caches responses server-side.
+	Server *ResponseCache `json:"-"`
+	// ServerTTL is how long Server keeps responses (default the cache's TTL).
+	ServerTTL time.Duration `json:"serverTTL,omitempty"`
+	// Tags label responses stored by Server for ResponseCache.Invalidate; add
+	// request-specific tags with AddCacheTags.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// String returns the Cache-Control header value of the policy.
+func (p CachePolicy) String() string {
+	var directives []string
+	add := func(ok bool, directive string) {
+		if ok {
+			directives = append(directives, directive)
+		}
+	}
+	seconds := func(d time.Duration) string {
+		return strconv.FormatInt(int64(d/time.Second), 10)
+	}
+	add(p.Public, "public")
+	add(p.Private, "private")
+	add(p.NoCache, "no-cache")
+	add(p.NoStore, "no-store")
+	add(p.MaxAge > 0, "max-age="+seconds(p.MaxAge))
+	add(p.SharedMaxAge > 0, "s-maxage="+seconds(p.SharedMaxAge))
+	add(p.StaleWhileRevalidate > 0, "stale-while-revalidate="+seconds(p.StaleWhileRevalidate))
+	add(p.MustRevalidate, "must-revalidate")
+	add(p.Immutable, "immutable")
+	return strings.Join(directives, ", ")
+}
+
+// CacheControl sets the caching policy of the route. Successful responses get
+// its Cache-Control header unless the handler sets one.
+func CacheControl(policy CachePolicy) RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Cache = &policy
+	}
+}
+
+// withCachePolicy applies the caching policy of meta, if any, around a route
+// handler: conditional requests first, then the response headers, then the
+// server-side cache.
+func withCachePolicy(next http.Handler, meta RouteMeta) http.Handler {
+	if meta.Cache == nil {
+		return next
+	}
+	return meta.Cache.wrap(next)
+}
+
+func (p *CachePolicy) wrap(next http.Handler) http.Handler {
+	if p.Server != nil {
+		next = p.Server.handler(p, next)
+	}
+	value := p.String()
+	vary := p.Vary
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range vary {
+			w.Header().Add("Vary", name)
+		}
+		if value != "" {
+			w = &cacheHeaderWriter{ResponseWriter: w, value: value}
+		}
+		next.ServeHTTP(w, r)
+	})
+	if p.ETag {
+		return Conditional()(handler)
+	}
+	return handler
+}
+
+// cacheHeaderWriter sets Cache-Control on successful responses that do not
+// have one, so errors are not cached.
+type cacheHeaderWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *cacheHeaderWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if status < http.StatusBadRequest && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheHeaderWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *cacheHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Conditional returns middleware that adds a strong ETag, computed from the
+// body, to 200 responses of GET requests without one, and answers
+// If-None-Match and If-Modified-Since (against Last-Modified) with 304 Not
+// Modified. It buffers responses, so do not use it on streaming routes.
+func Conditional() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			buf := newBufferedWriter(w)
+			next.ServeHTTP(buf, r)
+			if buf.status() == http.StatusOK {
+				if r.Method == http.MethodGet && w.Header().Get("ETag") == "" {
+					w.Header().Set("ETag", computeETag(buf.body.Bytes()))
+				}
+				if notModified(r, w.Header()) {
+					writeNotModified(w)
+					return
+				}
+			}
+			buf.flush()
+		})
+	}
+}
+
+// CheckNotModified sets the ETag and Last-Modified headers of w when they are
+// not empty, and reports whether r is a conditional GET or HEAD request they
+// satisfy. In that case it has written 304 Not Modified and the handler should
+// return without rendering the body.
+func CheckNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if notModified(r, w.Header()) {
+		writeNotModified(w)
+		return true
+	}
+	return false
+}
+
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when the request
+// has no If-None-Match, against the response headers; see RFC 9110, section
+// 13.2.2.
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETag(candidate) == weakETag(etag) {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	lastModified := header.Get("Last-Modified")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// weakETag strips the weak prefix, for the weak comparison If-None-Match uses.
+func weakETag(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	if h.Get("ETag") != "" {
+		h.Del("Last-Modified")
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// bufferedWriter holds a response until flush. It shares the header map of the
+// underlying writer.
+type bufferedWriter struct {
+	w      http.ResponseWriter
+	code   int
+	body   bytes.Buffer
+	header http.Header
+}
+
+func newBufferedWriter(w http.ResponseWriter) *bufferedWriter {
+	return &bufferedWriter{w: w, header: w.Header()}
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.code == 0 {
+		b.code = status
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.code == 0 {
+		b.code = http.StatusOK
+	}
+	return b.body.Write(p)
+}
+
+// status returns the response status; 200 when the handler wrote nothing.
+func (b *bufferedWriter) status() int {
+	if b.code == 0 {
+		return http.StatusOK
+	}
+	return b.code
+}
+
+func (b *bufferedWriter) flush() {
+	b.w.WriteHeader(b.status())
+	if b.body.Len() > 0 {
+		_, _ = b.w.Write(b.body.Bytes())
+	}
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCachePolicy_String(t *testing.T) {
+	cases := []struct {
+		policy CachePolicy
+		want   string
+	}{
+		{CachePolicy{}, ""},
+		{CachePolicy{Public: true, MaxAge: time.Minute, SharedMaxAge: time.Hour}, "public, max-age=60, s-maxage=3600"},
+		{CachePolicy{Private: true, NoCache: true, MustRevalidate: true}, "private, no-cache, must-revalidate"},
+		{CachePolicy{MaxAge: 365 * 24 * time.Hour, Immutable: true}, "max-age=31536000, immutable"},
+		{CachePolicy{NoStore: true}, "no-store"},
+		{CachePolicy{MaxAge: time.Second, StaleWhileRevalidate: 30 * time.Second}, "max-age=1, stale-while-revalidate=30"},
+	}
+	for _, tc := range cases {
+		if got := tc.policy.String(); got != tc.want {
+			t.Fatalf("String() = %q, want %q", got, tc.want)
+		}
+	}
+}
+
+func TestConditional_AnswersIfNoneMatchWith304(t *testing.T) {
+	handler := Conditional()(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":1}`))
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Body.String() != `{"id":1}` {
+		t.Fatalf("unexpected response: %d etag=%q body=%q", rec.Code, etag, rec.Body.String())
+	}
+
+	for _, inm := range []string{etag, "W/" + etag, `"other", ` + etag, "*"} {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("If-None-Match", inm)
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Fatalf("If-None-Match %q: expected empty 304, got %d %q", inm, rec.Code, rec.Body.String())
+		}
+		if rec.Header().Get("ETag") != etag || rec.Header().Get("Content-Type") != "" {
+			t.Fatalf("If-None-Match %q: unexpected headers %v", inm, rec.Header())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"id":1}` {
+		t.Fatalf("expected full response for a stale ETag, got %d", rec.Code)
+	}
+}
+
+func TestConditional_SkipsErrorsAndUnsafeMethods(t *testing.T) {
+	handler := Conditional()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			WriteError(w, r, NewProblem(http.StatusNotFound, "missing"))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Fatalf("expected 404 without ETag, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", http.NoBody))
+	if rec.Code != http.StatusCreated || rec.Header().Get("ETag") != "" {
+		t.Fatalf("expected POST to pass through, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestCheckNotModified_IfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	serve := func(since time.Time) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("If-Modified-Since", since.Format(http.TimeFormat))
+		rec := httptest.NewRecorder()
+		if !CheckNotModified(rec, req, "", modified) {
+			rec.WriteHeader(http.StatusOK)
+		}
+		return rec
+	}
+
+	rec := serve(modified)
+	if rec.Code != http.StatusNotModified || rec.Header().Get("Last-Modified") != modified.Format(http.TimeFormat) {
+		t.Fatalf("expected 304 with Last-Modified, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := serve(modified.Add(-time.Hour)); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for an older If-Modified-Since, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("If-None-Match", `"v2"`)
+	req.Header.Set("If-Modified-Since", modified.Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	if CheckNotModified(rec, req, `"v1"`, modified) {
+		t.Fatalf("If-None-Match must take precedence over If-Modified-Since")
+	}
+}
+
+func TestCacheControl_RoutePolicy(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		policy := CachePolicy{Public: true, MaxAge: time.Minute, ETag: true, Vary: []string{"Accept-Language"}}
+		router.Handle(http.MethodGet, "/items", Route(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("items"))
+		}), CacheControl(policy)))
+		router.Handle(http.MethodGet, "/missing", Route(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, errors.New("boom"))
+		}), CacheControl(policy)))
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", http.NoBody))
+		if rec.Header().Get("Cache-Control") != "public, max-age=60" || rec.Header().Get("Vary") != "Accept-Language" {
+			t.Fatalf("unexpected headers: %v", rec.Header())
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/items", http.NoBody)
+		req.Header.Set("If-None-Match", rec.Header().Get("ETag"))
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Header().Get("Cache-Control") != "public, max-age=60" {
+			t.Fatalf("expected 304 with Cache-Control, got %d %v", rec.Code, rec.Header())
+		}
+
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", http.NoBody))
+		if rec.Code != http.StatusInternalServerError || rec.Header().Get("Cache-Control") != "" {
+			t.Fatalf("expected error without Cache-Control, got %d %v", rec.Code, rec.Header())
+		}
+
+		routes := Routes(router)
+		if len(routes) != 2 || routes[0].Meta.Cache == nil || routes[0].Meta.Cache.MaxAge != time.Minute {
+			t.Fatalf("expected cache policy in route table, got %+v", routes)
+		}
+	})
+}
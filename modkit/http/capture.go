@@ -0,0 +1,49 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type routeCaptureKey struct{}
+
+// routeCapture receives the pattern of the route that serves a request.
+type routeCapture struct {
+	pattern atomic.Pointer[string]
+}
+
+// CaptureRoute returns a copy of r that records the route serving it, and a
+// function returning that route's full pattern once the handler has run. It is
+// for middleware that label requests by route rather than raw path, such as
+// metrics. The pattern is empty when no route matched. Nested middleware share
+// the capture of the outermost call, so each of them sees the pattern.
+func CaptureRoute(r *http.Request) (*http.Request, func() string) {
+	capture, ok := r.Context().Value(routeCaptureKey{}).(*routeCapture)
+	if !ok {
+		capture = &routeCapture{}
+		r = r.WithContext(context.WithValue(r.Context(), routeCaptureKey{}, capture))
+	}
+	return r, func() string {
+		if pattern := capture.pattern.Load(); pattern != nil {
+			return *pattern
+		}
+		// Routes registered directly on a chi router, outside an adapter.
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			return rctx.RoutePattern()
+		}
+		return ""
+	}
+}
+
+// recordRoute stores pattern in the capture of requests served by handler.
+func recordRoute(pattern string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if capture, ok := r.Context().Value(routeCaptureKey{}).(*routeCapture); ok {
+			capture.pattern.Store(&pattern)
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
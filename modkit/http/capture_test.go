@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func captureMiddleware(got *string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, pattern := CaptureRoute(r)
+			next.ServeHTTP(w, r)
+			*got = pattern()
+		})
+	}
+}
+
+func TestCaptureRoute_ReportsRoutePattern(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		var got string
+		router.Use(captureMiddleware(&got))
+		router.Group("/users", func(r Router) {
+			r.Handle(http.MethodGet, "/{id}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+		})
+
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		if got != "/users/{id}" {
+			t.Fatalf("expected /users/{id}, got %q", got)
+		}
+
+		got = "unset"
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+		if got != "" {
+			t.Fatalf("expected empty pattern for unmatched request, got %q", got)
+		}
+	})
+}
+
+func TestCaptureRoute_VersionedPattern(t *testing.T) {
+	router := chi.NewRouter()
+	adapter := AsRouter(router)
+	var got string
+	adapter.Use(captureMiddleware(&got))
+	UseVersioning(adapter, Versioning{Type: VersionURI})
+	adapter.Handle(http.MethodGet, "/items", Route(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), Versions("1")))
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/items", nil))
+	if got != "/v1/items" {
+		t.Fatalf("expected /v1/items, got %q", got)
+	}
+}
+
+func TestCaptureRoute_FallsBackToChiPattern(t *testing.T) {
+	router := chi.NewRouter()
+	var got string
+	router.Use(captureMiddleware(&got))
+	router.Get("/raw/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/raw/1", nil))
+	if got != "/raw/{id}" {
+		t.Fatalf("expected /raw/{id}, got %q", got)
+	}
+}
+
+func TestCaptureRoute_NestedCapturesShareThePattern(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		var outer, inner string
+		router.Use(captureMiddleware(&outer))
+		router.Use(captureMiddleware(&inner))
+		router.Handle(http.MethodGet, "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+		if outer != "/users/{id}" || inner != "/users/{id}" {
+			t.Fatalf("expected both captures to see /users/{id}, got outer=%q inner=%q", outer, inner)
+		}
+	})
+}
@@ -0,0 +1,316 @@
+package http
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultCompressMinSize is the minimum response size compressed when
+// CompressConfig.MinSize is zero.
+const DefaultCompressMinSize = 1024
+
+// compressedTypes are media types, or type prefixes ending in "/", whose
+// content is already compressed or streamed.
+var compressedTypes = []string{
+	"image/", "video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/zstd",
+	"application/x-bzip2", "application/x-7z-compressed", "application/x-rar-compressed",
+	"application/x-xz", "application/pdf", "application/wasm",
+	"text/event-stream",
+}
+
+// compressibleImages are image types that are text and compress well.
+var compressibleImages = []string{"image/svg+xml", "image/bmp", "image/x-icon"}
+
+// CompressConfig configures Compress.
+type CompressConfig struct {
+	// Level is the gzip and deflate compression level (default
+	// gzip.DefaultCompression).
+	Level int
+	// MinSize is the smallest body compressed, in bytes (default
+	// DefaultCompressMinSize).
+	MinSize int
+	// SkipTypes lists more media types, or prefixes ending in "/", that are not
+	// compressed. Images, audio, video, archives, and event streams are always
+	// skipped.
+	SkipTypes []string
+}
+
+// Compress returns middleware that compresses responses with gzip or deflate,
+// as accepted by the Accept-Encoding header (gzip when both are equally
+// acceptable). Responses smaller than MinSize, responses that already have a
+// Content-Encoding, and skipped types are sent as is. It adds Vary:
+// Accept-Encoding to every response it could compress.
+func Compress(cfg CompressConfig) func(http.Handler) http.Handler {
+	if cfg.Level == 0 {
+		cfg.Level = gzip.DefaultCompression
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = DefaultCompressMinSize
+	}
+	// Invalid levels fail here rather than on the first request.
+	if _, err := gzip.NewWriterLevel(io.Discard, cfg.Level); err != nil {
+		panic("http.Compress: " + err.Error())
+	}
+	c := &compressor{
+		level:     cfg.Level,
+		minSize:   cfg.MinSize,
+		skipTypes: append(append([]string(nil), compressedTypes...), cfg.SkipTypes...),
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := acceptedEncoding(r.Header.Values("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, c: c, encoding: encoding}
+			next.ServeHTTP(cw, r)
+			// Not deferred: after a panic the buffered start of the response is
+			// dropped so recovery middleware can still write an error.
+			cw.close()
+		})
+	}
+}
+
+// acceptedEncoding returns "gzip", "deflate", or "" for the Accept-Encoding
+// header values.
+func acceptedEncoding(values []string) string {
+	var gzipQ, deflateQ, anyQ float64 = -1, -1, -1
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+			q := 1.0
+			if key, raw, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+				if err != nil {
+					continue
+				}
+				q = parsed
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "gzip", "x-gzip":
+				gzipQ = q
+			case "deflate":
+				deflateQ = q
+			case "*":
+				anyQ = q
+			}
+		}
+	}
+	if gzipQ < 0 {
+		gzipQ = anyQ
+	}
+	if deflateQ < 0 {
+		deflateQ = anyQ
+	}
+	switch {
+	case gzipQ > 0 && gzipQ >= deflateQ:
+		return "gzip"
+	case deflateQ > 0:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+type compressor struct {
+	level       int
+	minSize     int
+	skipTypes   []string
+	gzipPool    sync.Pool
+	deflatePool sync.Pool
+}
+
+func (c *compressor) writer(encoding string, w io.Writer) io.WriteCloser {
+	if encoding == "gzip" {
+		if gw, ok := c.gzipPool.Get().(*gzip.Writer); ok {
+			gw.Reset(w)
+			return gw
+		}
+		gw, _ := gzip.NewWriterLevel(w, c.level)
+		return gw
+	}
+	if fw, ok := c.deflatePool.Get().(*flate.Writer); ok {
+		fw.Reset(w)
+		return fw
+	}
+	fw, _ := flate.NewWriter(w, c.level)
+	return fw
+}
+
+func (c *compressor) release(enc io.WriteCloser) {
+	switch enc := enc.(type) {
+	case *gzip.Writer:
+		c.gzipPool.Put(enc)
+	case *flate.Writer:
+		c.deflatePool.Put(enc)
+	}
+}
+
+func (c *compressor) skipped(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+	for _, image := range compressibleImages {
+		if mediaType == image {
+			return false
+		}
+	}
+	for _, skip := range c.skipTypes {
+		if strings.HasSuffix(skip, "/") && strings.HasPrefix(mediaType, skip) || mediaType == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// compressWriter buffers the start of a response until it knows whether the
+// response is large enough to compress.
+type compressWriter struct {
+	http.ResponseWriter
+	c        *compressor
+	encoding string
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if status < http.StatusOK {
+		// Informational responses such as 103 Early Hints pass through.
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	if !w.compressible() {
+		_ = w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) >= w.c.minSize {
+			if err := w.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(p), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends the buffered start of the response, compressed when it reached
+// the minimum size, and flushes the compressor.
+func (w *compressWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		_ = w.decide(len(w.buf) >= w.c.minSize)
+	}
+	if flusher, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible reports whether the status and headers allow compression.
+func (w *compressWriter) compressible() bool {
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || w.status == http.StatusPartialContent {
+		return false
+	}
+	h := w.Header()
+	// A range addresses bytes of the identity body; compressing it would make
+	// Content-Range describe the wrong bytes.
+	if h.Get("Content-Range") != "" {
+		return false
+	}
+	if ce := h.Get("Content-Encoding"); ce != "" && !strings.EqualFold(ce, "identity") {
+		return false
+	}
+	if cl := h.Get("Content-Length"); cl != "" {
+		if n, err := strconv.Atoi(cl); err == nil && n < w.c.minSize {
+			return false
+		}
+	}
+	if ct := h.Get("Content-Type"); ct != "" && w.c.skipped(ct) {
+		return false
+	}
+	return true
+}
+
+// decide writes the header and the buffered body, compressing them when
+// compress is true and the response allows it.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	if compress && h.Get("Content-Type") == "" {
+		// Sniff before compressing, as net/http would sniff the compressed bytes.
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if compress && w.compressible() {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		h.Del("Accept-Ranges")
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			// The compressed body is a different representation.
+			h.Set("ETag", "W/"+etag)
+		}
+		w.enc = w.c.writer(w.encoding, w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (w *compressWriter) close() {
+	if w.status == 0 && !w.decided {
+		// The handler wrote nothing, or hijacked the connection.
+		return
+	}
+	if !w.decided {
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.c.release(w.enc)
+		w.enc = nil
+	}
+}
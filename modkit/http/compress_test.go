@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressRequest(acceptEncoding string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	return req
+}
+
+func writeBody(contentType, body string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		_, _ = io.WriteString(w, body)
+	})
+}
+
+func TestCompress_GzipAndDeflate(t *testing.T) {
+	body := strings.Repeat("hello world ", 200)
+	handler := Compress(CompressConfig{})(writeBody("text/plain", body))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip, deflate"))
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("expected gzip, got %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != body {
+		t.Fatalf("unexpected gzip body of %d bytes", len(got))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip;q=0.5, deflate"))
+	if rec.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("expected deflate, got %v", rec.Header())
+	}
+	if got, _ := io.ReadAll(flate.NewReader(rec.Body)); string(got) != body {
+		t.Fatalf("unexpected deflate body of %d bytes", len(got))
+	}
+}
+
+func TestCompress_SkipsSmallCompressedPartialAndUnacceptedResponses(t *testing.T) {
+	large := strings.Repeat("x", 2048)
+	cases := []struct {
+		name           string
+		handler        http.Handler
+		acceptEncoding string
+	}{
+		{"small", writeBody("text/plain", "tiny"), "gzip"},
+		{"image", writeBody("image/png", large), "gzip"},
+		{"configured skip", writeBody("application/x-custom", large), "gzip"},
+		{"already encoded", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = io.WriteString(w, large)
+		}), "gzip"},
+		{"partial content", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = io.WriteString(w, large)
+		}), "gzip"},
+		{"content range", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("Content-Range", "bytes 0-2047/4096")
+			_, _ = io.WriteString(w, large)
+		}), "gzip"},
+		{"not accepted", writeBody("text/plain", large), "br, gzip;q=0"},
+		{"no header", writeBody("text/plain", large), ""},
+	}
+	for _, tc := range cases {
+		handler := Compress(CompressConfig{SkipTypes: []string{"application/x-custom"}})(tc.handler)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, compressRequest(tc.acceptEncoding))
+		if ce := rec.Header().Get("Content-Encoding"); ce == "gzip" || ce == "deflate" {
+			t.Fatalf("%s: expected no compression, got %q", tc.name, ce)
+		}
+		if rec.Body.Len() == 0 {
+			t.Fatalf("%s: expected body to be written", tc.name)
+		}
+	}
+}
+
+func TestCompress_SniffsContentTypeAndWeakensETag(t *testing.T) {
+	body := "<html><body>" + strings.Repeat("<p>hi</p>", 300) + "</body></html>"
+	handler := Compress(CompressConfig{MinSize: 64})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("ETag", `"abc"`)
+		w.Header().Set("Content-Length", "9999")
+		_, _ = io.WriteString(w, body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip"))
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("expected sniffed content type, got %q", rec.Header().Get("Content-Type"))
+	}
+	if rec.Header().Get("ETag") != `W/"abc"` || rec.Header().Get("Content-Length") != "" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+}
+
+func TestCompress_FlushesStreamedResponses(t *testing.T) {
+	handler := Compress(CompressConfig{MinSize: 4})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "first chunk")
+		http.NewResponseController(w).Flush()
+		_, _ = io.WriteString(w, " second chunk")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip"))
+	if !rec.Flushed || rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected flushed gzip response, flushed=%v headers=%v", rec.Flushed, rec.Header())
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != "first chunk second chunk" {
+		t.Fatalf("unexpected body %q", got)
+	}
+}
+
+func TestCompress_TypedHandlerResponse(t *testing.T) {
+	items := make([]negotiatedItem, 100)
+	handler := Compress(CompressConfig{})(Handle(func(context.Context, struct{}) ([]negotiatedItem, error) {
+		return items, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, compressRequest("gzip"))
+	if rec.Header().Get("Content-Encoding") != "gzip" || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+}
+
+func TestCompress_PanicLeavesResponseUnwritten(t *testing.T) {
+	handler := Compress(CompressConfig{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "partial")
+		panic("boom")
+	}))
+
+	rec := httptest.NewRecorder()
+	func() {
+		defer func() { _ = recover() }()
+		handler.ServeHTTP(rec, compressRequest("gzip"))
+	}()
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected nothing written after panic, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware.
+type CORSConfig struct {
+	// AllowedOrigins lists the allowed origins; "*" allows any origin. No
+	// origins disables CORS headers.
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, HEAD, POST, PUT, PATCH, and DELETE.
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers a preflight may ask for; when
+	// empty, the requested headers are allowed.
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers readable by the browser.
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and authorization headers. The request
+	// origin is echoed instead of "*".
+	AllowCredentials bool
+	// MaxAge is how long preflight results may be cached; zero omits the header.
+	MaxAge time.Duration
+}
+
+var defaultCORSMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// CORS returns middleware that adds CORS headers for allowed origins and answers
+// preflight requests (OPTIONS with Access-Control-Request-Method) with 204
+// without calling the next handler. Use it on the router or a module prefix so
+// it also sees preflights for paths without an OPTIONS route.
+func CORS(cfg CORSConfig) func(http.Handler) http.Handler {
+	origins := trimValues(cfg.AllowedOrigins)
+	anyOrigin := slices.Contains(origins, "*")
+	methods := trimValues(cfg.AllowedMethods)
+	if len(methods) == 0 {
+		methods = append([]string(nil), defaultCORSMethods...)
+	}
+	for i := range methods {
+		methods[i] = strings.ToUpper(methods[i])
+	}
+	allowMethods := strings.Join(methods, ", ")
+	headers := trimValues(cfg.AllowedHeaders)
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(trimValues(cfg.ExposedHeaders), ", ")
+	maxAge := ""
+	if cfg.MaxAge > 0 {
+		maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	allowed := func(origin string) bool {
+		return anyOrigin || slices.Contains(origins, origin)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if origin == "" || len(origins) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if preflight {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+			}
+			if allowed(origin) {
+				if anyOrigin && !cfg.AllowCredentials {
+					h.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					h.Set("Access-Control-Allow-Origin", origin)
+				}
+				if cfg.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			}
+
+			if !preflight {
+				if allowed(origin) && exposeHeaders != "" {
+					h.Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+			if allowed(origin) && slices.Contains(methods, method) {
+				h.Set("Access-Control-Allow-Methods", allowMethods)
+				if allowHeaders != "" {
+					h.Set("Access-Control-Allow-Headers", allowHeaders)
+				} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					h.Set("Access-Control-Allow-Headers", requested)
+				}
+				if maxAge != "" {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func trimValues(values []string) []string {
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if trimmed := strings.TrimSpace(value); trimmed != "" {
+			out = append(out, trimmed)
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func serveCORS(t *testing.T, cfg CORSConfig, req *http.Request) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	called := false
+	handler := CORS(cfg)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec, called
+}
+
+func TestCORS_AllowedOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", http.NoBody)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec, called := serveCORS(t, CORSConfig{
+		AllowedOrigins: []string{"https://app.example.com"},
+		ExposedHeaders: []string{"X-Request-Id"},
+	}, req)
+
+	if !called || rec.Code != http.StatusOK {
+		t.Fatalf("expected next handler, got %d", rec.Code)
+	}
+	h := rec.Header()
+	if h.Get("Access-Control-Allow-Origin") != "https://app.example.com" || h.Get("Vary") != "Origin" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	if h.Get("Access-Control-Expose-Headers") != "X-Request-Id" {
+		t.Fatalf("expected exposed headers, got %v", h)
+	}
+}
+
+func TestCORS_DisallowedOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", http.NoBody)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec, called := serveCORS(t, CORSConfig{AllowedOrigins: []string{"https://app.example.com"}}, req)
+
+	if !called || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("expected request without CORS headers, got %v", rec.Header())
+	}
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	cfg := CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"get", "post"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	req := httptest.NewRequest(http.MethodOptions, "/users", http.NoBody)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "Content-Type, Authorization")
+	rec, called := serveCORS(t, cfg, req)
+
+	if called || rec.Code != http.StatusNoContent {
+		t.Fatalf("expected preflight to short-circuit with 204, got %d (called=%v)", rec.Code, called)
+	}
+	h := rec.Header()
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST",
+		"Access-Control-Allow-Headers":     "Content-Type, Authorization",
+		"Access-Control-Max-Age":           "600",
+	}
+	for key, value := range want {
+		if h.Get(key) != value {
+			t.Fatalf("%s: expected %q, got %q", key, value, h.Get(key))
+		}
+	}
+
+	req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+	rec, _ = serveCORS(t, cfg, req)
+	if rec.Code != http.StatusNoContent || rec.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Fatalf("expected disallowed method without allow headers, got %v", rec.Header())
+	}
+}
+
+func TestCORS_WildcardWithoutCredentials(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users", http.NoBody)
+	req.Header.Set("Origin", "https://any.example.com")
+	rec, _ := serveCORS(t, CORSConfig{AllowedOrigins: []string{"*"}}, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("expected wildcard origin, got %v", rec.Header())
+	}
+}
+
+func TestCORS_NoOriginsDisabled(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/users", http.NoBody)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	if _, called := serveCORS(t, CORSConfig{}, req); !called {
+		t.Fatal("expected disabled CORS to pass preflights through")
+	}
+}
+
+func TestCORS_ConcurrentDefaultMethods(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = CORS(CORSConfig{AllowedOrigins: []string{"*"}})
+		}()
+	}
+	wg.Wait()
+	if defaultCORSMethods[0] != http.MethodGet {
+		t.Fatalf("expected default methods to be unchanged, got %v", defaultCORSMethods)
+	}
+}
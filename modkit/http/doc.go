@@ -4,5 +4,6 @@
 // invoked via RegisterRoutes. No reflection is used.
 //
 // NewRouter returns a chi.Router with baseline middleware. Use AsRouter to adapt
-// it to the method-based Router interface.
+// it to the method-based Router interface, or NewServeMux for a Router backed by
+// http.ServeMux.
 package http
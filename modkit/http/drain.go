@@ -0,0 +1,156 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
+)
+
+type drainKey struct{}
+
+// drainState tracks the two stages of a server shutdown: stopping starts the
+// drain phase, when readiness fails but requests are still accepted, and
+// draining starts http.Server.Shutdown.
+type drainState struct {
+	stopping  chan struct{}
+	draining  chan struct{}
+	stopOnce  sync.Once
+	drainOnce sync.Once
+}
+
+func (d *drainState) stop() {
+	d.stopOnce.Do(func() { close(d.stopping) })
+}
+
+func (d *drainState) drain() {
+	d.drainOnce.Do(func() { close(d.draining) })
+}
+
+// Draining returns a channel closed when the server serving the request starts
+// shutting down, for handlers of long-lived responses such as SSE streams and
+// WebSocket connections, which http.Server.Shutdown does not interrupt. It
+// returns nil, which blocks forever, for requests not served by Serve or
+// Server.
+func Draining(ctx context.Context) <-chan struct{} {
+	if d, ok := ctx.Value(drainKey{}).(*drainState); ok {
+		return d.draining
+	}
+	return nil
+}
+
+// Stopping reports whether the server serving the request has entered its
+// drain phase. It is false for requests not served by Serve or Server.
+func Stopping(ctx context.Context) bool {
+	d, ok := ctx.Value(drainKey{}).(*drainState)
+	if !ok {
+		return false
+	}
+	select {
+	case <-d.stopping:
+		return true
+	default:
+		return false
+	}
+}
+
+// withDrain makes server carry a drainState in the context of its requests,
+// whose Draining channel closes when Shutdown starts.
+func withDrain(server *http.Server) (*http.Server, *drainState) {
+	d := &drainState{stopping: make(chan struct{}), draining: make(chan struct{})}
+	base := server.BaseContext
+	server.BaseContext = func(ln net.Listener) context.Context {
+		ctx := context.Background()
+		if base != nil {
+			ctx = base(ln)
+		}
+		return context.WithValue(ctx, drainKey{}, d)
+	}
+	server.RegisterOnShutdown(d.drain)
+	return server, d
+}
+
+// drainConfig holds the phase settings of gracefulShutdown.
+type drainConfig struct {
+	name         string
+	preStopDelay time.Duration
+	timeout      time.Duration
+	logger       logging.Logger
+	shutdown     func(context.Context, *http.Server) error
+}
+
+// gracefulShutdown drains server in phases: readiness fails and keep-alives
+// stop, requests are still accepted for the pre-stop delay, then the listeners
+// close and in-flight requests get cfg.timeout to finish. Each phase is logged.
+// The pre-stop delay ends early when ctx is done.
+func gracefulShutdown(ctx context.Context, server *http.Server, d *drainState, cfg drainConfig) error {
+	logger := cfg.logger
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	if cfg.name != "" {
+		logger = logger.With("server", cfg.name)
+	}
+
+	d.stop()
+	server.SetKeepAlivesEnabled(false)
+	logger.Info("http drain: readiness failing", "pre_stop_delay", cfg.preStopDelay)
+	if cfg.preStopDelay > 0 {
+		timer := time.NewTimer(cfg.preStopDelay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			logger.Info("http drain: pre-stop delay cut short")
+		}
+	}
+
+	logger.Info("http drain: closing listeners", "timeout", cfg.timeout)
+	start := time.Now()
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.timeout)
+	defer cancel()
+	if err := cfg.shutdown(shutdownCtx, server); err != nil {
+		logger.Warn("http drain: in-flight requests did not finish", "elapsed", time.Since(start), "error", err)
+		return err
+	}
+	logger.Info("http drain: in-flight requests finished", "elapsed", time.Since(start))
+	return nil
+}
+
+// drainContext returns a context for gracefulShutdown once ctx is canceled: it
+// keeps the values of ctx and ends at its deadline, if any, so a caller's
+// deadline still bounds the pre-stop delay.
+func drainContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	drainCtx := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(drainCtx, deadline)
+	}
+	return context.WithCancel(drainCtx)
+}
+
+// ReadinessHandler returns a readiness probe for Serve and Server. It answers
+// 503 Problem Details once the serving server enters its drain phase or while
+// app.CheckHealth fails, and 200 {"status":"ok"} otherwise. A nil app only
+// reports the drain phase.
+func ReadinessHandler(app *kernel.App) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if Stopping(r.Context()) {
+			WriteProblem(w, r, Problem{Status: http.StatusServiceUnavailable, Detail: "server is draining"})
+			return
+		}
+		if app != nil {
+			if err := app.CheckHealth(r.Context()); err != nil {
+				WriteProblem(w, r, Problem{Status: http.StatusServiceUnavailable, Detail: "health check failed"})
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+}
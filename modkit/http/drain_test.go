@@ -0,0 +1,154 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+)
+
+type failingHealth struct{}
+
+func (failingHealth) CheckHealth(context.Context) error { return errors.New("db down") }
+
+func TestReadinessHandler_ReportsHealth(t *testing.T) {
+	serve := func(app *kernel.App) int {
+		rec := httptest.NewRecorder()
+		ReadinessHandler(app).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", http.NoBody))
+		return rec.Code
+	}
+	if code := serve(nil); code != http.StatusOK {
+		t.Fatalf("expected 200 without app, got %d", code)
+	}
+	if code := serve(&kernel.App{Controllers: map[string]any{"db:Health": failingHealth{}}}); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for failing health check, got %d", code)
+	}
+}
+
+func TestServer_DrainPhases(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle("/ready", ReadinessHandler(nil))
+	mux.Handle("/", okHandler())
+
+	app, err := kernel.Bootstrap(&cleanupModule{cleanup: func(context.Context) error { return nil }})
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	logger := &captureLogger{}
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := startServe(t, ctx, ServeOptions{
+		Servers: []*Server{{
+			Addr:         "127.0.0.1:0",
+			Handler:      mux,
+			PreStopDelay: 300 * time.Millisecond,
+			OnReady:      func(addr net.Addr) { ready <- addr },
+		}},
+		App:    app,
+		Logger: logger,
+	})
+	base := "http://" + (<-ready).String()
+	if _, resp := getBody(t, http.DefaultClient, base+"/ready"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected ready before shutdown, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, resp := getBody(t, http.DefaultClient, base+"/ready")
+		if resp.StatusCode == http.StatusServiceUnavailable {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected readiness to fail during the drain phase")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if body, _ := getBody(t, http.DefaultClient, base+"/"); body != "ok" {
+		t.Fatalf("expected requests to be served during the pre-stop delay, got %q", body)
+	}
+
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	want := []string{
+		"http drain: readiness failing",
+		"http drain: closing listeners",
+		"http drain: in-flight requests finished",
+		"http drain: shutting down app",
+		"http drain: app shut down",
+	}
+	if !reflect.DeepEqual(logger.messages, want) {
+		t.Fatalf("unexpected drain log:\n got %q\nwant %q", logger.messages, want)
+	}
+}
+
+func TestServer_DrainTimeoutIsReported(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	logger := &captureLogger{}
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	server := &Server{
+		Name: "public",
+		Addr: "127.0.0.1:0",
+		Handler: http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			close(started)
+			<-release
+		}),
+		ShutdownTimeout: 50 * time.Millisecond,
+		Logger:          logger,
+		OnReady:         func(addr net.Addr) { ready <- addr },
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+
+	go func() {
+		resp, err := http.Get("http://" + (<-ready).String())
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+	cancel()
+
+	err := waitErr(t, errCh)
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected shutdown timeout, got %v", err)
+	}
+	if last := logger.messages[len(logger.messages)-1]; last != "http drain: in-flight requests did not finish" {
+		t.Fatalf("expected timeout to be logged, got %q", logger.messages)
+	}
+}
+
+func TestServer_PreStopDelayEndsAtContextDeadline(t *testing.T) {
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	logger := &captureLogger{}
+	server := &Server{
+		Addr:         "127.0.0.1:0",
+		Handler:      okHandler(),
+		PreStopDelay: time.Minute,
+		Logger:       logger,
+		OnReady:      func(addr net.Addr) { ready <- addr },
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+	<-ready
+
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if len(logger.messages) < 2 || logger.messages[1] != "http drain: pre-stop delay cut short" {
+		t.Fatalf("expected the pre-stop delay to be cut short, got %q", logger.messages)
+	}
+}
@@ -0,0 +1,165 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// ErrorRule maps errors accepted by Match to a problem with a fixed status, code,
+// and detail message.
+type ErrorRule struct {
+	Match   func(err error) bool
+	Status  int
+	Code    string
+	Message string
+}
+
+// MapError implements ErrorMapper.
+func (r ErrorRule) MapError(_ *http.Request, err error) (Problem, bool) {
+	if r.Match == nil || !r.Match(err) {
+		return Problem{}, false
+	}
+	return Problem{Status: r.Status, Code: r.Code, Detail: r.Message}, true
+}
+
+// ErrorIs returns a rule matching errors for which errors.Is(err, target) is true.
+func ErrorIs(target error, status int, code, message string) ErrorRule {
+	return ErrorRule{
+		Match:   func(err error) bool { return errors.Is(err, target) },
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// ErrorAs returns a rule matching errors for which errors.As finds a T.
+func ErrorAs[T error](status int, code, message string) ErrorRule {
+	return ErrorRule{
+		Match: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// ErrorMappers is an ordered registry of mappers. The first mapper that handles
+// an error wins.
+type ErrorMappers []ErrorMapper
+
+// MapError implements ErrorMapper.
+func (m ErrorMappers) MapError(r *http.Request, err error) (Problem, bool) {
+	for _, mapper := range m {
+		if mapper == nil {
+			continue
+		}
+		if problem, ok := mapper.MapError(r, err); ok {
+			return problem, true
+		}
+	}
+	return Problem{}, false
+}
+
+// errorMapperTokenPrefix marks provider tokens that contribute error mappers.
+const errorMapperTokenPrefix = "http.error_mapper."
+
+// ErrorMapperToken returns the token used by ErrorMapperProvider for name.
+func ErrorMapperToken(name string) module.Token {
+	return module.Token(errorMapperTokenPrefix + name)
+}
+
+// ErrorMapperProvider returns a provider that contributes mappers to the
+// application's error mapping registry. The token does not need to be exported;
+// ErrorMappersFromApp resolves it from the module that declares it.
+func ErrorMapperProvider(name string, mappers ...ErrorMapper) module.ProviderDef {
+	registry := ErrorMappers(append([]ErrorMapper(nil), mappers...))
+	return module.ProviderDef{
+		Token: ErrorMapperToken(name),
+		Build: func(module.Resolver) (any, error) {
+			return registry, nil
+		},
+	}
+}
+
+// ErrorMappersFromApp resolves every error mapper provider in the graph, in module
+// graph order (imports before importers), and returns them as one registry. Each
+// mapper is resolved from its own module, so it need not be exported.
+func ErrorMappersFromApp(app *kernel.App) (ErrorMappers, error) {
+	if app == nil || app.Graph == nil {
+		return nil, kernel.ErrNilApp
+	}
+
+	var mappers ErrorMappers
+	for _, node := range app.Graph.Modules {
+		var resolver module.Resolver
+		for _, provider := range node.Def.Providers {
+			if !strings.HasPrefix(string(provider.Token), errorMapperTokenPrefix) {
+				continue
+			}
+			if resolver == nil {
+				r, err := app.ModuleResolver(node.Name)
+				if err != nil {
+					return nil, err
+				}
+				resolver = r
+			}
+			value, err := resolver.Get(provider.Token)
+			if err != nil {
+				return nil, err
+			}
+			mapper, ok := value.(ErrorMapper)
+			if !ok {
+				return nil, &ErrorMapperTypeError{Token: provider.Token, Type: fmt.Sprintf("%T", value)}
+			}
+			mappers = append(mappers, mapper)
+		}
+	}
+	return mappers, nil
+}
+
+type errorMapperKey struct{}
+
+func errorMapperFromContext(ctx context.Context) ErrorMapper {
+	mapper, _ := ctx.Value(errorMapperKey{}).(ErrorMapper)
+	return mapper
+}
+
+// MapErrors returns middleware that makes mapper available to typed handlers and
+// WriteError, and turns panics into problem responses through the same mapper.
+// Recovered panics are passed to the mapper as *PanicError.
+func MapErrors(mapper ErrorMapper) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(context.WithValue(r.Context(), errorMapperKey{}, mapper))
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler { //nolint:errorlint // Sentinel comparison matches net/http.
+					panic(rec)
+				}
+				middleware.PrintPrettyStack(rec)
+				WriteError(w, r, &PanicError{Value: rec, Stack: debug.Stack()})
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteError maps err through the request's error mapper (see MapErrors), falling
+// back to DefaultErrorMapper, and writes the resulting problem.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	WriteProblem(w, r, mapError(nil, r, err))
+}
@@ -0,0 +1,219 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+type quotaError struct{ Limit int }
+
+func (e *quotaError) Error() string { return "quota exceeded" }
+
+type errorMappingModule struct {
+	name    string
+	imports []module.Module
+	defs    []module.ProviderDef
+	exports []module.Token
+}
+
+func (m *errorMappingModule) Definition() module.ModuleDef {
+	return module.ModuleDef{Name: m.name, Imports: m.imports, Providers: m.defs, Exports: m.exports}
+}
+
+func TestErrorRules(t *testing.T) {
+	mappers := ErrorMappers{
+		ErrorIs(errOrderNotFound, http.StatusNotFound, "ORDER_NOT_FOUND", "order not found"),
+		ErrorAs[*quotaError](http.StatusTooManyRequests, "QUOTA", "quota exceeded"),
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+
+	problem, ok := mappers.MapError(req, errors.Join(errors.New("ctx"), errOrderNotFound))
+	if !ok || problem.Status != http.StatusNotFound || problem.Code != "ORDER_NOT_FOUND" {
+		t.Fatalf("unexpected Is mapping: %+v %v", problem, ok)
+	}
+
+	problem, ok = mappers.MapError(req, &quotaError{Limit: 3})
+	if !ok || problem.Status != http.StatusTooManyRequests || problem.Code != "QUOTA" {
+		t.Fatalf("unexpected As mapping: %+v %v", problem, ok)
+	}
+
+	if _, ok := mappers.MapError(req, errors.New("other")); ok {
+		t.Fatal("expected unmatched error to fall through")
+	}
+}
+
+func TestMapErrors_AppliesToTypedHandlersAndPanics(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	mappers := ErrorMappers{
+		ErrorIs(errOrderNotFound, http.StatusNotFound, "ORDER_NOT_FOUND", "order not found"),
+		ErrorAs[*PanicError](http.StatusServiceUnavailable, "PANIC", "try again"),
+	}
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(MapErrors(mappers))
+	router.Method(http.MethodGet, "/orders", Handle(func(context.Context, struct{}) (item, error) {
+		return item{}, errOrderNotFound
+	}))
+	router.Method(http.MethodGet, "/panic", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	router.Method(http.MethodGet, "/plain", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, r, errOrderNotFound)
+	}))
+
+	for _, tt := range []struct {
+		path   string
+		status int
+		code   string
+	}{
+		{path: "/orders", status: http.StatusNotFound, code: "ORDER_NOT_FOUND"},
+		{path: "/panic", status: http.StatusServiceUnavailable, code: "PANIC"},
+		{path: "/plain", status: http.StatusNotFound, code: "ORDER_NOT_FOUND"},
+	} {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, http.NoBody))
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			problem := decodeProblem(t, rec)
+			if problem.Code != tt.code || problem.RequestID == "" || problem.Instance != tt.path {
+				t.Fatalf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
+
+func TestMapErrors_HandlerOptionTakesPrecedence(t *testing.T) {
+	local := ErrorIs(errOrderNotFound, http.StatusGone, "GONE", "gone")
+	global := ErrorIs(errOrderNotFound, http.StatusNotFound, "ORDER_NOT_FOUND", "order not found")
+
+	router := chi.NewRouter()
+	router.Use(MapErrors(global))
+	router.Method(http.MethodGet, "/orders", Handle(func(context.Context, struct{}) (item, error) {
+		return item{}, errOrderNotFound
+	}, WithErrorMapper(local)))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/orders", http.NoBody))
+
+	if rec.Code != http.StatusGone {
+		t.Fatalf("expected 410, got %d", rec.Code)
+	}
+}
+
+func TestMapErrors_UnmappedPanicIs500(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	handler := MapErrors(nil)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(errors.New("secret"))
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", rec.Code)
+	}
+	if problem := decodeProblem(t, rec); problem.Detail != "internal error" {
+		t.Fatalf("expected generic detail, got %q", problem.Detail)
+	}
+}
+
+func TestErrorMappersFromApp(t *testing.T) {
+	orders := &errorMappingModule{
+		name:    "orders",
+		defs:    []module.ProviderDef{ErrorMapperProvider("orders", ErrorIs(errOrderNotFound, http.StatusNotFound, "ORDER_NOT_FOUND", ""))},
+		exports: []module.Token{ErrorMapperToken("orders")},
+	}
+	root := &errorMappingModule{
+		name:    "app",
+		imports: []module.Module{orders},
+		defs:    []module.ProviderDef{ErrorMapperProvider("app", ErrorAs[*quotaError](http.StatusTooManyRequests, "QUOTA", ""))},
+	}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	mappers, err := ErrorMappersFromApp(app)
+	if err != nil {
+		t.Fatalf("ErrorMappersFromApp failed: %v", err)
+	}
+	if len(mappers) != 2 {
+		t.Fatalf("expected 2 mappers, got %d", len(mappers))
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	if problem, ok := mappers.MapError(req, errOrderNotFound); !ok || problem.Code != "ORDER_NOT_FOUND" {
+		t.Fatalf("expected orders mapper, got %+v", problem)
+	}
+	if problem, ok := mappers.MapError(req, &quotaError{}); !ok || problem.Code != "QUOTA" {
+		t.Fatalf("expected app mapper, got %+v", problem)
+	}
+}
+
+func TestErrorMappersFromApp_NestedUnexported(t *testing.T) {
+	orders := &errorMappingModule{
+		name: "orders",
+		defs: []module.ProviderDef{ErrorMapperProvider("orders", ErrorIs(errOrderNotFound, http.StatusNotFound, "ORDER_NOT_FOUND", ""))},
+	}
+	shop := &errorMappingModule{name: "shop", imports: []module.Module{orders}}
+	root := &errorMappingModule{name: "app", imports: []module.Module{shop}}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	mappers, err := ErrorMappersFromApp(app)
+	if err != nil {
+		t.Fatalf("ErrorMappersFromApp failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	if problem, ok := mappers.MapError(req, errOrderNotFound); !ok || problem.Code != "ORDER_NOT_FOUND" {
+		t.Fatalf("expected nested orders mapper, got %+v", problem)
+	}
+}
+
+func TestErrorMappersFromApp_WrongType(t *testing.T) {
+	root := &errorMappingModule{
+		name: "app",
+		defs: []module.ProviderDef{{
+			Token: ErrorMapperToken("bad"),
+			Build: func(module.Resolver) (any, error) { return "nope", nil },
+		}},
+	}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	_, err = ErrorMappersFromApp(app)
+
+	var typeErr *ErrorMapperTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("expected ErrorMapperTypeError, got %v", err)
+	}
+}
+
+func TestErrorMappersFromApp_NilApp(t *testing.T) {
+	if _, err := ErrorMappersFromApp(nil); !errors.Is(err, kernel.ErrNilApp) {
+		t.Fatalf("expected ErrNilApp, got %v", err)
+	}
+}
@@ -1,6 +1,11 @@
 package http
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
 
 // RouteRegistrationError indicates a controller does not expose route registration.
 type RouteRegistrationError struct {
@@ -10,3 +15,156 @@ type RouteRegistrationError struct {
 func (e *RouteRegistrationError) Error() string {
 	return fmt.Sprintf("controller does not implement RouteRegistrar: %s", e.Name)
 }
+
+// ServerConfigError indicates an invalid Server or ServeOptions configuration.
+type ServerConfigError struct {
+	Name   string
+	Reason string
+}
+
+func (e *ServerConfigError) Error() string {
+	return fmt.Sprintf("invalid server config: name=%q: %s", e.Name, e.Reason)
+}
+
+// ServerError wraps a listen, serve, or shutdown failure of a Server.
+type ServerError struct {
+	Name string
+	Addr string
+	Err  error
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server failed: name=%q addr=%q: %v", e.Name, e.Addr, e.Err)
+}
+
+func (e *ServerError) Unwrap() error {
+	return e.Err
+}
+
+// RouteConflictError indicates two controllers registered the same method and pattern.
+type RouteConflictError struct {
+	Method      string
+	Pattern     string
+	Controllers []string
+}
+
+func (e *RouteConflictError) Error() string {
+	return fmt.Sprintf("route conflict: %s %s registered by controllers %q and %q",
+		e.Method, e.Pattern, e.Controllers[0], e.Controllers[1])
+}
+
+// BindError is returned when request parameters cannot be bound to the request type.
+type BindError struct {
+	Params []InvalidParam
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	names := make([]string, 0, len(e.Params))
+	for _, param := range e.Params {
+		names = append(names, param.Name)
+	}
+	return fmt.Sprintf("bind request: invalid params %v: %v", names, e.Err)
+}
+
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidParams returns the parameters that failed to bind.
+func (e *BindError) InvalidParams() []InvalidParam {
+	return e.Params
+}
+
+// ValidationError wraps an error returned by a request's Validate method.
+type ValidationError struct {
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validate request: %v", e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidParams returns the invalid parameters reported by the wrapped error, if any.
+func (e *ValidationError) InvalidParams() []InvalidParam {
+	var paramsErr InvalidParamsError
+	if errors.As(e.Err, &paramsErr) {
+		return paramsErr.InvalidParams()
+	}
+	return nil
+}
+
+// UnsupportedBindTypeError is returned when a tagged request field has a type Bind cannot set.
+type UnsupportedBindTypeError struct {
+	Type string
+}
+
+func (e *UnsupportedBindTypeError) Error() string {
+	return fmt.Sprintf("unsupported bind field type: %s", e.Type)
+}
+
+// PanicError carries a panic recovered by MapErrors to the error mapper.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value when it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// ErrorMapperTypeError is returned when an error mapper provider does not build an ErrorMapper.
+type ErrorMapperTypeError struct {
+	Token module.Token
+	Type  string
+}
+
+func (e *ErrorMapperTypeError) Error() string {
+	return fmt.Sprintf("error mapper provider has wrong type: token=%q type=%s", e.Token, e.Type)
+}
+
+// ModuleMountConflictError indicates a module with controllers is imported under
+// different HTTP mounts, so its prefix and middleware are ambiguous.
+type ModuleMountConflictError struct {
+	Module string
+	// Mounts lists the conflicting chains of modules declaring ModuleDef.HTTP.
+	Mounts [][]string
+}
+
+func (e *ModuleMountConflictError) Error() string {
+	return fmt.Sprintf("module %q is mounted under conflicting HTTP modules %q and %q",
+		e.Module, e.Mounts[0], e.Mounts[1])
+}
+
+// ModuleMiddlewareTypeError indicates a token in ModuleDef.HTTP.Middleware did
+// not resolve to func(http.Handler) http.Handler.
+type ModuleMiddlewareTypeError struct {
+	Module string
+	Token  module.Token
+	Type   string
+}
+
+func (e *ModuleMiddlewareTypeError) Error() string {
+	return fmt.Sprintf("module %q middleware %q: expected func(http.Handler) http.Handler, got %s",
+		e.Module, e.Token, e.Type)
+}
+
+// IdempotencyStoreConfigError indicates an invalid SQLIdempotencyStore
+// configuration.
+type IdempotencyStoreConfigError struct {
+	Reason string
+}
+
+func (e *IdempotencyStoreConfigError) Error() string {
+	return "invalid idempotency store config: " + e.Reason
+}
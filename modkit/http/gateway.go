@@ -0,0 +1,221 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultWebSocketMaxMessageSize is the message size limit used when
+	// WebSocketOptions.MaxMessageSize is zero.
+	DefaultWebSocketMaxMessageSize = 1 << 20
+	// DefaultWebSocketPingInterval is the ping interval used when
+	// WebSocketOptions.PingInterval is zero.
+	DefaultWebSocketPingInterval = 30 * time.Second
+	// DefaultWebSocketWriteTimeout is the write timeout used when
+	// WebSocketOptions.WriteTimeout is zero.
+	DefaultWebSocketWriteTimeout = 10 * time.Second
+
+	// ErrorMessageType is the type of messages carrying a Problem for a failed
+	// or unknown message.
+	ErrorMessageType = "error"
+)
+
+// WebSocketOptions configures a WebSocket gateway.
+type WebSocketOptions struct {
+	// CheckOrigin rejects upgrades with 403 when it returns false (default
+	// SameOrigin).
+	CheckOrigin func(r *http.Request) bool
+	// MaxMessageSize closes connections sending larger messages with
+	// CloseMessageTooBig (default DefaultWebSocketMaxMessageSize).
+	MaxMessageSize int64
+	// PingInterval is how often the server pings idle clients (default
+	// DefaultWebSocketPingInterval; negative disables).
+	PingInterval time.Duration
+	// WriteTimeout bounds each frame write (default
+	// DefaultWebSocketWriteTimeout; negative disables).
+	WriteTimeout time.Duration
+}
+
+func (o WebSocketOptions) withDefaults() WebSocketOptions {
+	if o.CheckOrigin == nil {
+		o.CheckOrigin = SameOrigin
+	}
+	if o.MaxMessageSize <= 0 {
+		o.MaxMessageSize = DefaultWebSocketMaxMessageSize
+	}
+	if o.PingInterval == 0 {
+		o.PingInterval = DefaultWebSocketPingInterval
+	}
+	if o.WriteTimeout == 0 {
+		o.WriteTimeout = DefaultWebSocketWriteTimeout
+	}
+	return o
+}
+
+// WebSocketMessage is the JSON envelope of gateway messages in both directions.
+type WebSocketMessage struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// MessageHandler handles one message type. A non-nil result is sent back with
+// the same type; an error is mapped like WriteError and sent as an
+// ErrorMessageType message carrying the Problem.
+type MessageHandler func(conn *WebSocketConn, data json.RawMessage) (any, error)
+
+// WebSocketGateway handles the messages of WebSocket connections, routed by
+// their envelope type. Messages of one connection are handled in order.
+type WebSocketGateway interface {
+	Messages() map[string]MessageHandler
+}
+
+// ConnectHook is implemented by gateways that run code when a connection opens.
+// Returning an error closes the connection with ClosePolicyViolation.
+type ConnectHook interface {
+	OnConnect(conn *WebSocketConn) error
+}
+
+// DisconnectHook is implemented by gateways that run code when a connection
+// closes. err is nil for normal closures.
+type DisconnectHook interface {
+	OnDisconnect(conn *WebSocketConn, err error)
+}
+
+// Send writes a message with the given type and data encoded as JSON.
+func (c *WebSocketConn) Send(messageType string, data any) error {
+	message := WebSocketMessage{Type: messageType}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		message.Data = raw
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.WriteText(payload)
+}
+
+// WebSocket returns a handler that upgrades requests and serves gateway on the
+// connection. Register it with Router.Handle like any other handler; guards and
+// middleware run before the upgrade. Handshake failures are written with
+// WriteError. Connections are closed with CloseGoingAway when the server drains
+// (see Draining).
+func WebSocket(gateway WebSocketGateway, opts WebSocketOptions) http.Handler {
+	opts = opts.withDefaults()
+	if opts.WriteTimeout < 0 {
+		opts.WriteTimeout = 0
+	}
+	handlers := gateway.Messages()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgradeWebSocket(w, r, opts)
+		if err != nil {
+			var problem *Problem
+			if errors.As(err, &problem) {
+				WriteError(w, r, err)
+			}
+			return
+		}
+		serveWebSocket(conn, gateway, handlers)
+	})
+}
+
+func serveWebSocket(conn *WebSocketConn, gateway WebSocketGateway, handlers map[string]MessageHandler) {
+	if hook, ok := gateway.(ConnectHook); ok {
+		if err := hook.OnConnect(conn); err != nil {
+			_ = conn.Close(ClosePolicyViolation, "connection rejected")
+			return
+		}
+	}
+
+	go conn.watch()
+
+	var err error
+	for {
+		var opcode byte
+		var payload []byte
+		opcode, payload, err = conn.readMessage()
+		if err != nil {
+			break
+		}
+		if opcode != wsOpText {
+			err = conn.fail(CloseUnsupportedData, "binary messages are not supported")
+			break
+		}
+		conn.dispatch(handlers, payload)
+	}
+	_ = conn.Close(CloseNormal, "")
+
+	if hook, ok := gateway.(DisconnectHook); ok {
+		var closeErr *WebSocketCloseError
+		if errors.As(err, &closeErr) && (closeErr.Code == CloseNormal || closeErr.Code == CloseGoingAway) {
+			err = nil
+		}
+		hook.OnDisconnect(conn, err)
+	}
+}
+
+// dispatch routes one text message to its handler and writes the reply.
+func (c *WebSocketConn) dispatch(handlers map[string]MessageHandler, payload []byte) {
+	var message WebSocketMessage
+	if err := json.Unmarshal(payload, &message); err != nil || message.Type == "" {
+		c.sendError(NewProblem(http.StatusBadRequest, "invalid message"))
+		return
+	}
+	handler, ok := handlers[message.Type]
+	if !ok {
+		c.sendError(&Problem{Status: http.StatusNotFound, Detail: errUnknownMessageType.Error() + ": " + message.Type})
+		return
+	}
+	reply, err := handler(c, message.Data)
+	if err != nil {
+		c.sendError(err)
+		return
+	}
+	if reply != nil {
+		if err := c.Send(message.Type, reply); err != nil {
+			_ = c.Close(CloseInternalError, "encoding reply failed")
+		}
+	}
+}
+
+func (c *WebSocketConn) sendError(err error) {
+	problem := mapError(nil, c.request, err)
+	if problem.Status == 0 {
+		problem.Status = http.StatusInternalServerError
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	_ = c.Send(ErrorMessageType, problem)
+}
+
+// watch pings the client and closes the connection when the server drains.
+func (c *WebSocketConn) watch() {
+	var tick <-chan time.Time
+	if c.opts.PingInterval > 0 {
+		ticker := time.NewTicker(c.opts.PingInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-Draining(c.request.Context()):
+			_ = c.Close(CloseGoingAway, "server shutting down")
+			return
+		case <-tick:
+			if err := c.writeFrame(wsOpPing, nil); err != nil {
+				_ = c.Close(CloseGoingAway, "")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"slices"
+)
+
+// RouteMeta is metadata attached to a route with Route. Guards receive it with
+// every request, and it is recorded in the route table.
+type RouteMeta struct {
+	// Public marks a route that does not require authentication.
+	Public bool `json:"public,omitempty"`
+	// Roles lists roles of which the principal needs at least one.
+	Roles []string `json:"roles,omitempty"`
+	// Summary is a short description used by generated API documents.
+	Summary string `json:"summary,omitempty"`
+	// Hidden excludes the route from generated API documents.
+	Hidden bool `json:"hidden,omitempty"`
+	// Versions lists the API versions serving the route; see Versioning.
+	Versions []string `json:"versions,omitempty"`
+	// Deprecation adds deprecation headers to the route's responses.
+	Deprecation *Deprecation `json:"deprecation,omitempty"`
+	// Cache is the caching policy of the route; see CacheControl.
+	Cache *CachePolicy `json:"cache,omitempty"`
+	// Values holds application-specific metadata.
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// Guard decides whether a request may reach a route. It returns the request to
+// continue with (for example with a principal added to its context) or an error
+// that is written through the request's error mapper; a *Problem controls the
+// response directly.
+type Guard interface {
+	Allow(r *http.Request, meta RouteMeta) (*http.Request, error)
+}
+
+// SecurityScheme describes how a guard authenticates requests, in the shape of
+// an OpenAPI security scheme object.
+type SecurityScheme struct {
+	// Key names the scheme in documents, for example "bearerAuth".
+	Key          string `json:"-"`
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// SecuritySchemer is implemented by guards that authenticate requests. Routes
+// they protect list the scheme in RouteInfo.Security.
+type SecuritySchemer interface {
+	SecurityScheme() SecurityScheme
+}
+
+// GuardFunc adapts a function to Guard.
+type GuardFunc func(r *http.Request, meta RouteMeta) (*http.Request, error)
+
+// Allow calls f(r, meta).
+func (f GuardFunc) Allow(r *http.Request, meta RouteMeta) (*http.Request, error) {
+	return f(r, meta)
+}
+
+// GuardedController is implemented by controllers whose guards apply to every
+// route they register through RegisterRoutes.
+type GuardedController interface {
+	Guards() []Guard
+}
+
+// GuardRouter is implemented by routers that support guards (see AsRouter).
+type GuardRouter interface {
+	UseGuards(guards ...Guard)
+}
+
+// UseGuards adds guards to router. Guards added to the root router apply to every
+// route registered afterwards; guards added inside Group apply to that group. It
+// reports false when router does not support guards.
+func UseGuards(router Router, guards ...Guard) bool {
+	guardRouter, ok := router.(GuardRouter)
+	if !ok {
+		return false
+	}
+	guardRouter.UseGuards(guards...)
+	return true
+}
+
+// RouteOption configures Route.
+type RouteOption func(*routeHandler)
+
+// Public marks the route as public.
+func Public() RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Public = true
+	}
+}
+
+// Roles requires the principal to have at least one of roles.
+func Roles(roles ...string) RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Roles = append(h.meta.Roles, roles...)
+	}
+}
+
+// Meta sets an application-specific metadata value.
+func Meta(key string, value any) RouteOption {
+	return func(h *routeHandler) {
+		if h.meta.Values == nil {
+			h.meta.Values = make(map[string]any)
+		}
+		h.meta.Values[key] = value
+	}
+}
+
+// Summary sets a short description used by generated API documents.
+func Summary(summary string) RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Summary = summary
+	}
+}
+
+// Hidden excludes the route from generated API documents.
+func Hidden() RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Hidden = true
+	}
+}
+
+// WithGuards adds guards that apply to this route only.
+func WithGuards(guards ...Guard) RouteOption {
+	return func(h *routeHandler) {
+		h.guards = append(h.guards, guards...)
+	}
+}
+
+// Route attaches metadata and route-level guards to handler. Register the result
+// with Router.Handle; the adapter returned by AsRouter combines them with global,
+// controller, and group guards.
+func Route(handler http.Handler, opts ...RouteOption) http.Handler {
+	route := &routeHandler{next: handler}
+	if inner, ok := handler.(*routeHandler); ok {
+		route.next = inner.next
+		route.meta = inner.meta
+		route.guards = slices.Clone(inner.guards)
+	}
+	for _, opt := range opts {
+		opt(route)
+	}
+	return route
+}
+
+type routeHandler struct {
+	next   http.Handler
+	meta   RouteMeta
+	guards []Guard
+}
+
+// ServeHTTP runs the route-level guards so Route also works on routers that do
+// not support guards.
+func (h *routeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	guarded(withCachePolicy(h.next, h.meta), h.meta, h.guards).ServeHTTP(w, r)
+}
+
+func unwrapRoute(handler http.Handler) (http.Handler, RouteMeta, []Guard, bool) {
+	if route, ok := handler.(*routeHandler); ok {
+		return route.next, route.meta, route.guards, true
+	}
+	return handler, RouteMeta{}, nil, false
+}
+
+func securitySchemes(guards []Guard) []SecurityScheme {
+	var schemes []SecurityScheme
+	for _, guard := range guards {
+		schemer, ok := guard.(SecuritySchemer)
+		if !ok {
+			continue
+		}
+		scheme := schemer.SecurityScheme()
+		if !slices.ContainsFunc(schemes, func(s SecurityScheme) bool { return s.Key == scheme.Key }) {
+			schemes = append(schemes, scheme)
+		}
+	}
+	return schemes
+}
+
+type routeMetaKey struct{}
+
+// RouteMetaFromContext returns the metadata of the route serving the request.
+func RouteMetaFromContext(ctx context.Context) (RouteMeta, bool) {
+	meta, ok := ctx.Value(routeMetaKey{}).(RouteMeta)
+	return meta, ok
+}
+
+func guarded(next http.Handler, meta RouteMeta, guards []Guard) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(context.WithValue(r.Context(), routeMetaKey{}, meta))
+		for _, guard := range guards {
+			allowed, err := guard.Allow(r, meta)
+			if err != nil {
+				WriteError(w, r, err)
+				return
+			}
+			if allowed != nil {
+				r = allowed
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	Subject string
+	Roles   []string
+	Claims  map[string]any
+}
+
+// HasRole reports whether the principal has role.
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored by an authentication guard.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
+
+// RequireRoles returns a guard enforcing RouteMeta.Roles: routes without roles
+// are allowed, otherwise the principal must hold one of them (401 without a
+// principal, 403 without a matching role). Public routes are always allowed.
+func RequireRoles() Guard {
+	return GuardFunc(func(r *http.Request, meta RouteMeta) (*http.Request, error) {
+		if meta.Public || len(meta.Roles) == 0 {
+			return r, nil
+		}
+		principal, ok := PrincipalFromContext(r.Context())
+		if !ok {
+			return nil, NewProblem(http.StatusUnauthorized, "authentication required")
+		}
+		for _, role := range meta.Roles {
+			if principal.HasRole(role) {
+				return r, nil
+			}
+		}
+		return nil, NewProblem(http.StatusForbidden, "insufficient role")
+	})
+}
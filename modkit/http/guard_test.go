@@ -0,0 +1,258 @@
+package http
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var testJWTSecret = []byte("test-secret")
+
+func signJWT(t *testing.T, alg string, key any, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var sig []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(input))
+		var err error
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub":   "user-1",
+		"iss":   "modkit",
+		"aud":   []string{"api"},
+		"roles": []string{"reader"},
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func recordGuard(name string, calls *[]string) Guard {
+	return GuardFunc(func(r *http.Request, _ RouteMeta) (*http.Request, error) {
+		*calls = append(*calls, name)
+		return r, nil
+	})
+}
+
+type guardedController struct {
+	calls *[]string
+}
+
+func (c *guardedController) Guards() []Guard {
+	return []Guard{recordGuard("controller", c.calls)}
+}
+
+func (c *guardedController) RegisterRoutes(router Router) {
+	router.Group("/admin", func(r Router) {
+		UseGuards(r, recordGuard("group", c.calls))
+		r.Handle(http.MethodGet, "/stats", Route(noContent(), Roles("admin"), WithGuards(recordGuard("route", c.calls))))
+	})
+}
+
+func TestGuards_RunGlobalControllerGroupRouteInOrder(t *testing.T) {
+	var calls []string
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		calls = nil
+		if !UseGuards(router, recordGuard("global", &calls)) {
+			t.Fatal("expected adapter to support guards")
+		}
+
+		if err := RegisterRoutes(router, map[string]any{"admin:Admin": &guardedController{calls: &calls}}); err != nil {
+			t.Fatalf("RegisterRoutes failed: %v", err)
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/stats", http.NoBody))
+
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d", rec.Code)
+		}
+		if got := strings.Join(calls, ","); got != "global,controller,group,route" {
+			t.Fatalf("unexpected guard order %q", got)
+		}
+
+		routes := Routes(router)
+		if len(routes) != 1 || routes[0].Guards != 4 || len(routes[0].Meta.Roles) != 1 || routes[0].Meta.Roles[0] != "admin" {
+			t.Fatalf("unexpected route info: %+v", routes)
+		}
+	})
+}
+
+func TestGuards_DenyWritesProblem(t *testing.T) {
+	deny := GuardFunc(func(*http.Request, RouteMeta) (*http.Request, error) {
+		return nil, NewProblem(http.StatusForbidden, "nope")
+	})
+	called := false
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		router.Handle(http.MethodGet, "/secret", Route(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			called = true
+		}), WithGuards(deny)))
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secret", http.NoBody))
+
+		if rec.Code != http.StatusForbidden || called {
+			t.Fatalf("expected 403 without calling handler, got %d (called=%v)", rec.Code, called)
+		}
+		if problem := decodeProblem(t, rec); problem.Detail != "nope" {
+			t.Fatalf("unexpected problem %+v", problem)
+		}
+	})
+}
+
+func TestRoute_WorksWithoutAdapter(t *testing.T) {
+	var meta RouteMeta
+	mux := chi.NewRouter()
+	mux.Method(http.MethodGet, "/", Route(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		meta, _ = RouteMetaFromContext(r.Context())
+	}), Public(), Meta("rate", 10)))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if !meta.Public || meta.Values["rate"] != 10 {
+		t.Fatalf("unexpected meta %+v", meta)
+	}
+}
+
+func serveJWT(t *testing.T, guard Guard, opts []RouteOption, authorization string) (*httptest.ResponseRecorder, Principal, bool) {
+	t.Helper()
+	mux := chi.NewRouter()
+	router := AsRouter(mux)
+	UseGuards(router, guard, RequireRoles())
+
+	var (
+		principal Principal
+		found     bool
+	)
+	router.Handle(http.MethodGet, "/me", Route(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, found = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}), opts...))
+
+	req := httptest.NewRequest(http.MethodGet, "/me", http.NoBody)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec, principal, found
+}
+
+func TestBearerJWT(t *testing.T) {
+	guard := BearerJWT(JWTConfig{Key: testJWTSecret, Issuer: "modkit", Audience: "api"})
+
+	expired := validClaims()
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	wrongIssuer := validClaims()
+	wrongIssuer["iss"] = "other"
+	noExp := validClaims()
+	delete(noExp, "exp")
+
+	tests := []struct {
+		name   string
+		auth   string
+		opts   []RouteOption
+		status int
+	}{
+		{name: "valid", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, validClaims()), status: http.StatusOK},
+		{name: "missing", status: http.StatusUnauthorized},
+		{name: "expired", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, expired), status: http.StatusUnauthorized},
+		{name: "no exp", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, noExp), status: http.StatusUnauthorized},
+		{name: "wrong issuer", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, wrongIssuer), status: http.StatusUnauthorized},
+		{name: "wrong secret", auth: "Bearer " + signJWT(t, "HS256", []byte("other"), validClaims()), status: http.StatusUnauthorized},
+		{name: "alg none", auth: "Bearer " + strings.TrimSuffix(signJWT(t, "none", []byte{}, validClaims()), "."), status: http.StatusUnauthorized},
+		{name: "public without token", opts: []RouteOption{Public()}, status: http.StatusOK},
+		{name: "role allowed", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, validClaims()), opts: []RouteOption{Roles("reader")}, status: http.StatusOK},
+		{name: "role denied", auth: "Bearer " + signJWT(t, "HS256", testJWTSecret, validClaims()), opts: []RouteOption{Roles("admin")}, status: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, _, _ := serveJWT(t, guard, tt.opts, tt.auth)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Fatalf("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestBearerJWT_SetsPrincipal(t *testing.T) {
+	guard := BearerJWT(JWTConfig{Key: testJWTSecret})
+
+	_, principal, found := serveJWT(t, guard, nil, "Bearer "+signJWT(t, "HS256", testJWTSecret, validClaims()))
+
+	if !found || principal.Subject != "user-1" || !principal.HasRole("reader") || principal.Claims["iss"] != "modkit" {
+		t.Fatalf("unexpected principal %+v (found=%v)", principal, found)
+	}
+}
+
+func TestBearerJWT_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	guard := BearerJWT(JWTConfig{Key: &key.PublicKey})
+
+	rec, principal, _ := serveJWT(t, guard, nil, "Bearer "+signJWT(t, "RS256", key, validClaims()))
+	if rec.Code != http.StatusOK || principal.Subject != "user-1" {
+		t.Fatalf("expected RS256 token to verify, got %d", rec.Code)
+	}
+
+	rec, _, _ = serveJWT(t, guard, nil, "Bearer "+signJWT(t, "HS256", testJWTSecret, validClaims()))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected HS256 token to be rejected by RS256 guard, got %d", rec.Code)
+	}
+}
+
+func TestBearerJWT_InvalidTokenProblemWrapsErrInvalidToken(t *testing.T) {
+	guard := BearerJWT(JWTConfig{Key: testJWTSecret})
+	allow := func(authorization string) error {
+		req := httptest.NewRequest(http.MethodGet, "/me", http.NoBody)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		_, err := guard.Allow(req, RouteMeta{})
+		return err
+	}
+
+	err := allow("Bearer " + signJWT(t, "HS256", []byte("other"), validClaims()))
+	var problem *Problem
+	if !errors.As(err, &problem) || problem.Status != http.StatusUnauthorized {
+		t.Fatalf("expected 401 problem, got %v", err)
+	}
+	if !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected problem to wrap ErrInvalidToken, got %v", err)
+	}
+
+	if err := allow(""); err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected missing token not to match ErrInvalidToken, got %v", err)
+	}
+}
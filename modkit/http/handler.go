@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// HandlerFunc is a typed handler used with Handle.
+type HandlerFunc[Req, Resp any] func(ctx context.Context, req Req) (Resp, error)
+
+// NoContent is a response type that writes 204 No Content.
+type NoContent struct{}
+
+// StatusCoder lets a response value choose its success status code.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+type handlerConfig struct {
+	status       int
+	mapper       ErrorMapper
+	allowUnknown bool
+	negotiator   *Negotiator
+}
+
+// HandlerOption configures Handle.
+type HandlerOption func(*handlerConfig)
+
+// WithStatus sets the success status code (default 200).
+func WithStatus(status int) HandlerOption {
+	return func(c *handlerConfig) {
+		c.status = status
+	}
+}
+
+// WithErrorMapper sets the mapper used for errors returned by the handler.
+// Errors it does not handle fall back to DefaultErrorMapper.
+func WithErrorMapper(mapper ErrorMapper) HandlerOption {
+	return func(c *handlerConfig) {
+		c.mapper = mapper
+	}
+}
+
+// WithUnknownFields accepts JSON bodies with fields not present in Req.
+func WithUnknownFields() HandlerOption {
+	return func(c *handlerConfig) {
+		c.allowUnknown = true
+	}
+}
+
+// WithEncoders sets the encoders the response is negotiated among, overriding
+// ContentNegotiation for this handler.
+func WithEncoders(encoders ...Encoder) HandlerOption {
+	return func(c *handlerConfig) {
+		c.negotiator = NewNegotiator(encoders...)
+	}
+}
+
+// HandlerTypes describes the request and response of a handler built with
+// Handle. The route table records it so documents such as OpenAPI can be
+// generated from registered routes.
+type HandlerTypes struct {
+	// Request and Response are the Req and Resp type arguments of Handle.
+	Request  reflect.Type
+	Response reflect.Type
+	// Status is the success status code.
+	Status int
+	// Params lists the fields bound from path, query, and header tags.
+	Params []ParamInfo
+	// MediaTypes lists the response media types set with WithEncoders; empty
+	// means the request's negotiator decides, JSON by default.
+	MediaTypes []string
+}
+
+// ParamInfo describes a request field bound from the path, query, or headers.
+type ParamInfo struct {
+	Name string
+	// In is "path", "query", or "header".
+	In    string
+	Type  reflect.Type
+	Field reflect.StructField
+}
+
+// TypedHandler is implemented by handlers that expose their HandlerTypes.
+type TypedHandler interface {
+	http.Handler
+	HandlerTypes() HandlerTypes
+}
+
+type typedHandler struct {
+	serve http.HandlerFunc
+	types HandlerTypes
+}
+
+func (h *typedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.serve(w, r)
+}
+
+func (h *typedHandler) HandlerTypes() HandlerTypes {
+	return h.types
+}
+
+// Handle adapts a typed function to an http.Handler. The request is bound from
+// the JSON body and from path, query, and header tags (see Bind), validated
+// when Req implements Validate() error, and passed to fn. The response is
+// encoded by the encoder negotiated from the Accept header (see
+// ContentNegotiation and WithEncoders; JSON by default), and requests accepting
+// none of them get 406 before fn runs. Returned errors are written as
+// application/problem+json. The returned handler implements TypedHandler.
+func Handle[Req, Resp any](fn HandlerFunc[Req, Resp], opts ...HandlerOption) http.Handler {
+	cfg := handlerConfig{status: http.StatusOK}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	types := HandlerTypes{
+		Request:  reflect.TypeFor[Req](),
+		Response: reflect.TypeFor[Resp](),
+		Status:   cfg.status,
+		Params:   paramInfos(reflect.TypeFor[Req]()),
+	}
+	noContent := types.Response == reflect.TypeFor[NoContent]()
+	if noContent {
+		types.Status = http.StatusNoContent
+	}
+	if cfg.negotiator != nil {
+		for _, encoder := range cfg.negotiator.encoders {
+			types.MediaTypes = append(types.MediaTypes, encoder.MediaType())
+		}
+	}
+
+	return &typedHandler{types: types, serve: func(w http.ResponseWriter, r *http.Request) {
+		negotiator := cfg.negotiator
+		if negotiator == nil {
+			negotiator = NegotiatorFromContext(r.Context())
+		}
+		encoder, err := negotiator.Negotiate(r)
+		if err != nil && !noContent {
+			WriteProblem(w, r, mapError(cfg.mapper, r, err))
+			return
+		}
+
+		var req Req
+		if err := bind(r, &req, cfg.allowUnknown); err != nil {
+			WriteProblem(w, r, mapError(cfg.mapper, r, err))
+			return
+		}
+		if err := validate(&req); err != nil {
+			WriteProblem(w, r, mapError(cfg.mapper, r, err))
+			return
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteProblem(w, r, mapError(cfg.mapper, r, err))
+			return
+		}
+
+		writeResponse(w, r, cfg, negotiator, encoder, resp)
+	}}
+}
+
+type validator interface {
+	Validate() error
+}
+
+func validate(req any) error {
+	v, ok := req.(validator)
+	if !ok {
+		return nil
+	}
+	if err := v.Validate(); err != nil {
+		return &ValidationError{Err: err}
+	}
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, cfg handlerConfig, negotiator *Negotiator, encoder Encoder, resp any) {
+	if _, ok := resp.(NoContent); ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	status := cfg.status
+	if coder, ok := resp.(StatusCoder); ok {
+		status = coder.StatusCode()
+	}
+	negotiator.write(w, r, encoder, status, resp, cfg.mapper)
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type updateItemRequest struct {
+	ID      int64         `path:"id" json:"-"`
+	Verbose bool          `query:"verbose" json:"-"`
+	Tags    []string      `query:"tag" json:"-"`
+	Limit   *int          `query:"limit" json:"-"`
+	Wait    time.Duration `query:"wait" json:"-"`
+	Trace   string        `header:"X-Trace" json:"-"`
+	Name    string        `json:"name"`
+}
+
+func (r updateItemRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return paramsError{{Name: "name", Reason: "is required"}}
+	}
+	return nil
+}
+
+type paramsError []InvalidParam
+
+func (e paramsError) Error() string                 { return "invalid params" }
+func (e paramsError) InvalidParams() []InvalidParam { return e }
+
+type item struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+var errItemNotFound = errors.New("item not found")
+
+func serveTyped(t *testing.T, handler http.Handler, method, pattern, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Method(method, pattern, handler)
+
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, http.NoBody)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Fatalf("expected %s, got %q", ProblemContentType, ct)
+	}
+	var problem Problem
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return problem
+}
+
+func TestHandle_BindsBodyPathQueryAndHeader(t *testing.T) {
+	var got updateItemRequest
+	handler := Handle(func(_ context.Context, req updateItemRequest) (item, error) {
+		got = req
+		return item{ID: req.ID, Name: req.Name}, nil
+	})
+
+	rec := serveTyped(t, handler, http.MethodPut, "/items/{id}",
+		"/items/42?verbose=true&tag=a&tag=b&limit=5&wait=2s", `{"name":"widget"}`,
+		map[string]string{"X-Trace": "abc"})
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if got.ID != 42 || !got.Verbose || len(got.Tags) != 2 || got.Tags[1] != "b" ||
+		got.Limit == nil || *got.Limit != 5 || got.Wait != 2*time.Second || got.Trace != "abc" || got.Name != "widget" {
+		t.Fatalf("unexpected binding: %+v", got)
+	}
+	var resp item
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || resp.ID != 42 {
+		t.Fatalf("unexpected response %+v (%v)", resp, err)
+	}
+}
+
+func TestHandle_BindErrorListsInvalidParams(t *testing.T) {
+	handler := Handle(func(context.Context, updateItemRequest) (item, error) {
+		t.Fatal("handler should not run")
+		return item{}, nil
+	})
+
+	rec := serveTyped(t, handler, http.MethodPut, "/items/{id}", "/items/abc?verbose=maybe", `{"name":"x"}`, nil)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	problem := decodeProblem(t, rec)
+	if len(problem.InvalidParams) != 2 || problem.InvalidParams[0] != (InvalidParam{Name: "id", Reason: "must be a number"}) ||
+		problem.InvalidParams[1] != (InvalidParam{Name: "verbose", Reason: "must be a boolean"}) {
+		t.Fatalf("unexpected invalid params: %+v", problem.InvalidParams)
+	}
+	if problem.Type != "about:blank" || problem.Instance != "/items/abc" || problem.Title != "Bad Request" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestHandle_InvalidJSONAndUnknownFields(t *testing.T) {
+	fn := func(_ context.Context, req updateItemRequest) (item, error) { return item{Name: req.Name}, nil }
+
+	for _, body := range []string{`{"name":`, `{"name":"x","extra":1}`} {
+		rec := serveTyped(t, Handle(fn), http.MethodPut, "/items/{id}", "/items/1", body, nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("body %s: expected 400, got %d", body, rec.Code)
+		}
+		if problem := decodeProblem(t, rec); len(problem.InvalidParams) != 1 || problem.InvalidParams[0].Name != "body" {
+			t.Fatalf("body %s: unexpected params %+v", body, problem.InvalidParams)
+		}
+	}
+
+	rec := serveTyped(t, Handle(fn, WithUnknownFields()), http.MethodPut, "/items/{id}", "/items/1", `{"name":"x","extra":1}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected unknown fields to be accepted, got %d", rec.Code)
+	}
+}
+
+func TestHandle_ValidationError(t *testing.T) {
+	handler := Handle(func(context.Context, updateItemRequest) (item, error) {
+		t.Fatal("handler should not run")
+		return item{}, nil
+	})
+
+	rec := serveTyped(t, handler, http.MethodPut, "/items/{id}", "/items/1", `{"name":"  "}`, nil)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	problem := decodeProblem(t, rec)
+	if problem.Detail != "validation failed" || len(problem.InvalidParams) != 1 || problem.InvalidParams[0].Name != "name" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+}
+
+func TestHandle_ErrorMapping(t *testing.T) {
+	mapper := ErrorMapperFunc(func(_ *http.Request, err error) (Problem, bool) {
+		if errors.Is(err, errItemNotFound) {
+			return Problem{Status: http.StatusNotFound, Detail: "not found", Code: "ITEM_NOT_FOUND"}, true
+		}
+		return Problem{}, false
+	})
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+	}{
+		{name: "mapped", err: errItemNotFound, status: http.StatusNotFound, detail: "not found"},
+		{name: "problem", err: NewProblem(http.StatusConflict, "exists"), status: http.StatusConflict, detail: "exists"},
+		{name: "unknown", err: errors.New("db password leaked"), status: http.StatusInternalServerError, detail: "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handle(func(context.Context, struct{}) (item, error) { return item{}, tt.err }, WithErrorMapper(mapper))
+			rec := serveTyped(t, handler, http.MethodGet, "/items", "/items", "", nil)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, rec.Code)
+			}
+			if problem := decodeProblem(t, rec); problem.Detail != tt.detail {
+				t.Fatalf("expected detail %q, got %q", tt.detail, problem.Detail)
+			}
+		})
+	}
+}
+
+type createdItem item
+
+func (createdItem) StatusCode() int { return http.StatusCreated }
+
+func TestHandle_SuccessStatuses(t *testing.T) {
+	noContent := Handle(func(context.Context, struct{}) (NoContent, error) { return NoContent{}, nil })
+	if rec := serveTyped(t, noContent, http.MethodDelete, "/items", "/items", "", nil); rec.Code != http.StatusNoContent || rec.Body.Len() != 0 {
+		t.Fatalf("expected empty 204, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	withStatus := Handle(func(context.Context, struct{}) (item, error) { return item{}, nil }, WithStatus(http.StatusAccepted))
+	if rec := serveTyped(t, withStatus, http.MethodPost, "/items", "/items", "", nil); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", rec.Code)
+	}
+
+	coder := Handle(func(context.Context, struct{}) (createdItem, error) { return createdItem{}, nil })
+	if rec := serveTyped(t, coder, http.MethodPost, "/items", "/items", "", nil); rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+}
+
+func TestBind_UnsupportedFieldType(t *testing.T) {
+	var target struct {
+		Value map[string]string `query:"value"`
+	}
+	req := httptest.NewRequest(http.MethodGet, "/?value=x", http.NoBody)
+
+	err := Bind(req, &target)
+
+	var unsupported *UnsupportedBindTypeError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedBindTypeError, got %v", err)
+	}
+}
+
+func TestPathParam_StdlibMux(t *testing.T) {
+	mux := http.NewServeMux()
+	var got string
+	mux.HandleFunc("GET /items/{id}", func(_ http.ResponseWriter, r *http.Request) {
+		got = PathParam(r, "id")
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/7", http.NoBody))
+
+	if got != "7" {
+		t.Fatalf("expected 7, got %q", got)
+	}
+}
@@ -0,0 +1,318 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the idempotency key.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on replayed responses.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// ErrIdempotencyLockLost is returned by IdempotencyStore.Save when the lock on
+// the key expired and was claimed again, or was released, before the response
+// was stored.
+var ErrIdempotencyLockLost = errors.New("idempotency lock lost")
+
+// IdempotencyRecord is the state stored under an idempotency key. Status is
+// zero while the first request is still in flight.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request payload that claimed the key.
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	// ExpiresAt is when the lock or the stored response lapses.
+	ExpiresAt time.Time
+}
+
+// Completed reports whether the record holds a response.
+func (r *IdempotencyRecord) Completed() bool {
+	return r.Status != 0
+}
+
+// IdempotencyStore persists idempotency records. Implementations must make
+// Lock atomic across every process sharing the store.
+type IdempotencyStore interface {
+	// Lock claims key for a request with fingerprint until ttl elapses and
+	// returns a random owner token identifying the claim. When the key is
+	// already claimed by an unexpired record it returns that record and an
+	// empty owner.
+	Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, string, error)
+	// Save stores the response of the request whose claim on key is owner. It
+	// returns ErrIdempotencyLockLost when owner no longer holds the lock.
+	Save(ctx context.Context, key, owner string, record IdempotencyRecord) error
+	// Unlock releases the lock on key without storing a response, so the request
+	// can be retried. It does nothing when owner no longer holds the lock.
+	Unlock(ctx context.Context, key, owner string) error
+}
+
+// IdempotencyConfig configures the Idempotency middleware.
+type IdempotencyConfig struct {
+	// Store holds locks and responses (default a new MemoryIdempotencyStore,
+	// which only suits a single process).
+	Store IdempotencyStore
+	// TTL is how long responses are replayed (default 24 hours).
+	TTL time.Duration
+	// LockTimeout bounds how long an in-flight request holds its key, so a
+	// crashed request does not block retries forever (default 1 minute).
+	LockTimeout time.Duration
+	// Methods are the methods the middleware applies to (default POST and
+	// PATCH).
+	Methods []string
+	// MaxBodyBytes caps the request body read to fingerprint the request
+	// (default 1 MiB). Larger bodies are rejected with 413.
+	MaxBodyBytes int64
+	// Required rejects requests without a key with 400.
+	Required bool
+	// Scope partitions keys, for example by principal with RateLimitByPrincipal,
+	// so clients cannot replay each other's responses.
+	Scope func(r *http.Request) string
+}
+
+// Idempotency returns middleware that makes retries of unsafe requests with an
+// Idempotency-Key header safe. The first response for a key is stored with a
+// fingerprint of the method, URL, and body; retries get it replayed with an
+// Idempotent-Replayed header. Reusing a key with a different payload is
+// rejected with 422, and a retry while the first request is in flight with 409.
+// Responses with a 5xx status are not stored, so those requests can be retried.
+// Only the headers the handler sets are stored, not those of outer middleware.
+func Idempotency(cfg IdempotencyConfig) func(http.Handler) http.Handler {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryIdempotencyStore()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = time.Minute
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = 1 << 20
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !slices.Contains(cfg.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				if cfg.Required {
+					WriteError(w, r, NewProblem(http.StatusBadRequest, "Idempotency-Key header is required"))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				WriteError(w, r, NewProblem(http.StatusBadRequest, "Idempotency-Key header is too long"))
+				return
+			}
+			if cfg.Scope != nil {
+				if scope := cfg.Scope(r); scope != "" {
+					key = scope + ":" + key
+				}
+			}
+
+			if r.ContentLength > cfg.MaxBodyBytes {
+				WriteProblem(w, r, Problem{Status: http.StatusRequestEntityTooLarge, Detail: "request body too large"})
+				return
+			}
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, cfg.MaxBodyBytes))
+			if err != nil {
+				WriteError(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			fingerprint := requestFingerprint(r, body)
+
+			existing, owner, err := cfg.Store.Lock(r.Context(), key, fingerprint, cfg.LockTimeout)
+			if err != nil {
+				WriteError(w, r, err)
+				return
+			}
+			if owner == "" {
+				replayIdempotent(w, r, existing, fingerprint)
+				return
+			}
+			serveIdempotent(w, r, next, cfg, key, owner, fingerprint)
+		})
+	}
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	_, _ = h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func replayIdempotent(w http.ResponseWriter, r *http.Request, record *IdempotencyRecord, fingerprint string) {
+	switch {
+	case record.Fingerprint != fingerprint:
+		WriteError(w, r, NewProblem(http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request"))
+	case !record.Completed():
+		problem := NewProblem(http.StatusConflict, "a request with this Idempotency-Key is in progress")
+		problem.Headers = http.Header{"Retry-After": {"1"}}
+		WriteError(w, r, problem)
+	default:
+		for name, values := range record.Header {
+			w.Header()[name] = slices.Clone(values)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(record.Status)
+		_, _ = w.Write(record.Body)
+	}
+}
+
+// serveIdempotent runs next while owner holds the lock on key and stores its
+// response. The lock is released when the handler fails or panics.
+func serveIdempotent(w http.ResponseWriter, r *http.Request, next http.Handler, cfg IdempotencyConfig, key, owner, fingerprint string) {
+	ctx := context.WithoutCancel(r.Context())
+	saved := false
+	defer func() {
+		if !saved {
+			_ = cfg.Store.Unlock(ctx, key, owner)
+		}
+	}()
+
+	before := w.Header().Clone()
+	var body bytes.Buffer
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	ww.Tee(&body)
+	next.ServeHTTP(ww, r)
+
+	status := ww.Status()
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status >= http.StatusInternalServerError {
+		return
+	}
+	header := handlerHeader(before, ww.Header())
+	header.Del(IdempotentReplayedHeader)
+	saved = cfg.Store.Save(ctx, key, owner, IdempotencyRecord{
+		Fingerprint: fingerprint,
+		Status:      status,
+		Header:      header,
+		Body:        body.Bytes(),
+		ExpiresAt:   time.Now().Add(cfg.TTL),
+	}) == nil
+}
+
+// handlerHeader returns the headers of after that are not in before with the
+// same values, which are those set by the handler rather than by outer
+// middleware.
+func handlerHeader(before, after http.Header) http.Header {
+	header := http.Header{}
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			header[name] = slices.Clone(values)
+		}
+	}
+	return header
+}
+
+// newIdempotencyOwner returns a random lock owner token.
+func newIdempotencyOwner() string {
+	var owner [16]byte
+	_, _ = rand.Read(owner[:])
+	return hex.EncodeToString(owner[:])
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore.
+type MemoryIdempotencyStore struct {
+	now     func() time.Time
+	mu      sync.Mutex
+	records map[string]*memoryIdempotencyRecord
+	swept   time.Time
+}
+
+// memoryIdempotencyRecord is a record and the owner of its lock.
+type memoryIdempotencyRecord struct {
+	IdempotencyRecord
+	owner string
+}
+
+// NewMemoryIdempotencyStore returns an empty in-process store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{now: time.Now, records: make(map[string]*memoryIdempotencyRecord)}
+}
+
+// Lock implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Lock(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, string, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) >= time.Minute {
+		for k, record := range s.records {
+			if !now.Before(record.ExpiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.swept = now
+	}
+
+	if record, ok := s.records[key]; ok && now.Before(record.ExpiresAt) {
+		existing := record.IdempotencyRecord
+		return &existing, "", nil
+	}
+	owner := newIdempotencyOwner()
+	s.records[key] = &memoryIdempotencyRecord{
+		IdempotencyRecord: IdempotencyRecord{Fingerprint: fingerprint, ExpiresAt: now.Add(ttl)},
+		owner:             owner,
+	}
+	return nil, owner, nil
+}
+
+// Save implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Save(_ context.Context, key, owner string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lockedBy(key, owner) {
+		return ErrIdempotencyLockLost
+	}
+	record.Header = record.Header.Clone()
+	record.Body = bytes.Clone(record.Body)
+	s.records[key] = &memoryIdempotencyRecord{IdempotencyRecord: record}
+	return nil
+}
+
+// Unlock implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Unlock(_ context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lockedBy(key, owner) {
+		delete(s.records, key)
+	}
+	return nil
+}
+
+// lockedBy reports whether key holds an in-flight lock claimed by owner. The
+// caller holds s.mu.
+func (s *MemoryIdempotencyStore) lockedBy(key, owner string) bool {
+	record, ok := s.records[key]
+	return ok && !record.Completed() && record.owner == owner && owner != ""
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlmodule"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+const idempotencyModuleName = "http.idempotency"
+
+// Tokens exported by NewIdempotencyModule.
+const (
+	// TokenIdempotencyStore resolves the IdempotencyStore.
+	TokenIdempotencyStore module.Token = "http.idempotency.store"
+	// TokenIdempotencyMiddleware resolves the Idempotency middleware, for use in
+	// module.HTTPDef.Middleware or with Router.Use.
+	TokenIdempotencyMiddleware module.Token = "http.idempotency.middleware"
+)
+
+// IdempotencyOptions configures NewIdempotencyModule.
+type IdempotencyOptions struct {
+	// Config configures the middleware. Config.Store, when set, is used as is
+	// and Database is ignored.
+	Config IdempotencyConfig
+	// Database is the SQL data module whose handle backs a SQLIdempotencyStore,
+	// for example postgres.NewModule(postgres.Options{}). It is imported by the
+	// idempotency module. Without it records are kept in memory.
+	Database module.Module
+	// DatabaseName is the data module's instance name (see
+	// sqlmodule.NamedTokens); empty for the default database.db token.
+	DatabaseName string
+	// Table is the SQL table (default DefaultIdempotencyTable).
+	Table string
+	// CreateTable creates the SQL table when the store is built.
+	CreateTable bool
+}
+
+// IdempotencyModule provides an IdempotencyStore and the Idempotency
+// middleware using it.
+type IdempotencyModule struct {
+	opts IdempotencyOptions
+}
+
+// NewIdempotencyModule constructs a module exporting TokenIdempotencyStore and
+// TokenIdempotencyMiddleware.
+func NewIdempotencyModule(opts IdempotencyOptions) module.Module {
+	return &IdempotencyModule{opts: opts}
+}
+
+// Definition returns the module definition for graph construction.
+func (m *IdempotencyModule) Definition() module.ModuleDef {
+	var imports []module.Module
+	if m.opts.Config.Store == nil && m.opts.Database != nil {
+		imports = append(imports, m.opts.Database)
+	}
+
+	return module.ModuleDef{
+		Name:    idempotencyModuleName,
+		Imports: imports,
+		Providers: []module.ProviderDef{
+			{Token: TokenIdempotencyStore, Build: m.buildStore},
+			{
+				Token: TokenIdempotencyMiddleware,
+				Build: func(r module.Resolver) (any, error) {
+					store, err := module.Get[IdempotencyStore](r, TokenIdempotencyStore)
+					if err != nil {
+						return nil, err
+					}
+					cfg := m.opts.Config
+					cfg.Store = store
+					return Idempotency(cfg), nil
+				},
+			},
+		},
+		Exports: []module.Token{TokenIdempotencyStore, TokenIdempotencyMiddleware},
+	}
+}
+
+func (m *IdempotencyModule) buildStore(r module.Resolver) (any, error) {
+	if m.opts.Config.Store != nil {
+		return m.opts.Config.Store, nil
+	}
+	if m.opts.Database == nil {
+		return NewMemoryIdempotencyStore(), nil
+	}
+
+	toks, err := sqlmodule.NamedTokens(m.opts.DatabaseName)
+	if err != nil {
+		return nil, err
+	}
+	db, err := module.Get[*sql.DB](r, toks.DB)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: database %q: %w", m.opts.DatabaseName, err)
+	}
+	dialect, err := module.Get[sqlmodule.Dialect](r, toks.Dialect)
+	if err != nil {
+		return nil, fmt.Errorf("idempotency: database %q: %w", m.opts.DatabaseName, err)
+	}
+	store, err := NewSQLIdempotencyStore(db, dialect, m.opts.Table)
+	if err != nil {
+		return nil, err
+	}
+	if m.opts.CreateTable {
+		if err := store.CreateTable(context.Background()); err != nil {
+			return nil, fmt.Errorf("idempotency: create table: %w", err)
+		}
+	}
+	return store, nil
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlmodule"
+)
+
+// DefaultIdempotencyTable is the table used by SQLIdempotencyStore when no
+// table name is given.
+const DefaultIdempotencyTable = "modkit_idempotency"
+
+var sqlIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLIdempotencyStore is an IdempotencyStore backed by a database/sql handle,
+// shared by every process using the database. Lock relies on the primary key of
+// the table, so it is atomic without transactions.
+type SQLIdempotencyStore struct {
+	db      *sql.DB
+	dialect sqlmodule.Dialect
+	table   string
+	now     func() time.Time
+}
+
+// NewSQLIdempotencyStore returns a store keeping records in table (default
+// DefaultIdempotencyTable) of db. Create the table with CreateTable or a
+// migration of the same shape.
+func NewSQLIdempotencyStore(db *sql.DB, dialect sqlmodule.Dialect, table string) (*SQLIdempotencyStore, error) {
+	if db == nil {
+		return nil, &IdempotencyStoreConfigError{Reason: "db is nil"}
+	}
+	switch dialect {
+	case sqlmodule.DialectPostgres, sqlmodule.DialectSQLite, sqlmodule.DialectMySQL:
+	default:
+		return nil, &IdempotencyStoreConfigError{Reason: "unsupported dialect " + strconv.Quote(string(dialect))}
+	}
+	if table == "" {
+		table = DefaultIdempotencyTable
+	}
+	if !sqlIdentifier.MatchString(table) {
+		return nil, &IdempotencyStoreConfigError{Reason: "invalid table name " + strconv.Quote(table)}
+	}
+	return &SQLIdempotencyStore{db: db, dialect: dialect, table: table, now: time.Now}, nil
+}
+
+// CreateTable creates the table if it does not exist.
+func (s *SQLIdempotencyStore) CreateTable(ctx context.Context) error {
+	bodyType, headerType := "BLOB", "TEXT"
+	switch s.dialect {
+	case sqlmodule.DialectPostgres:
+		bodyType = "BYTEA"
+	case sqlmodule.DialectMySQL:
+		bodyType, headerType = "LONGBLOB", "MEDIUMTEXT"
+	}
+	_, err := s.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+s.table+" ("+
+		"idempotency_key VARCHAR(512) NOT NULL PRIMARY KEY, "+
+		"fingerprint VARCHAR(64) NOT NULL, "+
+		"lock_owner VARCHAR(32) NOT NULL, "+
+		"status INTEGER NOT NULL, "+
+		"header "+headerType+", "+
+		"body "+bodyType+", "+
+		"expires_at BIGINT NOT NULL)")
+	return err
+}
+
+// Lock implements IdempotencyStore.
+func (s *SQLIdempotencyStore) Lock(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, string, error) {
+	now := s.now()
+	owner := newIdempotencyOwner()
+	insert := "INSERT INTO " + s.table + " (idempotency_key, fingerprint, lock_owner, status, expires_at) VALUES (?, ?, ?, 0, ?)"
+	if s.dialect == sqlmodule.DialectMySQL {
+		insert = "INSERT IGNORE" + strings.TrimPrefix(insert, "INSERT")
+	} else {
+		insert += " ON CONFLICT (idempotency_key) DO NOTHING"
+	}
+
+	// A concurrent Unlock or expiry can remove the row between the insert and the
+	// select, so the claim is retried once.
+	for range 2 {
+		if _, err := s.exec(ctx, "DELETE FROM "+s.table+" WHERE idempotency_key = ? AND expires_at <= ?",
+			key, now.UnixMilli()); err != nil {
+			return nil, "", err
+		}
+		res, err := s.exec(ctx, insert, key, fingerprint, owner, now.Add(ttl).UnixMilli())
+		if err != nil {
+			return nil, "", err
+		}
+		inserted, err := res.RowsAffected()
+		if err != nil {
+			return nil, "", err
+		}
+		if inserted == 1 {
+			return nil, owner, nil
+		}
+
+		record, err := s.load(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return record, "", nil
+	}
+	return nil, "", errors.New("idempotency: could not lock key")
+}
+
+func (s *SQLIdempotencyStore) load(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	var (
+		record    IdempotencyRecord
+		header    sql.NullString
+		expiresAt int64
+	)
+	row := s.db.QueryRowContext(ctx, s.rebind("SELECT fingerprint, status, header, body, expires_at FROM "+
+		s.table+" WHERE idempotency_key = ?"), key)
+	if err := row.Scan(&record.Fingerprint, &record.Status, &header, &record.Body, &expiresAt); err != nil {
+		return nil, err
+	}
+	if header.String != "" {
+		if err := json.Unmarshal([]byte(header.String), &record.Header); err != nil {
+			return nil, err
+		}
+	}
+	record.ExpiresAt = time.UnixMilli(expiresAt)
+	return &record, nil
+}
+
+// Save implements IdempotencyStore.
+func (s *SQLIdempotencyStore) Save(ctx context.Context, key, owner string, record IdempotencyRecord) error {
+	header, err := json.Marshal(record.Header)
+	if err != nil {
+		return err
+	}
+	body := record.Body
+	if body == nil {
+		body = []byte{}
+	}
+	res, err := s.exec(ctx, "UPDATE "+s.table+" SET status = ?, header = ?, body = ?, expires_at = ? "+
+		"WHERE idempotency_key = ? AND lock_owner = ? AND status = 0",
+		record.Status, string(header), body, record.ExpiresAt.UnixMilli(), key, owner)
+	if err != nil {
+		return err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrIdempotencyLockLost
+	}
+	return nil
+}
+
+// Unlock implements IdempotencyStore.
+func (s *SQLIdempotencyStore) Unlock(ctx context.Context, key, owner string) error {
+	_, err := s.exec(ctx, "DELETE FROM "+s.table+" WHERE idempotency_key = ? AND lock_owner = ? AND status = 0", key, owner)
+	return err
+}
+
+// DeleteExpired removes expired records and returns how many were removed.
+// Expired keys are replaced when reused, so this only bounds the table size;
+// run it periodically, for example from a module.Runnable.
+func (s *SQLIdempotencyStore) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := s.exec(ctx, "DELETE FROM "+s.table+" WHERE expires_at <= ?", s.now().UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (s *SQLIdempotencyStore) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.rebind(query), args...)
+}
+
+// rebind rewrites ? placeholders to $n for PostgreSQL.
+func (s *SQLIdempotencyStore) rebind(query string) string {
+	if s.dialect != sqlmodule.DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+var _ IdempotencyStore = (*SQLIdempotencyStore)(nil)
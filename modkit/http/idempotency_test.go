@@ -0,0 +1,364 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlmodule"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func idempotentRequest(method, target, key, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return req
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(string(body) + ":" + strconv.Itoa(int(n))))
+	}))
+
+	first := httptest.NewRecorder()
+	handler.ServeHTTP(first, idempotentRequest(http.MethodPost, "/orders", "k1", "order"))
+	if first.Code != http.StatusCreated || first.Body.String() != "order:1" {
+		t.Fatalf("unexpected first response: %d %q", first.Code, first.Body.String())
+	}
+	if first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("first response must not be marked replayed")
+	}
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, idempotentRequest(http.MethodPost, "/orders", "k1", "order"))
+	if second.Code != http.StatusCreated || second.Body.String() != "order:1" {
+		t.Fatalf("unexpected replay: %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get(IdempotentReplayedHeader) != "true" || second.Header().Get("Location") != "/orders/1" {
+		t.Fatalf("unexpected replay headers: %v", second.Header())
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected handler to run once, ran %d times", calls.Load())
+	}
+}
+
+func TestIdempotency_ReplaysOnlyHandlerHeaders(t *testing.T) {
+	var requests atomic.Int32
+	requestID := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "req-"+strconv.Itoa(int(requests.Add(1))))
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := requestID(Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Location", "/orders/1")
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "/orders", "k1", "order"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/orders", "k1", "order"))
+	if rec.Header().Get(IdempotentReplayedHeader) != "true" || rec.Header().Get("Location") != "/orders/1" {
+		t.Fatalf("unexpected replay headers: %v", rec.Header())
+	}
+	if got := rec.Header().Values("X-Request-Id"); len(got) != 1 || got[0] != "req-2" {
+		t.Fatalf("expected the replay to keep its own request ID, got %v", got)
+	}
+}
+
+func TestIdempotency_LimitsBody(t *testing.T) {
+	handler := Idempotency(IdempotencyConfig{MaxBodyBytes: 4})(noContent())
+
+	for _, req := range []*http.Request{
+		idempotentRequest(http.MethodPost, "/", "k1", "12345"),
+		func() *http.Request {
+			req := idempotentRequest(http.MethodPost, "/", "k2", "12345")
+			req.ContentLength = -1
+			return req
+		}(),
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected 413, got %d", rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/", "k3", "1234"))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected body within the limit to pass, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_RejectsKeyReuseWithDifferentPayload(t *testing.T) {
+	handler := Idempotency(IdempotencyConfig{})(noContent())
+
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "/orders", "k1", "a"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/orders", "k1", "b"))
+	if rec.Code != http.StatusUnprocessableEntity || rec.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected 422 problem, got %d %v", rec.Code, rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/other", "k1", "a"))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a different URL, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_RejectsInFlightDuplicate(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(entered)
+		<-release
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/jobs", "k1", "job"))
+		done <- rec.Code
+	}()
+	<-entered
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/jobs", "k1", "job"))
+	if rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("expected 409 with Retry-After, got %d %v", rec.Code, rec.Header())
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusAccepted {
+		t.Fatalf("expected first request to finish with 202, got %d", code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/jobs", "k1", "job"))
+	if rec.Code != http.StatusAccepted || rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected replayed 202, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnServerErrorAndPanic(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(IdempotencyConfig{})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			panic("boom")
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/", "k1", ""))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatalf("expected panic to propagate")
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "/", "k1", ""))
+	}()
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/", "k1", ""))
+	if rec.Code != http.StatusOK || rec.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("expected a fresh 200, got %d %v", rec.Code, rec.Header())
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestIdempotency_KeyRequirementsAndMethods(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(IdempotencyConfig{Required: true})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/", "", ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without key, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, idempotentRequest(http.MethodPost, "/", strings.Repeat("k", 256), ""))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for long key, got %d", rec.Code)
+	}
+
+	for i := 0; i < 2; i++ {
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, idempotentRequest(http.MethodPut, "/", "k1", ""))
+		if rec.Code != http.StatusNoContent || rec.Header().Get(IdempotentReplayedHeader) != "" {
+			t.Fatalf("expected PUT to bypass the middleware, got %d %v", rec.Code, rec.Header())
+		}
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestIdempotency_ScopePartitionsKeys(t *testing.T) {
+	var calls atomic.Int32
+	handler := Idempotency(IdempotencyConfig{Scope: RateLimitByPrincipal})(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(subject string) *httptest.ResponseRecorder {
+		req := idempotentRequest(http.MethodPost, "/", "k1", "")
+		req = req.WithContext(WithPrincipal(req.Context(), Principal{Subject: subject}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	serve("alice")
+	if rec := serve("bob"); rec.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Fatalf("expected separate key per principal")
+	}
+	if rec := serve("alice"); rec.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Fatalf("expected replay for the same principal")
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("expected 2 handler calls, got %d", calls.Load())
+	}
+}
+
+func TestMemoryIdempotencyStore_ExpiresRecords(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, owner, _ := store.Lock(ctx, "k", "f", time.Minute); owner == "" {
+		t.Fatalf("expected first lock to be acquired")
+	}
+	if record, owner, _ := store.Lock(ctx, "k", "f", time.Minute); owner != "" || record.Completed() {
+		t.Fatalf("expected in-flight record, got owner=%q record=%+v", owner, record)
+	}
+
+	now = now.Add(time.Minute)
+	_, owner, _ := store.Lock(ctx, "k", "f", time.Minute)
+	if owner == "" {
+		t.Fatalf("expected expired lock to be reclaimed")
+	}
+	if err := store.Save(ctx, "k", owner, IdempotencyRecord{Fingerprint: "f", Status: 200, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Unlock(ctx, "k", owner); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if record, owner, _ := store.Lock(ctx, "k", "f", time.Minute); owner != "" || record.Status != 200 {
+		t.Fatalf("expected stored response to survive Unlock, got owner=%q record=%+v", owner, record)
+	}
+}
+
+func TestMemoryIdempotencyStore_ChecksLockOwner(t *testing.T) {
+	now := time.Unix(1000, 0)
+	store := NewMemoryIdempotencyStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, stale, _ := store.Lock(ctx, "k", "f", time.Minute)
+	now = now.Add(time.Minute)
+	_, current, _ := store.Lock(ctx, "k", "f", time.Minute)
+	if stale == "" || current == "" || stale == current {
+		t.Fatalf("expected distinct owners, got %q and %q", stale, current)
+	}
+
+	if err := store.Unlock(ctx, "k", stale); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	if err := store.Save(ctx, "k", stale, IdempotencyRecord{Fingerprint: "f", Status: 200}); !errors.Is(err, ErrIdempotencyLockLost) {
+		t.Fatalf("expected ErrIdempotencyLockLost for a stale owner, got %v", err)
+	}
+	if record, owner, _ := store.Lock(ctx, "k", "f", time.Minute); owner != "" || record.Completed() {
+		t.Fatalf("expected the current lock to survive the stale owner, got owner=%q record=%+v", owner, record)
+	}
+	if err := store.Save(ctx, "k", current, IdempotencyRecord{Fingerprint: "f", Status: 200, ExpiresAt: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+}
+
+func TestNewSQLIdempotencyStore_ValidatesConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		db      *sql.DB
+		dialect sqlmodule.Dialect
+		table   string
+	}{
+		{name: "nil db", dialect: sqlmodule.DialectPostgres},
+		{name: "unknown dialect", db: new(sql.DB), dialect: "oracle"},
+		{name: "invalid table", db: new(sql.DB), dialect: sqlmodule.DialectSQLite, table: "keys; DROP TABLE users"},
+	}
+	for _, tc := range cases {
+		_, err := NewSQLIdempotencyStore(tc.db, tc.dialect, tc.table)
+		var configErr *IdempotencyStoreConfigError
+		if !errors.As(err, &configErr) {
+			t.Fatalf("%s: expected IdempotencyStoreConfigError, got %v", tc.name, err)
+		}
+	}
+
+	store, err := NewSQLIdempotencyStore(new(sql.DB), sqlmodule.DialectPostgres, "")
+	if err != nil {
+		t.Fatalf("NewSQLIdempotencyStore failed: %v", err)
+	}
+	if got := store.rebind("UPDATE t SET a = ? WHERE b = ?"); got != "UPDATE t SET a = $1 WHERE b = $2" {
+		t.Fatalf("unexpected rebind: %q", got)
+	}
+}
+
+func TestIdempotencyModule_ExportsMiddleware(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	root := &mountModule{def: module.ModuleDef{
+		Name:    "root",
+		Imports: []module.Module{NewIdempotencyModule(IdempotencyOptions{Config: IdempotencyConfig{Store: store}})},
+	}}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	got, err := app.Get(TokenIdempotencyStore)
+	if err != nil || got != IdempotencyStore(store) {
+		t.Fatalf("expected configured store, got %v %v", got, err)
+	}
+	mw, err := module.Get[func(http.Handler) http.Handler](app, TokenIdempotencyMiddleware)
+	if err != nil {
+		t.Fatalf("Get middleware failed: %v", err)
+	}
+	handler := mw(noContent())
+	handler.ServeHTTP(httptest.NewRecorder(), idempotentRequest(http.MethodPost, "/", "k1", ""))
+	if _, owner, _ := store.Lock(context.Background(), "k1", "", time.Minute); owner != "" {
+		t.Fatalf("expected middleware to use the configured store")
+	}
+}
@@ -0,0 +1,230 @@
+package http
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register SHA-256 for HS256/RS256.
+	_ "crypto/sha512" // Register SHA-384/512 for HS384/HS512/RS384/RS512.
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures BearerJWT.
+type JWTConfig struct {
+	// Key verifies signatures: a []byte secret for HS256/HS384/HS512 or an
+	// *rsa.PublicKey for RS256/RS384/RS512.
+	Key any
+	// Algorithms lists accepted "alg" values. Defaults to HS256 for []byte keys
+	// and RS256 for RSA keys.
+	Algorithms []string
+	// Issuer and Audience, when set, must match the "iss" and "aud" claims.
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding roles (default "roles"). Both string
+	// arrays and space-separated strings are accepted.
+	RolesClaim string
+	// Leeway tolerates clock skew when checking "exp" and "nbf".
+	Leeway time.Duration
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+}
+
+// ErrInvalidToken is wrapped by the 401 *Problem a BearerJWT guard returns for
+// a token that fails verification, so errors.Is matches it. A missing token
+// is rejected with a plain 401 *Problem.
+var ErrInvalidToken = errors.New("invalid token")
+
+// BearerJWT returns a guard that authenticates "Authorization: Bearer" JWTs and
+// stores the Principal in the request context. Tokens must carry "exp". On
+// public routes a missing or invalid token is ignored.
+func BearerJWT(cfg JWTConfig) Guard {
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if len(cfg.Algorithms) == 0 {
+		switch cfg.Key.(type) {
+		case *rsa.PublicKey:
+			cfg.Algorithms = []string{"RS256"}
+		default:
+			cfg.Algorithms = []string{"HS256"}
+		}
+	}
+
+	return &jwtGuard{cfg: cfg}
+}
+
+type jwtGuard struct {
+	cfg JWTConfig
+}
+
+// SecurityScheme reports the HTTP bearer scheme.
+func (g *jwtGuard) SecurityScheme() SecurityScheme {
+	return SecurityScheme{Key: "bearerAuth", Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+}
+
+// Allow verifies the bearer token and adds its Principal to the context.
+func (g *jwtGuard) Allow(r *http.Request, meta RouteMeta) (*http.Request, error) {
+	token := bearerToken(r.Header.Get("Authorization"))
+	if token == "" {
+		if meta.Public {
+			return r, nil
+		}
+		return nil, unauthorized("missing bearer token", nil)
+	}
+
+	claims, err := g.cfg.verify(token)
+	if err != nil {
+		if meta.Public {
+			return r, nil
+		}
+		return nil, unauthorized("invalid bearer token", err)
+	}
+
+	principal := Principal{Claims: claims, Roles: claimStrings(claims[g.cfg.RolesClaim])}
+	principal.Subject, _ = claims["sub"].(string)
+	return r.WithContext(WithPrincipal(r.Context(), principal)), nil
+}
+
+func unauthorized(detail string, cause error) *Problem {
+	problem := NewProblem(http.StatusUnauthorized, detail)
+	problem.cause = cause
+	problem.Headers = http.Header{"WWW-Authenticate": []string{"Bearer"}}
+	return problem
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+func (cfg JWTConfig) verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(cfg.Algorithms, header.Alg) {
+		return nil, ErrInvalidToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := verifySignature(header.Alg, cfg.Key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	if err := cfg.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, target any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return ErrInvalidToken
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func verifySignature(alg string, key any, signingInput string, signature []byte) error {
+	hashes := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+	if len(alg) != 5 {
+		return ErrInvalidToken
+	}
+	hash, ok := hashes[alg[2:]]
+	if !ok {
+		return ErrInvalidToken
+	}
+
+	switch {
+	case strings.HasPrefix(alg, "HS"):
+		secret, ok := key.([]byte)
+		if !ok || len(secret) == 0 {
+			return ErrInvalidToken
+		}
+		mac := hmac.New(hash.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return ErrInvalidToken
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS"):
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidToken
+		}
+		digest := hash.New()
+		digest.Write([]byte(signingInput))
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest.Sum(nil), signature); err != nil {
+			return ErrInvalidToken
+		}
+		return nil
+	default:
+		return ErrInvalidToken
+	}
+}
+
+func (cfg JWTConfig) validateClaims(claims map[string]any) error {
+	now := cfg.Now()
+
+	exp, ok := claims["exp"].(float64)
+	if !ok || now.After(time.Unix(int64(exp), 0).Add(cfg.Leeway)) {
+		return ErrInvalidToken
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(cfg.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return ErrInvalidToken
+	}
+	if cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != cfg.Issuer {
+			return ErrInvalidToken
+		}
+	}
+	if cfg.Audience != "" && !slices.Contains(claimStrings(claims["aud"]), cfg.Audience) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+func claimStrings(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
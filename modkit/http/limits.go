@@ -0,0 +1,54 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Timeout returns middleware that cancels the request context after d. When the
+// handler returns after the deadline without writing a response, a 503 problem
+// is written. Handlers must honor context cancellation; a zero or negative d
+// disables the timeout.
+func Timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+			if ww.Status() == 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				WriteProblem(w, r, Problem{Status: http.StatusServiceUnavailable, Detail: "request timed out"})
+			}
+		})
+	}
+}
+
+// MaxBodySize returns middleware that limits request bodies to limit bytes.
+// Requests declaring a larger Content-Length are rejected with 413; reading past
+// the limit returns *http.MaxBytesError, which handlers built with Handle also
+// report as 413. A zero or negative limit disables the check.
+func MaxBodySize(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > limit {
+				WriteProblem(w, r, Problem{Status: http.StatusRequestEntityTooLarge, Detail: "request body too large"})
+				return
+			}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = http.MaxBytesReader(w, r.Body, limit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
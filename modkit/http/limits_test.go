@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeout_WritesProblemWhenHandlerGivesUp(t *testing.T) {
+	handler := Timeout(10 * time.Millisecond)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected 503 problem, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestTimeout_KeepsWrittenResponse(t *testing.T) {
+	handler := Timeout(time.Second)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("expected request deadline")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected handler response, got %d", rec.Code)
+	}
+
+	if Timeout(0)(noContent()) == nil {
+		t.Fatal("expected disabled timeout to return next")
+	}
+}
+
+func TestMaxBodySize(t *testing.T) {
+	var readErr error
+	handler := MaxBodySize(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("12345")))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for declared length, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("12345"))
+	req.ContentLength = -1
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if readErr == nil {
+		t.Fatal("expected read past the limit to fail")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("1234")))
+	if rec.Code != http.StatusNoContent || readErr != nil {
+		t.Fatalf("expected body within limit, got %d %v", rec.Code, readErr)
+	}
+}
+
+func TestMaxBodySize_TypedHandlerReports413(t *testing.T) {
+	type input struct {
+		Name string `json:"name"`
+	}
+	handler := MaxBodySize(8)(Handle(func(context.Context, input) (NoContent, error) {
+		return NoContent{}, nil
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"too long"}`))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413, got %d %s", rec.Code, rec.Body.String())
+	}
+}
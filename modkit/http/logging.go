@@ -6,9 +6,12 @@ import (
 
 	"github.com/go-chi/chi/v5/middleware" //nolint:goimports // False positive
 	"github.com/go-modkit/modkit/modkit/logging"
+	"github.com/go-modkit/modkit/modkit/tracing"
 )
 
 // RequestLogger returns an HTTP middleware that logs each request with method, path, status, and duration.
+// The chi request ID and the trace and span IDs of the Tracing middleware are
+// added when present, so place it after both.
 func RequestLogger(logger logging.Logger) func(http.Handler) http.Handler {
 	if logger == nil {
 		logger = logging.NewNopLogger()
@@ -20,12 +23,19 @@ func RequestLogger(logger logging.Logger) func(http.Handler) http.Handler {
 			next.ServeHTTP(ww, r)
 			duration := time.Since(start)
 
-			logger.Info("http request",
+			args := []any{
 				"method", r.Method,
 				"path", r.URL.Path,
 				"status", ww.Status(),
 				"duration", duration,
-			)
+			}
+			if id := middleware.GetReqID(r.Context()); id != "" {
+				args = append(args, "request_id", id)
+			}
+			if sc, ok := tracing.SpanContextFromContext(r.Context()); ok {
+				args = append(args, "trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String())
+			}
+			logger.Info("http request", args...)
 		})
 	}
 }
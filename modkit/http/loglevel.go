@@ -0,0 +1,44 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// LogLevel is the body of the LogLevelHandler endpoint.
+type LogLevel struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler returns an admin endpoint for the level of loggers built with
+// logging.NewSlogLoggerWithOptions. GET returns the current level; PUT with a
+// LogLevel body such as {"level":"debug"} changes it atomically for every
+// logger sharing level. Other methods get 405. Register it behind a guard;
+// anyone who can reach it can make the service log at debug level.
+func LogLevelHandler(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut:
+			var body LogLevel
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				WriteProblem(w, r, Problem{Status: http.StatusBadRequest, Detail: "invalid JSON body"})
+				return
+			}
+			var parsed slog.Level
+			if err := parsed.UnmarshalText([]byte(strings.TrimSpace(body.Level))); err != nil {
+				WriteProblem(w, r, Problem{Status: http.StatusBadRequest, Detail: "unknown log level " + body.Level})
+				return
+			}
+			level.Set(parsed)
+		default:
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+			WriteProblem(w, r, Problem{Status: http.StatusMethodNotAllowed})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(LogLevel{Level: level.Level().String()})
+	})
+}
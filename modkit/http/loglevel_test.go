@@ -0,0 +1,55 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	level := &slog.LevelVar{}
+	handler := LogLevelHandler(level)
+
+	get := func() LogLevel {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/log-level", nil))
+		var body LogLevel
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return body
+	}
+	if got := get().Level; got != "INFO" {
+		t.Fatalf("expected INFO, got %q", got)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+	if rec.Code != http.StatusOK || level.Level() != slog.LevelDebug {
+		t.Fatalf("expected level change, got %d %s", rec.Code, level.Level())
+	}
+	if got := get().Level; got != "DEBUG" {
+		t.Fatalf("expected DEBUG, got %q", got)
+	}
+
+	for _, tc := range []struct {
+		method, body string
+		status       int
+	}{
+		{http.MethodPut, `{"level":"loud"}`, http.StatusBadRequest},
+		{http.MethodPut, `not json`, http.StatusBadRequest},
+		{http.MethodPost, `{"level":"error"}`, http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tc.method, "/admin/log-level", strings.NewReader(tc.body)))
+		if rec.Code != tc.status {
+			t.Fatalf("%s %s: expected %d, got %d", tc.method, tc.body, tc.status, rec.Code)
+		}
+	}
+	if level.Level() != slog.LevelDebug {
+		t.Fatalf("expected rejected requests to keep the level, got %s", level.Level())
+	}
+}
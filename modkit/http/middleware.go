@@ -1,3 +1,304 @@
 package http
 
-// Intentionally minimal for MVP.
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/config"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+const middlewareModuleName = "http.middleware"
+
+// Config tokens provided by MiddlewareConfigModule.
+const (
+	// TokenCORSAllowedOrigins resolves CORSConfig.AllowedOrigins.
+	TokenCORSAllowedOrigins module.Token = "http.middleware.cors_allowed_origins"
+	// TokenCORSAllowedMethods resolves CORSConfig.AllowedMethods.
+	TokenCORSAllowedMethods module.Token = "http.middleware.cors_allowed_methods"
+	// TokenCORSAllowedHeaders resolves CORSConfig.AllowedHeaders.
+	TokenCORSAllowedHeaders module.Token = "http.middleware.cors_allowed_headers"
+	// TokenCORSExposedHeaders resolves CORSConfig.ExposedHeaders.
+	TokenCORSExposedHeaders module.Token = "http.middleware.cors_exposed_headers"
+	// TokenCORSAllowCredentials resolves CORSConfig.AllowCredentials.
+	TokenCORSAllowCredentials module.Token = "http.middleware.cors_allow_credentials"
+	// TokenCORSMaxAge resolves CORSConfig.MaxAge.
+	TokenCORSMaxAge module.Token = "http.middleware.cors_max_age"
+	// TokenRateLimitPerSecond resolves RateLimitConfig.RequestsPerSecond.
+	TokenRateLimitPerSecond module.Token = "http.middleware.rate_limit_per_second"
+	// TokenRateLimitBurst resolves RateLimitConfig.Burst.
+	TokenRateLimitBurst module.Token = "http.middleware.rate_limit_burst"
+	// TokenRateLimitKey resolves the rate limit key: "ip" or "principal".
+	TokenRateLimitKey module.Token = "http.middleware.rate_limit_key"
+	// TokenRequestTimeout resolves the Timeout duration.
+	TokenRequestTimeout module.Token = "http.middleware.request_timeout"
+	// TokenMaxBodyBytes resolves the MaxBodySize limit.
+	TokenMaxBodyBytes module.Token = "http.middleware.max_body_bytes"
+	// TokenSecurityHeadersEnabled resolves whether SecurityHeaders are sent.
+	TokenSecurityHeadersEnabled module.Token = "http.middleware.security_headers_enabled"
+	// TokenContentSecurityPolicy resolves SecurityHeadersConfig.ContentSecurityPolicy.
+	TokenContentSecurityPolicy module.Token = "http.middleware.content_security_policy"
+	// TokenHSTSMaxAge resolves SecurityHeadersConfig.HSTSMaxAge.
+	TokenHSTSMaxAge module.Token = "http.middleware.hsts_max_age"
+)
+
+// Middleware tokens exported by NewMiddlewareModule. Each resolves to a
+// func(http.Handler) http.Handler usable in module.HTTPDef.Middleware, except
+// TokenRateLimiter, which resolves to the *RateLimiter for use as a guard.
+const (
+	TokenCORSMiddleware            module.Token = "http.middleware.cors"
+	TokenRateLimiter               module.Token = "http.middleware.rate_limiter"
+	TokenRateLimitMiddleware       module.Token = "http.middleware.rate_limit"
+	TokenTimeoutMiddleware         module.Token = "http.middleware.timeout"
+	TokenMaxBodySizeMiddleware     module.Token = "http.middleware.max_body_size"
+	TokenSecurityHeadersMiddleware module.Token = "http.middleware.security_headers"
+)
+
+// MiddlewareConfigModule provides the settings of the built-in middleware from
+// environment variables. opts are applied after the defaults, for example
+// config.WithSource.
+//
+// All optional:
+// - HTTP_CORS_ALLOWED_ORIGINS (CSV; empty disables CORS headers)
+// - HTTP_CORS_ALLOWED_METHODS, HTTP_CORS_ALLOWED_HEADERS, HTTP_CORS_EXPOSED_HEADERS (CSV)
+// - HTTP_CORS_ALLOW_CREDENTIALS (default false), HTTP_CORS_MAX_AGE (duration)
+// - HTTP_RATE_LIMIT_PER_SECOND (default 0; disables rate limiting)
+// - HTTP_RATE_LIMIT_BURST (default 1), HTTP_RATE_LIMIT_KEY ("ip" or "principal", default "ip")
+// - HTTP_REQUEST_TIMEOUT (default 0; disables the timeout)
+// - HTTP_MAX_BODY_BYTES (default 0; disables the limit)
+// - HTTP_SECURITY_HEADERS (default true), HTTP_CONTENT_SECURITY_POLICY, HTTP_HSTS_MAX_AGE
+func MiddlewareConfigModule(opts ...config.Option) module.Module {
+	burst := 1
+	key := "ip"
+	securityHeaders := true
+	csp := DefaultSecurityHeaders().ContentSecurityPolicy
+
+	options := []config.Option{
+		config.WithModuleName(middlewareModuleName + ".config"),
+		config.WithTyped(TokenCORSAllowedOrigins, config.ValueSpec[[]string]{
+			Key:         "HTTP_CORS_ALLOWED_ORIGINS",
+			Description: "Origins allowed by CORS; * allows any origin.",
+			Parse:       config.ParseCSV,
+		}, true),
+		config.WithTyped(TokenCORSAllowedMethods, config.ValueSpec[[]string]{
+			Key:         "HTTP_CORS_ALLOWED_METHODS",
+			Description: "Methods allowed by CORS preflights.",
+			Parse:       config.ParseCSV,
+		}, true),
+		config.WithTyped(TokenCORSAllowedHeaders, config.ValueSpec[[]string]{
+			Key:         "HTTP_CORS_ALLOWED_HEADERS",
+			Description: "Request headers allowed by CORS preflights; empty allows the requested headers.",
+			Parse:       config.ParseCSV,
+		}, true),
+		config.WithTyped(TokenCORSExposedHeaders, config.ValueSpec[[]string]{
+			Key:         "HTTP_CORS_EXPOSED_HEADERS",
+			Description: "Response headers exposed to CORS requests.",
+			Parse:       config.ParseCSV,
+		}, true),
+		config.WithTyped(TokenCORSAllowCredentials, config.ValueSpec[bool]{
+			Key:         "HTTP_CORS_ALLOW_CREDENTIALS",
+			Description: "Whether CORS requests may include credentials.",
+			Parse:       config.ParseBool,
+		}, true),
+		config.WithTyped(TokenCORSMaxAge, config.ValueSpec[time.Duration]{
+			Key:         "HTTP_CORS_MAX_AGE",
+			Description: "How long browsers may cache preflight results.",
+			Parse:       config.ParseDuration,
+		}, true),
+		config.WithTyped(TokenRateLimitPerSecond, config.ValueSpec[float64]{
+			Key:         "HTTP_RATE_LIMIT_PER_SECOND",
+			Description: "Requests per second allowed per key. 0 disables rate limiting.",
+			Parse:       config.ParseFloat64,
+		}, true),
+		config.WithTyped(TokenRateLimitBurst, config.ValueSpec[int]{
+			Key:         "HTTP_RATE_LIMIT_BURST",
+			Default:     &burst,
+			Description: "Requests allowed in a burst per key.",
+			Parse:       config.ParseInt,
+		}, true),
+		config.WithTyped(TokenRateLimitKey, config.ValueSpec[string]{
+			Key:         "HTTP_RATE_LIMIT_KEY",
+			Default:     &key,
+			Description: "Rate limit key: ip or principal.",
+			Parse:       parseRateLimitKey,
+		}, true),
+		config.WithTyped(TokenRequestTimeout, config.ValueSpec[time.Duration]{
+			Key:         "HTTP_REQUEST_TIMEOUT",
+			Description: "Request context timeout. 0 disables the timeout.",
+			Parse:       config.ParseDuration,
+		}, true),
+		config.WithTyped(TokenMaxBodyBytes, config.ValueSpec[int64]{
+			Key:         "HTTP_MAX_BODY_BYTES",
+			Description: "Maximum request body size in bytes. 0 disables the limit.",
+			Parse:       config.ParseInt64,
+		}, true),
+		config.WithTyped(TokenSecurityHeadersEnabled, config.ValueSpec[bool]{
+			Key:         "HTTP_SECURITY_HEADERS",
+			Default:     &securityHeaders,
+			Description: "Whether security headers are sent.",
+			Parse:       config.ParseBool,
+		}, true),
+		config.WithTyped(TokenContentSecurityPolicy, config.ValueSpec[string]{
+			Key:         "HTTP_CONTENT_SECURITY_POLICY",
+			Default:     &csp,
+			Description: "Content-Security-Policy header value.",
+			Parse:       config.ParseString,
+		}, true),
+		config.WithTyped(TokenHSTSMaxAge, config.ValueSpec[time.Duration]{
+			Key:         "HTTP_HSTS_MAX_AGE",
+			Description: "Strict-Transport-Security max-age for TLS requests. 0 omits the header.",
+			Parse:       config.ParseDuration,
+		}, true),
+	}
+	return config.NewModule(append(options, opts...)...)
+}
+
+func parseRateLimitKey(raw string) (string, error) {
+	switch raw {
+	case "ip", "principal":
+		return raw, nil
+	default:
+		return "", fmt.Errorf("must be ip or principal, got %q", raw)
+	}
+}
+
+// MiddlewareOptions configures NewMiddlewareModule.
+type MiddlewareOptions struct {
+	// Config provides the middleware config tokens (default
+	// MiddlewareConfigModule()).
+	Config module.Module
+}
+
+// MiddlewareModule provides the built-in middleware configured from config
+// tokens.
+type MiddlewareModule struct {
+	opts MiddlewareOptions
+}
+
+// NewMiddlewareModule constructs a module exporting the middleware tokens. Import
+// it and list the tokens in module.HTTPDef.Middleware, for example CORS, rate
+// limit, security headers, max body size, then timeout.
+func NewMiddlewareModule(opts MiddlewareOptions) module.Module {
+	if opts.Config == nil {
+		opts.Config = MiddlewareConfigModule()
+	}
+	return &MiddlewareModule{opts: opts}
+}
+
+// Definition returns the module definition for graph construction.
+func (m *MiddlewareModule) Definition() module.ModuleDef {
+	configMod := m.opts.Config
+	if configMod == nil {
+		configMod = MiddlewareConfigModule()
+	}
+
+	return module.ModuleDef{
+		Name:    middlewareModuleName,
+		Imports: []module.Module{configMod},
+		Providers: []module.ProviderDef{
+			{Token: TokenCORSMiddleware, Build: buildCORS},
+			{Token: TokenRateLimiter, Build: buildRateLimiter},
+			{
+				Token: TokenRateLimitMiddleware,
+				Build: func(r module.Resolver) (any, error) {
+					limiter, err := module.Get[*RateLimiter](r, TokenRateLimiter)
+					if err != nil {
+						return nil, err
+					}
+					return limiter.Middleware, nil
+				},
+			},
+			{
+				Token: TokenTimeoutMiddleware,
+				Build: func(r module.Resolver) (any, error) {
+					timeout, err := module.Get[time.Duration](r, TokenRequestTimeout)
+					if err != nil {
+						return nil, err
+					}
+					return Timeout(timeout), nil
+				},
+			},
+			{
+				Token: TokenMaxBodySizeMiddleware,
+				Build: func(r module.Resolver) (any, error) {
+					limit, err := module.Get[int64](r, TokenMaxBodyBytes)
+					if err != nil {
+						return nil, err
+					}
+					return MaxBodySize(limit), nil
+				},
+			},
+			{Token: TokenSecurityHeadersMiddleware, Build: buildSecurityHeaders},
+		},
+		Exports: []module.Token{
+			TokenCORSMiddleware,
+			TokenRateLimiter,
+			TokenRateLimitMiddleware,
+			TokenTimeoutMiddleware,
+			TokenMaxBodySizeMiddleware,
+			TokenSecurityHeadersMiddleware,
+		},
+	}
+}
+
+func buildCORS(r module.Resolver) (any, error) {
+	var cfg CORSConfig
+	var err error
+	if cfg.AllowedOrigins, err = module.Get[[]string](r, TokenCORSAllowedOrigins); err != nil {
+		return nil, err
+	}
+	if cfg.AllowedMethods, err = module.Get[[]string](r, TokenCORSAllowedMethods); err != nil {
+		return nil, err
+	}
+	if cfg.AllowedHeaders, err = module.Get[[]string](r, TokenCORSAllowedHeaders); err != nil {
+		return nil, err
+	}
+	if cfg.ExposedHeaders, err = module.Get[[]string](r, TokenCORSExposedHeaders); err != nil {
+		return nil, err
+	}
+	if cfg.AllowCredentials, err = module.Get[bool](r, TokenCORSAllowCredentials); err != nil {
+		return nil, err
+	}
+	if cfg.MaxAge, err = module.Get[time.Duration](r, TokenCORSMaxAge); err != nil {
+		return nil, err
+	}
+	return CORS(cfg), nil
+}
+
+func buildRateLimiter(r module.Resolver) (any, error) {
+	var cfg RateLimitConfig
+	var err error
+	if cfg.RequestsPerSecond, err = module.Get[float64](r, TokenRateLimitPerSecond); err != nil {
+		return nil, err
+	}
+	if cfg.Burst, err = module.Get[int](r, TokenRateLimitBurst); err != nil {
+		return nil, err
+	}
+	key, err := module.Get[string](r, TokenRateLimitKey)
+	if err != nil {
+		return nil, err
+	}
+	if key == "principal" {
+		cfg.Key = RateLimitByPrincipal
+	}
+	return NewRateLimiter(cfg), nil
+}
+
+func buildSecurityHeaders(r module.Resolver) (any, error) {
+	enabled, err := module.Get[bool](r, TokenSecurityHeadersEnabled)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return func(next http.Handler) http.Handler { return next }, nil
+	}
+	cfg := DefaultSecurityHeaders()
+	if cfg.ContentSecurityPolicy, err = module.Get[string](r, TokenContentSecurityPolicy); err != nil {
+		return nil, err
+	}
+	if cfg.HSTSMaxAge, err = module.Get[time.Duration](r, TokenHSTSMaxAge); err != nil {
+		return nil, err
+	}
+	return SecurityHeaders(cfg), nil
+}
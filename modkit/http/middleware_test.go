@@ -1,9 +1,14 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"github.com/go-modkit/modkit/modkit/config"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
 )
 
 func TestMiddlewareRecoverer_HandlesPanic(t *testing.T) {
@@ -46,3 +51,78 @@ func TestMiddlewareStopsChainWhenHandlingResponse(t *testing.T) {
 		t.Fatalf("expected handler not to run")
 	}
 }
+
+type mapSource map[string]string
+
+func (s mapSource) Lookup(key string) (string, bool) {
+	value, ok := s[key]
+	return value, ok
+}
+
+func bootstrapMiddleware(t *testing.T, env map[string]string, tokens ...module.Token) (*kernel.App, error) {
+	t.Helper()
+	middleware := NewMiddlewareModule(MiddlewareOptions{Config: MiddlewareConfigModule(config.WithSource(mapSource(env)))})
+	return kernel.Bootstrap(&mountModule{def: module.ModuleDef{
+		Name:        "app",
+		Imports:     []module.Module{middleware},
+		Controllers: []module.ControllerDef{pathController("AppController", "/items", new([]string))},
+		HTTP:        module.HTTPDef{Prefix: "/api", Middleware: tokens},
+	}})
+}
+
+func TestMiddlewareModule_AppliesConfiguredMiddleware(t *testing.T) {
+	app, err := bootstrapMiddleware(t, map[string]string{
+		"HTTP_CORS_ALLOWED_ORIGINS":  "https://app.example.com",
+		"HTTP_RATE_LIMIT_PER_SECOND": "1",
+		"HTTP_MAX_BODY_BYTES":        "16",
+	}, TokenCORSMiddleware, TokenRateLimitMiddleware, TokenSecurityHeadersMiddleware, TokenMaxBodySizeMiddleware, TokenTimeoutMiddleware)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	mux := NewServeMux()
+	if err := RegisterApp(mux, app); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/items", http.NoBody)
+	req.Header.Set("Origin", "https://app.example.com")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("expected CORS response, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("expected security headers by default, got %v", rec.Header())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/items", http.NoBody))
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("expected rate limit, got %d %v", rec.Code, rec.Header())
+	}
+
+	limiter, err := module.Get[*RateLimiter](mustResolver(t, app), TokenRateLimiter)
+	if err != nil || limiter == nil {
+		t.Fatalf("expected exported rate limiter, got %v", err)
+	}
+}
+
+func TestMiddlewareModule_InvalidRateLimitKey(t *testing.T) {
+	app, err := bootstrapMiddleware(t, map[string]string{"HTTP_RATE_LIMIT_KEY": "session"}, TokenRateLimitMiddleware)
+	if err == nil {
+		err = RegisterApp(NewServeMux(), app)
+	}
+	var parseErr *config.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Key != "HTTP_RATE_LIMIT_KEY" {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func mustResolver(t *testing.T, app *kernel.App) module.Resolver {
+	t.Helper()
+	resolver, err := app.ModuleResolver("app")
+	if err != nil {
+		t.Fatalf("resolver: %v", err)
+	}
+	return resolver
+}
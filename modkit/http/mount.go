@@ -0,0 +1,172 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+)
+
+// RegisterApp registers the controllers of app in module graph order. Modules
+// declaring ModuleDef.HTTP mount their controllers, and those of the modules they
+// import, under their prefix and middleware, nesting through imports: with
+// "api" (prefix /api) importing "users" (prefix /users), a users controller
+// route "/{id}" is served at "/api/users/{id}". Middleware tokens are resolved
+// from the declaring module. A module with controllers that is reachable through
+// differently mounted importers returns a ModuleMountConflictError.
+func RegisterApp(router Router, app *kernel.App) error {
+	if app == nil {
+		return kernel.ErrNilApp
+	}
+	if app.Graph == nil {
+		return RegisterRoutes(router, app.Controllers)
+	}
+
+	chains, err := moduleMounts(app.Graph)
+	if err != nil {
+		return err
+	}
+
+	root := &mountNode{}
+	nodes := map[string]*mountNode{}
+	for _, ref := range app.ControllerRefs() {
+		node := root
+		for i, name := range chains[ref.Module] {
+			key := fmt.Sprint(chains[ref.Module][:i+1])
+			child, ok := nodes[key]
+			if !ok {
+				child, err = newMountNode(app, name)
+				if err != nil {
+					return err
+				}
+				nodes[key] = child
+				node.children = append(node.children, child)
+			}
+			node = child
+		}
+		node.controllers = append(node.controllers, ref)
+	}
+	return root.register(router)
+}
+
+type mountNode struct {
+	prefix      string
+	middleware  []func(http.Handler) http.Handler
+	controllers []kernel.ControllerRef
+	children    []*mountNode
+}
+
+func newMountNode(app *kernel.App, moduleName string) (*mountNode, error) {
+	def := app.Graph.Nodes[moduleName].Def.HTTP
+	node := &mountNode{prefix: def.Prefix}
+	if len(def.Middleware) == 0 {
+		return node, nil
+	}
+
+	resolver, err := app.ModuleResolver(moduleName)
+	if err != nil {
+		return nil, err
+	}
+	for _, token := range def.Middleware {
+		value, err := resolver.Get(token)
+		if err != nil {
+			return nil, err
+		}
+		middleware, ok := value.(func(http.Handler) http.Handler)
+		if !ok {
+			return nil, &ModuleMiddlewareTypeError{Module: moduleName, Token: token, Type: fmt.Sprintf("%T", value)}
+		}
+		node.middleware = append(node.middleware, middleware)
+	}
+	return node, nil
+}
+
+// register adds the node's controllers, then its children. A child's middleware
+// is used on its mounted sub-router so it also runs for unmatched paths under the
+// prefix (for example CORS preflights). Children sharing a prefix are mounted
+// once, each in an inline group for its middleware.
+func (n *mountNode) register(router Router) error {
+	if err := registerControllers(router, n.controllers); err != nil {
+		return err
+	}
+
+	var prefixes []string
+	shared := make(map[string]int)
+	for _, child := range n.children {
+		if shared[child.prefix] == 0 {
+			prefixes = append(prefixes, child.prefix)
+		}
+		shared[child.prefix]++
+	}
+
+	var err error
+	for _, prefix := range prefixes {
+		mount := func(r Router) {
+			for _, child := range n.children {
+				if child.prefix != prefix || err != nil {
+					continue
+				}
+				if prefix != "" && shared[prefix] == 1 {
+					r.Use(child.middleware...)
+					err = child.register(r)
+					continue
+				}
+				r.Group("", func(inner Router) {
+					inner.Use(child.middleware...)
+					err = child.register(inner)
+				})
+			}
+		}
+		if prefix == "" {
+			mount(router)
+		} else {
+			router.Group(prefix, mount)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moduleMounts returns, for every module reachable from the root, the chain of
+// modules declaring ModuleDef.HTTP from the root down to it.
+func moduleMounts(graph *kernel.Graph) (map[string][]string, error) {
+	chains := make(map[string][]string, len(graph.Modules))
+	visited := make(map[string]bool)
+
+	var visit func(name string, chain []string) error
+	visit = func(name string, chain []string) error {
+		node := graph.Nodes[name]
+		if node == nil {
+			return nil
+		}
+		if node.Def.HTTP.Prefix != "" || len(node.Def.HTTP.Middleware) > 0 {
+			chain = append(slices.Clip(chain), name)
+		}
+
+		key := name + "\x00" + fmt.Sprint(chain)
+		if visited[key] {
+			return nil
+		}
+		visited[key] = true
+
+		if existing, ok := chains[name]; !ok {
+			chains[name] = chain
+		} else if !slices.Equal(existing, chain) && len(node.Def.Controllers) > 0 {
+			return &ModuleMountConflictError{Module: name, Mounts: [][]string{existing, chain}}
+		}
+		for _, imported := range node.Imports {
+			if err := visit(imported, chain); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := visit(graph.Root, nil); err != nil {
+		return nil, err
+	}
+	return chains, nil
+}
@@ -0,0 +1,258 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+type mountModule struct {
+	def module.ModuleDef
+}
+
+func (m *mountModule) Definition() module.ModuleDef {
+	return m.def
+}
+
+func headerMiddleware(value string) module.ProviderDef {
+	return module.ProviderDef{
+		Token: module.Token("mw." + value),
+		Build: func(module.Resolver) (any, error) {
+			return func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("X-Chain", value)
+					next.ServeHTTP(w, r)
+				})
+			}, nil
+		},
+	}
+}
+
+func pathController(name, pattern string, order *[]string) module.ControllerDef {
+	return module.ControllerDef{
+		Name: name,
+		Build: func(module.Resolver) (any, error) {
+			return &routesController{register: func(r Router) {
+				*order = append(*order, name)
+				r.Handle(http.MethodGet, pattern, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					_, _ = w.Write([]byte(name))
+				}))
+			}}, nil
+		},
+	}
+}
+
+func TestRegisterApp_NestsPrefixesAndMiddleware(t *testing.T) {
+	var order []string
+	users := &mountModule{def: module.ModuleDef{
+		Name:        "users",
+		Providers:   []module.ProviderDef{headerMiddleware("users")},
+		Controllers: []module.ControllerDef{pathController("UsersController", "/{id}", &order)},
+		HTTP:        module.HTTPDef{Prefix: "/users", Middleware: []module.Token{"mw.users"}},
+	}}
+	health := &mountModule{def: module.ModuleDef{
+		Name:        "health",
+		Controllers: []module.ControllerDef{pathController("HealthController", "/health", &order)},
+	}}
+	api := &mountModule{def: module.ModuleDef{
+		Name:      "api",
+		Imports:   []module.Module{users},
+		Providers: []module.ProviderDef{headerMiddleware("api")},
+		HTTP:      module.HTTPDef{Prefix: "/api", Middleware: []module.Token{"mw.api"}},
+	}}
+	root := &mountModule{def: module.ModuleDef{
+		Name:        "app",
+		Imports:     []module.Module{health, api},
+		Controllers: []module.ControllerDef{pathController("AppController", "/", &order)},
+	}}
+
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		order = nil
+		if err := RegisterApp(router, app); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if want := []string{"HealthController", "AppController", "UsersController"}; !reflect.DeepEqual(order, want) {
+			t.Fatalf("expected graph order %v, got %v", want, order)
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/users/7", nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "UsersController" {
+			t.Fatalf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Values("X-Chain"); !reflect.DeepEqual(got, []string{"api", "users"}) {
+			t.Fatalf("expected outer middleware first, got %v", got)
+		}
+
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		if rec.Code != http.StatusOK || len(rec.Header().Values("X-Chain")) != 0 {
+			t.Fatalf("expected unmounted route without middleware, got %d %v", rec.Code, rec.Header())
+		}
+
+		var patterns []string
+		for _, route := range Routes(router) {
+			patterns = append(patterns, route.Pattern+" "+route.Controller)
+			if route.Controller == "users:UsersController" && route.Middleware != 2 {
+				t.Fatalf("expected 2 middlewares, got %+v", route)
+			}
+		}
+		want := []string{"/ app:AppController", "/api/users/{id} users:UsersController", "/health health:HealthController"}
+		if !reflect.DeepEqual(patterns, want) {
+			t.Fatalf("unexpected routes: %v", patterns)
+		}
+	})
+}
+
+func TestRegisterApp_MiddlewareOnlyModule(t *testing.T) {
+	var order []string
+	admin := &mountModule{def: module.ModuleDef{
+		Name:        "admin",
+		Providers:   []module.ProviderDef{headerMiddleware("admin")},
+		Controllers: []module.ControllerDef{pathController("AdminController", "/admin", &order)},
+		HTTP:        module.HTTPDef{Middleware: []module.Token{"mw.admin"}},
+	}}
+	root := &mountModule{def: module.ModuleDef{
+		Name:        "app",
+		Imports:     []module.Module{admin},
+		Controllers: []module.ControllerDef{pathController("AppController", "/", &order)},
+	}}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		if err := RegisterApp(router, app); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for path, want := range map[string]int{"/admin": 1, "/": 0} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != http.StatusOK || len(rec.Header().Values("X-Chain")) != want {
+				t.Fatalf("%s: expected %d middleware headers, got %d %v", path, want, rec.Code, rec.Header())
+			}
+		}
+	})
+}
+
+func TestRegisterApp_SharedPrefix(t *testing.T) {
+	var order []string
+	a := &mountModule{def: module.ModuleDef{
+		Name:        "a",
+		Controllers: []module.ControllerDef{pathController("A", "/a", &order)},
+		HTTP:        module.HTTPDef{Prefix: "/v1"},
+	}}
+	b := &mountModule{def: module.ModuleDef{
+		Name:        "b",
+		Controllers: []module.ControllerDef{pathController("B", "/b", &order)},
+		HTTP:        module.HTTPDef{Prefix: "/v1"},
+	}}
+	app, err := kernel.Bootstrap(&mountModule{def: module.ModuleDef{Name: "app", Imports: []module.Module{a, b}}})
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		if err := RegisterApp(router, app); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, path := range []string{"/v1/a", "/v1/b"} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: expected 200, got %d", path, rec.Code)
+			}
+		}
+	})
+}
+
+func TestRegisterApp_MountConflict(t *testing.T) {
+	var order []string
+	shared := &mountModule{def: module.ModuleDef{
+		Name:        "shared",
+		Controllers: []module.ControllerDef{pathController("Shared", "/shared", &order)},
+	}}
+	api := &mountModule{def: module.ModuleDef{
+		Name:    "api",
+		Imports: []module.Module{shared},
+		HTTP:    module.HTTPDef{Prefix: "/api"},
+	}}
+	app, err := kernel.Bootstrap(&mountModule{def: module.ModuleDef{Name: "app", Imports: []module.Module{shared, api}}})
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+
+	err = RegisterApp(AsRouter(chi.NewRouter()), app)
+	var conflict *ModuleMountConflictError
+	if !errors.As(err, &conflict) || conflict.Module != "shared" {
+		t.Fatalf("expected ModuleMountConflictError, got %v", err)
+	}
+	if !reflect.DeepEqual(conflict.Mounts, [][]string{nil, {"api"}}) {
+		t.Fatalf("unexpected mounts: %v", conflict.Mounts)
+	}
+}
+
+func TestRegisterApp_MiddlewareTypeError(t *testing.T) {
+	root := &mountModule{def: module.ModuleDef{
+		Name: "app",
+		Providers: []module.ProviderDef{{
+			Token: "mw.bad",
+			Build: func(module.Resolver) (any, error) { return "not middleware", nil },
+		}},
+		Controllers: []module.ControllerDef{pathController("App", "/", new([]string))},
+		HTTP:        module.HTTPDef{Middleware: []module.Token{"mw.bad"}},
+	}}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+
+	err = RegisterApp(AsRouter(chi.NewRouter()), app)
+	var typeErr *ModuleMiddlewareTypeError
+	if !errors.As(err, &typeErr) || typeErr.Token != "mw.bad" || !strings.Contains(err.Error(), "string") {
+		t.Fatalf("expected ModuleMiddlewareTypeError, got %v", err)
+	}
+}
+
+func TestRegisterApp_NilApp(t *testing.T) {
+	if err := RegisterApp(AsRouter(chi.NewRouter()), nil); !errors.Is(err, kernel.ErrNilApp) {
+		t.Fatalf("expected ErrNilApp, got %v", err)
+	}
+}
+
+func TestRegisterApp_MiddlewareRunsForUnmatchedMethods(t *testing.T) {
+	users := &mountModule{def: module.ModuleDef{
+		Name:        "users",
+		Providers:   []module.ProviderDef{headerMiddleware("users")},
+		Controllers: []module.ControllerDef{pathController("UsersController", "/", new([]string))},
+		HTTP:        module.HTTPDef{Prefix: "/users", Middleware: []module.Token{"mw.users"}},
+	}}
+	app, err := kernel.Bootstrap(&mountModule{def: module.ModuleDef{Name: "app", Imports: []module.Module{users}}})
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		if err := RegisterApp(router, app); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/users", nil))
+		if got := rec.Header().Values("X-Chain"); !reflect.DeepEqual(got, []string{"users"}) {
+			t.Fatalf("expected module middleware on preflight, got %d %v", rec.Code, got)
+		}
+	})
+}
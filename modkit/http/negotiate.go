@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Encoder writes response values in one media type.
+type Encoder interface {
+	// MediaType is the type sent as Content-Type, for example
+	// "application/json".
+	MediaType() string
+	Encode(w io.Writer, v any) error
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) MediaType() string { return "application/json" }
+
+func (jsonEncoder) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) MediaType() string { return "application/xml" }
+
+func (xmlEncoder) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// JSONEncoder encodes values with encoding/json. It is the default encoder.
+func JSONEncoder() Encoder { return jsonEncoder{} }
+
+// XMLEncoder encodes values with encoding/xml.
+func XMLEncoder() Encoder { return xmlEncoder{} }
+
+// Negotiator chooses a response encoder by the Accept header.
+type Negotiator struct {
+	encoders []Encoder
+}
+
+// NewNegotiator returns a negotiator over encoders, in order of server
+// preference. Without encoders it only offers JSONEncoder.
+func NewNegotiator(encoders ...Encoder) *Negotiator {
+	if len(encoders) == 0 {
+		encoders = []Encoder{JSONEncoder()}
+	}
+	return &Negotiator{encoders: encoders}
+}
+
+var defaultNegotiator = NewNegotiator()
+
+// Encoders returns the encoders of the negotiator.
+func (n *Negotiator) Encoders() []Encoder {
+	return append([]Encoder(nil), n.encoders...)
+}
+
+// Negotiate returns the encoder best matching the Accept header of r: the one
+// with the highest quality, then the most specific media range, then the first
+// registered. Requests without Accept get the first encoder. When nothing is
+// acceptable it returns a 406 *Problem.
+func (n *Negotiator) Negotiate(r *http.Request) (Encoder, error) {
+	accept := strings.Join(r.Header.Values("Accept"), ",")
+	if strings.TrimSpace(accept) == "" {
+		return n.encoders[0], nil
+	}
+	ranges := parseAccept(accept)
+
+	var (
+		best            Encoder
+		bestQ           float64
+		bestSpecificity int
+	)
+	for _, encoder := range n.encoders {
+		q, specificity := matchAccept(ranges, encoder.MediaType())
+		if q <= 0 {
+			continue
+		}
+		if best == nil || q > bestQ || (q == bestQ && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = encoder, q, specificity
+		}
+	}
+	if best == nil {
+		offered := make([]string, len(n.encoders))
+		for i, encoder := range n.encoders {
+			offered[i] = encoder.MediaType()
+		}
+		return nil, NewProblem(http.StatusNotAcceptable, "acceptable media types: "+strings.Join(offered, ", "))
+	}
+	return best, nil
+}
+
+// Write encodes v with the negotiated encoder and writes it with status. When
+// nothing is acceptable it writes the 406 problem instead; encoding failures
+// are written through WriteError before anything is sent.
+func (n *Negotiator) Write(w http.ResponseWriter, r *http.Request, status int, v any) {
+	encoder, err := n.Negotiate(r)
+	if err != nil {
+		WriteError(w, r, err)
+		return
+	}
+	n.write(w, r, encoder, status, v, nil)
+}
+
+func (n *Negotiator) write(w http.ResponseWriter, r *http.Request, encoder Encoder, status int, v any, mapper ErrorMapper) {
+	var buf bytes.Buffer
+	if err := encoder.Encode(&buf, v); err != nil {
+		WriteProblem(w, r, mapError(mapper, r, err))
+		return
+	}
+	if len(n.encoders) > 1 {
+		w.Header().Add("Vary", "Accept")
+	}
+	w.Header().Set("Content-Type", encoder.MediaType())
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+type negotiatorKey struct{}
+
+// ContentNegotiation returns middleware that makes Respond and handlers built
+// with Handle choose among encoders by the Accept header.
+func ContentNegotiation(encoders ...Encoder) func(http.Handler) http.Handler {
+	negotiator := NewNegotiator(encoders...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithNegotiator(r.Context(), negotiator)))
+		})
+	}
+}
+
+// WithNegotiator returns a copy of ctx carrying n.
+func WithNegotiator(ctx context.Context, n *Negotiator) context.Context {
+	return context.WithValue(ctx, negotiatorKey{}, n)
+}
+
+// NegotiatorFromContext returns the negotiator set by ContentNegotiation, or one
+// offering only JSON.
+func NegotiatorFromContext(ctx context.Context) *Negotiator {
+	if n, ok := ctx.Value(negotiatorKey{}).(*Negotiator); ok {
+		return n
+	}
+	return defaultNegotiator
+}
+
+// Respond writes v with status, encoded by the negotiator of the request (see
+// ContentNegotiation). It writes a 406 problem when no encoder is acceptable.
+func Respond(w http.ResponseWriter, r *http.Request, status int, v any) {
+	NegotiatorFromContext(r.Context()).Write(w, r, status, v)
+}
+
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		if mediaType == "*" {
+			// Some clients send a bare "*" for "*/*".
+			mediaType = "*/*"
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil || parsed < 0 || parsed > 1 {
+				continue
+			}
+			q = parsed
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q})
+	}
+	return ranges
+}
+
+// matchAccept returns the quality of the most specific range matching
+// mediaType, and that specificity: 2 for an exact match, 1 for type/*, 0 for
+// */*. The quality is 0 when no range matches.
+func matchAccept(ranges []acceptRange, mediaType string) (q float64, specificity int) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	specificity = -1
+	for _, ar := range ranges {
+		s := -1
+		switch {
+		case strings.EqualFold(ar.typ, typ) && strings.EqualFold(ar.subtype, subtype):
+			s = 2
+		case strings.EqualFold(ar.typ, typ) && ar.subtype == "*":
+			s = 1
+		case ar.typ == "*" && ar.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = ar.q, s
+		}
+	}
+	return q, specificity
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiator_ChoosesByAccept(t *testing.T) {
+	n := NewNegotiator(JSONEncoder(), XMLEncoder())
+	cases := []struct {
+		accept string
+		want   string
+	}{
+		{"", "application/json"},
+		{"application/xml", "application/xml"},
+		{"application/json;q=0.5, application/xml", "application/xml"},
+		{"text/html, application/*;q=0.8", "application/json"},
+		{"*/*", "application/json"},
+		{"*", "application/json"},
+		{"application/*;q=0.9, application/xml;q=0.9", "application/xml"},
+		{"application/json;q=0, */*;q=0.1", "application/xml"},
+		{"application/json; v=2", "application/json"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		if tc.accept != "" {
+			req.Header.Set("Accept", tc.accept)
+		}
+		encoder, err := n.Negotiate(req)
+		if err != nil {
+			t.Fatalf("Accept %q: unexpected error %v", tc.accept, err)
+		}
+		if encoder.MediaType() != tc.want {
+			t.Fatalf("Accept %q: got %s, want %s", tc.accept, encoder.MediaType(), tc.want)
+		}
+	}
+}
+
+func TestNegotiator_NotAcceptable(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.Header.Set("Accept", "text/html, application/json;q=0")
+	rec := httptest.NewRecorder()
+	Respond(rec, req, http.StatusOK, map[string]string{"a": "b"})
+	if rec.Code != http.StatusNotAcceptable || rec.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected 406 problem, got %d %v", rec.Code, rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), "application/json") {
+		t.Fatalf("expected offered types in detail, got %s", rec.Body.String())
+	}
+}
+
+type negotiatedItem struct {
+	ID   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+func TestHandle_NegotiatesResponseEncoding(t *testing.T) {
+	var calls int
+	handler := ContentNegotiation(JSONEncoder(), XMLEncoder())(Handle(func(context.Context, struct{}) (negotiatedItem, error) {
+		calls++
+		return negotiatedItem{ID: 1, Name: "a"}, nil
+	}))
+
+	serve := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("application/xml")
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/xml" || rec.Header().Get("Vary") != "Accept" {
+		t.Fatalf("unexpected XML response: %d %v", rec.Code, rec.Header())
+	}
+	if !strings.Contains(rec.Body.String(), "<negotiatedItem><id>1</id><name>a</name></negotiatedItem>") {
+		t.Fatalf("unexpected XML body: %s", rec.Body.String())
+	}
+	rec = serve("application/json")
+	if rec.Header().Get("Content-Type") != "application/json" || strings.TrimSpace(rec.Body.String()) != `{"id":1,"name":"a"}` {
+		t.Fatalf("unexpected JSON response: %v %s", rec.Header(), rec.Body.String())
+	}
+
+	rec = serve("text/csv")
+	if rec.Code != http.StatusNotAcceptable || calls != 2 {
+		t.Fatalf("expected 406 before the handler runs, got %d after %d calls", rec.Code, calls)
+	}
+}
+
+func TestHandle_WithEncodersRecordsMediaTypes(t *testing.T) {
+	handler := Handle(func(context.Context, struct{}) (negotiatedItem, error) {
+		return negotiatedItem{}, nil
+	}, WithEncoders(XMLEncoder()))
+
+	types := handler.(TypedHandler).HandlerTypes()
+	if len(types.MediaTypes) != 1 || types.MediaTypes[0] != "application/xml" {
+		t.Fatalf("unexpected media types: %v", types.MediaTypes)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Header().Get("Content-Type") != "application/xml" {
+		t.Fatalf("expected XML without Accept, got %v", rec.Header())
+	}
+}
@@ -0,0 +1,309 @@
+// Package openapi generates OpenAPI 3.1 documents from the routes recorded by
+// the modkit/http router adapter.
+package openapi
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// DefaultPath is where Serve mounts the document when Config.Path is empty.
+const DefaultPath = "/openapi.json"
+
+// Config describes the API in the generated document.
+type Config struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []Server
+	// Path is where Serve mounts the document (default DefaultPath).
+	Path string
+	// APIVersion limits the document to routes of one API version and
+	// version-neutral routes; empty includes every version.
+	APIVersion string
+}
+
+// Document is an OpenAPI 3.1 document.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info is the OpenAPI info object.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server is the OpenAPI server object.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag is the OpenAPI tag object.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps lower-case HTTP methods to operations.
+type PathItem map[string]*Operation
+
+// Operation is the OpenAPI operation object.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	// APIVersion is the route's API version, as the x-api-version extension.
+	APIVersion string `json:"x-api-version,omitempty"`
+}
+
+// Parameter is the OpenAPI parameter object.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the OpenAPI request body object.
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is the OpenAPI response object.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is the OpenAPI media type object.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the reusable schemas and security schemes.
+type Components struct {
+	Schemas         map[string]*Schema               `json:"schemas,omitempty"`
+	SecuritySchemes map[string]mkhttp.SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// Build generates a document from routes, typically Routes(router) after
+// RegisterRoutes. Hidden routes are skipped. Operations are tagged with the
+// module part of their controller key, list parameters and schemas for handlers
+// built with Handle, and require the security schemes of their guards unless
+// the route is public. Versioned routes carry an x-api-version extension; when
+// versions share a path and method (header or media type versioning), the first
+// in routes is documented unless Config.APIVersion selects one; for
+// Routes(router) that is the lowest version, "v2" before "v10".
+func Build(routes []mkhttp.RouteInfo, cfg Config) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: cfg.Title, Version: cfg.Version, Description: cfg.Description},
+		Servers: cfg.Servers,
+		Paths:   make(map[string]PathItem),
+	}
+	if doc.Info.Title == "" {
+		doc.Info.Title = "API"
+	}
+	if doc.Info.Version == "" {
+		doc.Info.Version = "1.0.0"
+	}
+
+	gen := newSchemaGenerator()
+	tags := make(map[string]bool)
+	for _, route := range routes {
+		if route.Meta.Hidden {
+			continue
+		}
+		if cfg.APIVersion != "" && route.Version != "" && route.Version != cfg.APIVersion {
+			continue
+		}
+		path, pathParams := convertPattern(route.Pattern)
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		method := strings.ToLower(route.Method)
+		if _, ok := item[method]; ok {
+			continue
+		}
+		op := buildOperation(gen, route, path, pathParams)
+		item[method] = op
+		for _, tag := range op.Tags {
+			tags[tag] = true
+		}
+		for _, scheme := range route.Security {
+			if doc.Components.SecuritySchemes == nil {
+				doc.Components.SecuritySchemes = make(map[string]mkhttp.SecurityScheme)
+			}
+			doc.Components.SecuritySchemes[scheme.Key] = scheme
+		}
+	}
+
+	doc.Components.Schemas = gen.components
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+	return doc
+}
+
+func buildOperation(gen *schemaGenerator, route mkhttp.RouteInfo, path string, pathParams []string) *Operation {
+	op := &Operation{
+		OperationID: operationID(route.Method, path),
+		Summary:     route.Meta.Summary,
+		Responses:   make(map[string]*Response),
+		Deprecated:  route.Meta.Deprecation != nil,
+		APIVersion:  route.Version,
+	}
+	if module, _, ok := strings.Cut(route.Controller, ":"); ok && module != "" {
+		op.Tags = []string{module}
+	}
+
+	seen := make(map[string]bool)
+	if route.Types != nil {
+		for _, param := range route.Types.Params {
+			seen[param.In+" "+param.Name] = true
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     param.Name,
+				In:       param.In,
+				Required: param.In == "path",
+				Schema:   gen.schema(param.Type),
+			})
+		}
+	}
+	for _, name := range pathParams {
+		if seen["path "+name] {
+			continue
+		}
+		op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+
+	secured := !route.Meta.Public && len(route.Security) > 0
+	if secured {
+		requirement := make(map[string][]string, len(route.Security))
+		for _, scheme := range route.Security {
+			requirement[scheme.Key] = append([]string{}, route.Meta.Roles...)
+		}
+		op.Security = []map[string][]string{requirement}
+	}
+
+	if route.Types == nil {
+		op.Responses["default"] = &Response{Description: "Unspecified response"}
+		return op
+	}
+
+	if route.Method != http.MethodGet && route.Method != http.MethodHead {
+		if body := gen.bodySchema(route.Types.Request); body != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: body}},
+			}
+		}
+	}
+
+	status := route.Types.Status
+	success := &Response{Description: http.StatusText(status)}
+	if status != http.StatusNoContent {
+		mediaTypes := route.Types.MediaTypes
+		if len(mediaTypes) == 0 {
+			mediaTypes = []string{"application/json"}
+		}
+		schema := gen.schema(route.Types.Response)
+		success.Content = make(map[string]MediaType, len(mediaTypes))
+		for _, mediaType := range mediaTypes {
+			success.Content[mediaType] = MediaType{Schema: schema}
+		}
+	}
+	op.Responses[strconv.Itoa(status)] = success
+
+	problem := func(status int) *Response {
+		description := "Error"
+		if status != 0 {
+			description = http.StatusText(status)
+		}
+		return &Response{
+			Description: description,
+			Content:     map[string]MediaType{mkhttp.ProblemContentType: {Schema: gen.schema(problemType)}},
+		}
+	}
+	if len(op.Parameters) > 0 || op.RequestBody != nil {
+		op.Responses[strconv.Itoa(http.StatusBadRequest)] = problem(http.StatusBadRequest)
+	}
+	if secured {
+		op.Responses[strconv.Itoa(http.StatusUnauthorized)] = problem(http.StatusUnauthorized)
+		if len(route.Meta.Roles) > 0 {
+			op.Responses[strconv.Itoa(http.StatusForbidden)] = problem(http.StatusForbidden)
+		}
+	}
+	op.Responses["default"] = problem(0)
+	return op
+}
+
+// convertPattern turns a chi pattern into an OpenAPI path, dropping regexp
+// constraints, and returns the parameter names in order.
+func convertPattern(pattern string) (string, []string) {
+	var b strings.Builder
+	var params []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		end := strings.IndexByte(pattern, '}')
+		if start < 0 || end < start {
+			b.WriteString(pattern)
+			return b.String(), params
+		}
+		name := pattern[start+1 : end]
+		if colon := strings.IndexByte(name, ':'); colon >= 0 {
+			name = name[:colon]
+		}
+		name = strings.TrimSuffix(name, "...")
+		params = append(params, name)
+		b.WriteString(pattern[:start])
+		b.WriteString("{" + name + "}")
+		pattern = pattern[end+1:]
+	}
+}
+
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.Split(path, "/") {
+		part = strings.Trim(part, "{}")
+		if part == "" {
+			continue
+		}
+		b.WriteByte('_')
+		for _, r := range part {
+			if isNameRune(r) {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+	}
+	return b.String()
+}
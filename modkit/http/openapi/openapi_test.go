@@ -0,0 +1,302 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+type user struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Manager   *user     `json:"manager,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	internal  string
+}
+
+type createUserInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type getUserRequest struct {
+	ID int `path:"id"`
+}
+
+type listUsersRequest struct {
+	Page  int      `query:"page"`
+	Tags  []string `query:"tag"`
+	Trace string   `header:"X-Trace"`
+}
+
+type updateUserRequest struct {
+	ID int `path:"id"`
+	createUserInput
+}
+
+type usersController struct{}
+
+func (c *usersController) RegisterRoutes(router mkhttp.Router) {
+	router.Handle(http.MethodGet, "/users", mkhttp.Route(
+		mkhttp.Handle(func(context.Context, listUsersRequest) ([]user, error) { return nil, nil }),
+		mkhttp.Public(), mkhttp.Summary("List users"),
+	))
+	router.Handle(http.MethodPost, "/users", mkhttp.Route(
+		mkhttp.Handle(func(context.Context, createUserInput) (user, error) { return user{}, nil }, mkhttp.WithStatus(http.StatusCreated)),
+		mkhttp.Roles("admin"),
+	))
+	router.Handle(http.MethodGet, "/users/{id:[0-9]+}", mkhttp.Handle(func(context.Context, getUserRequest) (user, error) { return user{}, nil }))
+	router.Handle(http.MethodPut, "/users/{id}", mkhttp.Handle(func(context.Context, updateUserRequest) (user, error) { return user{}, nil }))
+	router.Handle(http.MethodDelete, "/users/{id}", mkhttp.Handle(func(context.Context, getUserRequest) (mkhttp.NoContent, error) {
+		return mkhttp.NoContent{}, nil
+	}))
+}
+
+type healthController struct{}
+
+func (c *healthController) RegisterRoutes(router mkhttp.Router) {
+	router.Handle(http.MethodGet, "/health/{check}", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+}
+
+func newTestRouter(t *testing.T) mkhttp.Router {
+	t.Helper()
+	router := mkhttp.AsRouter(chi.NewRouter())
+	mkhttp.UseGuards(router, mkhttp.BearerJWT(mkhttp.JWTConfig{Key: []byte("secret")}))
+	err := mkhttp.RegisterRoutes(router, map[string]any{
+		"users:UsersController":   &usersController{},
+		"health:HealthController": &healthController{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return router
+}
+
+func TestBuildPathsAndOperations(t *testing.T) {
+	doc := Build(mkhttp.Routes(newTestRouter(t)), Config{Title: "users", Version: "1.2.3"})
+
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "users" || doc.Info.Version != "1.2.3" {
+		t.Fatalf("unexpected header: %+v %+v", doc.OpenAPI, doc.Info)
+	}
+	if !reflect.DeepEqual(doc.Tags, []Tag{{Name: "health"}, {Name: "users"}}) {
+		t.Fatalf("unexpected tags: %+v", doc.Tags)
+	}
+	if len(doc.Paths) != 3 {
+		t.Fatalf("expected 3 paths, got %v", doc.Paths)
+	}
+
+	get := doc.Paths["/users/{id}"]["get"]
+	if get == nil {
+		t.Fatalf("expected GET /users/{id}, got %v", doc.Paths)
+	}
+	if get.OperationID != "get_users_id" || !reflect.DeepEqual(get.Tags, []string{"users"}) {
+		t.Fatalf("unexpected operation: %+v", get)
+	}
+	want := []Parameter{{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int64"}}}
+	if !reflect.DeepEqual(get.Parameters, want) {
+		t.Fatalf("unexpected parameters: %+v", get.Parameters)
+	}
+	if got := get.Responses["200"].Content["application/json"].Schema.Ref; got != "#/components/schemas/openapi.user" {
+		t.Fatalf("unexpected response schema: %q", got)
+	}
+	if got := get.Responses["default"].Content[mkhttp.ProblemContentType].Schema.Ref; got != "#/components/schemas/http.Problem" {
+		t.Fatalf("unexpected problem schema: %q", got)
+	}
+	if _, ok := get.Responses["400"]; !ok {
+		t.Fatalf("expected 400 response, got %v", get.Responses)
+	}
+
+	list := doc.Paths["/users"]["get"]
+	if list.Summary != "List users" || list.RequestBody != nil {
+		t.Fatalf("unexpected list operation: %+v", list)
+	}
+	if len(list.Parameters) != 3 || list.Parameters[1].Schema.Type != "array" || list.Parameters[2].In != "header" {
+		t.Fatalf("unexpected list parameters: %+v", list.Parameters)
+	}
+	if items := list.Responses["200"].Content["application/json"].Schema; items.Type != "array" || items.Items.Ref == "" {
+		t.Fatalf("unexpected list schema: %+v", items)
+	}
+
+	del := doc.Paths["/users/{id}"]["delete"]
+	if response := del.Responses["204"]; response == nil || response.Content != nil {
+		t.Fatalf("unexpected delete responses: %+v", del.Responses)
+	}
+
+	health := doc.Paths["/health/{check}"]["get"]
+	if len(health.Parameters) != 1 || health.Parameters[0].Schema.Type != "string" || health.Responses["default"] == nil {
+		t.Fatalf("unexpected untyped operation: %+v", health)
+	}
+}
+
+func TestBuildRequestBodies(t *testing.T) {
+	doc := Build(mkhttp.Routes(newTestRouter(t)), Config{})
+
+	create := doc.Paths["/users"]["post"]
+	if create.RequestBody == nil || create.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/openapi.createUserInput" {
+		t.Fatalf("unexpected create body: %+v", create.RequestBody)
+	}
+	if _, ok := create.Responses["201"]; !ok {
+		t.Fatalf("expected 201 response, got %v", create.Responses)
+	}
+
+	update := doc.Paths["/users/{id}"]["put"]
+	body := update.RequestBody.Content["application/json"].Schema
+	if body.Ref != "" || len(body.Properties) != 2 || body.Properties["id"] != nil {
+		t.Fatalf("expected inline body without path fields, got %+v", body)
+	}
+	if del := doc.Paths["/users/{id}"]["delete"]; del.RequestBody != nil {
+		t.Fatalf("expected no body for path-only request, got %+v", del.RequestBody)
+	}
+}
+
+func TestBuildResponseMediaTypes(t *testing.T) {
+	router := mkhttp.AsRouter(chi.NewRouter())
+	router.Handle(http.MethodGet, "/users/{id}", mkhttp.Handle(
+		func(context.Context, getUserRequest) (user, error) { return user{}, nil },
+		mkhttp.WithEncoders(mkhttp.JSONEncoder(), mkhttp.XMLEncoder()),
+	))
+	doc := Build(mkhttp.Routes(router), Config{})
+
+	content := doc.Paths["/users/{id}"]["get"].Responses["200"].Content
+	if len(content) != 2 || content["application/xml"].Schema == nil || content["application/json"].Schema == nil {
+		t.Fatalf("expected JSON and XML responses, got %+v", content)
+	}
+}
+
+func TestBuildSchemas(t *testing.T) {
+	doc := Build(mkhttp.Routes(newTestRouter(t)), Config{})
+
+	schema := doc.Components.Schemas["openapi.user"]
+	if schema == nil {
+		t.Fatalf("expected user component, got %v", doc.Components.Schemas)
+	}
+	if !reflect.DeepEqual(schema.Required, []string{"id", "name", "created_at"}) {
+		t.Fatalf("unexpected required: %v", schema.Required)
+	}
+	if _, ok := schema.Properties["internal"]; ok {
+		t.Fatalf("unexpected unexported field")
+	}
+	if got := schema.Properties["manager"].Ref; got != "#/components/schemas/openapi.user" {
+		t.Fatalf("expected recursive reference, got %q", got)
+	}
+	if got := schema.Properties["created_at"]; got.Type != "string" || got.Format != "date-time" {
+		t.Fatalf("unexpected time schema: %+v", got)
+	}
+	if doc.Components.Schemas["http.Problem"] == nil || doc.Components.Schemas["http.InvalidParam"] == nil {
+		t.Fatalf("expected problem components, got %v", doc.Components.Schemas)
+	}
+}
+
+func TestBuildSecurityFromGuards(t *testing.T) {
+	doc := Build(mkhttp.Routes(newTestRouter(t)), Config{})
+
+	scheme, ok := doc.Components.SecuritySchemes["bearerAuth"]
+	if !ok || scheme.Type != "http" || scheme.Scheme != "bearer" {
+		t.Fatalf("unexpected security schemes: %+v", doc.Components.SecuritySchemes)
+	}
+	create := doc.Paths["/users"]["post"]
+	if !reflect.DeepEqual(create.Security, []map[string][]string{{"bearerAuth": {"admin"}}}) {
+		t.Fatalf("unexpected security: %+v", create.Security)
+	}
+	if create.Responses["401"] == nil || create.Responses["403"] == nil {
+		t.Fatalf("expected 401 and 403 responses, got %v", create.Responses)
+	}
+	if list := doc.Paths["/users"]["get"]; list.Security != nil || list.Responses["401"] != nil {
+		t.Fatalf("expected public route without security, got %+v", list)
+	}
+
+	encoded, _ := json.Marshal(doc.Paths["/users/{id}"]["get"].Security)
+	if string(encoded) != `[{"bearerAuth":[]}]` {
+		t.Fatalf("unexpected encoded security: %s", encoded)
+	}
+}
+
+func TestBuildVersionedRoutes(t *testing.T) {
+	router := mkhttp.AsRouter(chi.NewRouter())
+	mkhttp.UseVersioning(router, mkhttp.Versioning{Type: mkhttp.VersionHeader})
+	noop := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	router.Handle(http.MethodGet, "/users", mkhttp.Route(noop, mkhttp.Versions("1"), mkhttp.Deprecated(mkhttp.Deprecation{})))
+	router.Handle(http.MethodGet, "/users", mkhttp.Route(noop, mkhttp.Versions("2"), mkhttp.Summary("v2")))
+	router.Handle(http.MethodGet, "/orders", mkhttp.Route(noop, mkhttp.Versions("10")))
+	router.Handle(http.MethodGet, "/orders", mkhttp.Route(noop, mkhttp.Versions("2")))
+	router.Handle(http.MethodGet, "/health", noop)
+
+	doc := Build(mkhttp.Routes(router), Config{})
+	if op := doc.Paths["/users"]["get"]; op.APIVersion != "1" || !op.Deprecated {
+		t.Fatalf("expected lowest version documented, got %+v", op)
+	}
+	if op := doc.Paths["/orders"]["get"]; op.APIVersion != "2" {
+		t.Fatalf("expected version 2 before 10, got %+v", op)
+	}
+
+	doc = Build(mkhttp.Routes(router), Config{APIVersion: "2"})
+	if op := doc.Paths["/users"]["get"]; op.APIVersion != "2" || op.Summary != "v2" || op.Deprecated {
+		t.Fatalf("expected version 2, got %+v", op)
+	}
+	if op := doc.Paths["/health"]["get"]; op == nil || op.APIVersion != "" {
+		t.Fatalf("expected neutral route, got %+v", op)
+	}
+	encoded, _ := json.Marshal(doc.Paths["/users"]["get"])
+	if !bytes.Contains(encoded, []byte(`"x-api-version":"2"`)) {
+		t.Fatalf("expected x-api-version extension, got %s", encoded)
+	}
+}
+
+func TestServe(t *testing.T) {
+	mux := chi.NewRouter()
+	router := mkhttp.AsRouter(mux)
+	Serve(router, Config{Path: "/docs/openapi.json", Title: "svc"})
+	router.Handle(http.MethodGet, "/ping", mkhttp.Handle(func(context.Context, struct{}) (string, error) { return "pong", nil }))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response: %d %v", rec.Code, rec.Header())
+	}
+	var doc Document
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if doc.Info.Title != "svc" || len(doc.Paths) != 1 || doc.Paths["/ping"] == nil {
+		t.Fatalf("expected only /ping, got %+v", doc.Paths)
+	}
+}
+
+func TestWriteFileIsStable(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.json")
+	second := filepath.Join(dir, "second.json")
+
+	if err := WriteFile(first, Build(mkhttp.Routes(newTestRouter(t)), Config{})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := WriteFile(second, Build(mkhttp.Routes(newTestRouter(t)), Config{})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	a, _ := os.ReadFile(first)
+	b, _ := os.ReadFile(second)
+	if len(a) == 0 || !bytes.Equal(a, b) {
+		t.Fatalf("expected identical output")
+	}
+}
+
+func TestConvertPattern(t *testing.T) {
+	path, params := convertPattern("/orgs/{org}/items/{id:[0-9]+}")
+	if path != "/orgs/{org}/items/{id}" || !reflect.DeepEqual(params, []string{"org", "id"}) {
+		t.Fatalf("unexpected conversion: %q %v", path, params)
+	}
+}
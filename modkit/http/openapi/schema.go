@@ -0,0 +1,238 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+// Schema is the subset of JSON Schema used for generated documents.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+var (
+	problemType       = reflect.TypeFor[mkhttp.Problem]()
+	timeType          = reflect.TypeFor[time.Time]()
+	durationType      = reflect.TypeFor[time.Duration]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// schemaGenerator derives schemas from Go types following encoding/json rules.
+// Named struct types become components referenced with $ref.
+type schemaGenerator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{names: make(map[reflect.Type]string)}
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t == rawMessageType:
+		return &Schema{}
+	case implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objectSchema(t, nil)
+		}
+		return g.ref(t)
+	default:
+		return &Schema{}
+	}
+}
+
+// bodySchema describes the JSON body of a request type: its fields without
+// path, query, or header tags. It returns nil when no field is left.
+func (g *schemaGenerator) bodySchema(t reflect.Type) *Schema {
+	if t.Kind() != reflect.Struct {
+		return g.schema(t)
+	}
+	if !hasBoundField(t) {
+		return g.schema(t)
+	}
+	schema := g.objectSchema(t, isBoundField)
+	if len(schema.Properties) == 0 {
+		return nil
+	}
+	return schema
+}
+
+func (g *schemaGenerator) ref(t reflect.Type) *Schema {
+	name, ok := g.names[t]
+	if !ok {
+		name = g.componentName(t)
+		g.names[t] = name
+		if g.components == nil {
+			g.components = make(map[string]*Schema)
+		}
+		// Reserve the name first so recursive types terminate.
+		g.components[name] = &Schema{}
+		g.components[name] = g.objectSchema(t, nil)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// componentName returns "package.Type", sanitized for component keys and made
+// unique when two packages share a name.
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if open := strings.IndexByte(name, '['); open >= 0 && strings.HasSuffix(name, "]") {
+		args := strings.Split(name[open+1:len(name)-1], ",")
+		for i, arg := range args {
+			args[i] = arg[strings.LastIndexByte(arg, '/')+1:]
+		}
+		name = name[:open] + "_" + strings.Join(args, "_")
+	}
+	if pkg := t.PkgPath(); pkg != "" {
+		name = pkg[strings.LastIndexByte(pkg, '/')+1:] + "." + name
+	}
+	name = strings.Map(func(r rune) rune {
+		if isNameRune(r) || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+
+	unique := name
+	for i := 2; g.components[unique] != nil; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	return unique
+}
+
+func (g *schemaGenerator) objectSchema(t reflect.Type, skip func(reflect.StructField) bool) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.collectFields(schema, t, skip)
+	return schema
+}
+
+func (g *schemaGenerator) collectFields(schema *Schema, t reflect.Type, skip func(reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if skip != nil && skip(field) {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.collectFields(schema, embedded, skip)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schema(field.Type)
+		if hasOption(opts, "string") {
+			property = &Schema{Type: "string"}
+		}
+		schema.Properties[name] = property
+		if field.Type.Kind() != reflect.Pointer && !hasOption(opts, "omitempty") && !hasOption(opts, "omitzero") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+func hasBoundField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isBoundField(field) {
+			return true
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && hasBoundField(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBoundField(field reflect.StructField) bool {
+	for _, source := range []string{"path", "query", "header"} {
+		if name, ok := field.Tag.Lookup(source); ok && name != "" && name != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func hasOption(opts, option string) bool {
+	for opts != "" {
+		var current string
+		current, opts, _ = strings.Cut(opts, ",")
+		if current == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
@@ -0,0 +1,55 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+// Handler serves the document generated from the routes of router. The
+// document is rebuilt on each request so it includes routes registered after
+// the handler was created.
+func Handler(router mkhttp.Router, cfg Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		if err := Write(&buf, Build(mkhttp.Routes(router), cfg)); err != nil {
+			mkhttp.WriteError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf.Bytes())
+	})
+}
+
+// Serve registers a public GET route at cfg.Path (default DefaultPath) that
+// serves the document. The route itself is hidden from the document.
+func Serve(router mkhttp.Router, cfg Config) {
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath
+	}
+	router.Handle(http.MethodGet, path, mkhttp.Route(Handler(router, cfg), mkhttp.Public(), mkhttp.Hidden()))
+}
+
+// Write encodes doc as indented JSON. Map keys are sorted, so the output is
+// stable across runs and suitable for diffing.
+func Write(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteFile writes doc to the named file, for example to commit it and fail CI
+// when the checked-in copy differs from the generated one.
+func WriteFile(name string, doc *Document) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		return err
+	}
+	return os.WriteFile(name, buf.Bytes(), 0o644)
+}
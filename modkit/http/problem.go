@@ -0,0 +1,152 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// ProblemContentType is the RFC 9457 media type for problem details.
+const ProblemContentType = "application/problem+json"
+
+// InvalidParam describes a request parameter that failed binding or validation.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// Problem is an RFC 9457 problem details object. It implements error so handlers
+// can return it directly.
+type Problem struct {
+	Type          string         `json:"type,omitempty"`
+	Title         string         `json:"title,omitempty"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	Code          string         `json:"code,omitempty"`
+	InvalidParams []InvalidParam `json:"invalidParams,omitempty"`
+	RequestID     string         `json:"requestId,omitempty"`
+	// Headers are added to the response, for example WWW-Authenticate.
+	Headers http.Header `json:"-"`
+
+	// cause is returned by Unwrap and never written.
+	cause error
+}
+
+// NewProblem returns a problem with the given status and detail.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Status: status, Detail: detail}
+}
+
+// Unwrap returns the error the problem was built from, if any, such as
+// ErrInvalidToken for a rejected bearer token.
+func (p *Problem) Unwrap() error {
+	return p.cause
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return http.StatusText(p.Status) + ": " + p.Detail
+	}
+	return http.StatusText(p.Status)
+}
+
+// WriteProblem writes p as application/problem+json. Type defaults to
+// "about:blank", Title to the status text, Instance to the request path, and
+// RequestID to the chi request ID.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Instance == "" && r != nil && r.URL != nil {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" && r != nil {
+		p.RequestID = middleware.GetReqID(r.Context())
+	}
+
+	for key, values := range p.Headers {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// InvalidParamsError is implemented by errors that describe invalid request parameters.
+type InvalidParamsError interface {
+	error
+	InvalidParams() []InvalidParam
+}
+
+// ErrorMapper converts an error returned by a handler into a problem. It returns
+// false when it does not handle err, so the next mapper (or the default) applies.
+type ErrorMapper interface {
+	MapError(r *http.Request, err error) (Problem, bool)
+}
+
+// ErrorMapperFunc adapts a function to ErrorMapper.
+type ErrorMapperFunc func(r *http.Request, err error) (Problem, bool)
+
+// MapError calls f(r, err).
+func (f ErrorMapperFunc) MapError(r *http.Request, err error) (Problem, bool) {
+	return f(r, err)
+}
+
+// DefaultErrorMapper maps a returned *Problem as-is, binding and validation
+// errors to 400 with their invalid parameters, bodies over a MaxBodySize limit to
+// 413, and everything else to a 500 that does not leak the error text.
+var DefaultErrorMapper ErrorMapper = ErrorMapperFunc(defaultMapError)
+
+func defaultMapError(_ *http.Request, err error) (Problem, bool) {
+	var problem *Problem
+	if errors.As(err, &problem) {
+		return *problem, true
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return Problem{Status: http.StatusRequestEntityTooLarge, Detail: "request body too large"}, true
+	}
+
+	var bindErr *BindError
+	if errors.As(err, &bindErr) {
+		return Problem{Status: http.StatusBadRequest, Detail: "invalid request", InvalidParams: bindErr.InvalidParams()}, true
+	}
+
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		problem := Problem{Status: http.StatusBadRequest, Detail: "validation failed", InvalidParams: validationErr.InvalidParams()}
+		if len(problem.InvalidParams) == 0 {
+			problem.Detail = validationErr.Err.Error()
+		}
+		return problem, true
+	}
+
+	return Problem{Status: http.StatusInternalServerError, Detail: "internal error"}, true
+}
+
+// mapError applies mapper, then the request's mapper installed by MapErrors, and
+// falls back to DefaultErrorMapper.
+func mapError(mapper ErrorMapper, r *http.Request, err error) Problem {
+	for _, m := range []ErrorMapper{mapper, errorMapperFromContext(r.Context())} {
+		if m == nil {
+			continue
+		}
+		if problem, ok := m.MapError(r, err); ok {
+			return problem
+		}
+	}
+	problem, _ := DefaultErrorMapper.MapError(r, err)
+	return problem
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig configures a token-bucket RateLimiter.
+type RateLimitConfig struct {
+	// RequestsPerSecond is the refill rate of each bucket; zero or less
+	// disables rate limiting.
+	RequestsPerSecond float64
+	// Burst is the bucket size (default 1).
+	Burst int
+	// Key selects the bucket of a request (default RateLimitByIP).
+	Key func(r *http.Request) string
+	// IdleTTL drops buckets unused for this long (default 10 minutes), but
+	// never before Burst/RequestsPerSecond, when they would be full again.
+	IdleTTL time.Duration
+}
+
+// RateLimitByIP keys requests by the host of RemoteAddr. Put it behind a
+// trusted proxy-aware middleware such as chi's RealIP.
+func RateLimitByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RateLimitByPrincipal keys requests by the subject of the principal set by a
+// guard, falling back to RateLimitByIP for anonymous requests. Use it with the
+// RateLimiter as a guard, after the authenticating guard.
+func RateLimitByPrincipal(r *http.Request) string {
+	if principal, ok := PrincipalFromContext(r.Context()); ok && principal.Subject != "" {
+		return "principal:" + principal.Subject
+	}
+	return "ip:" + RateLimitByIP(r)
+}
+
+// RateLimiter limits requests per key with token buckets. Rejected requests get
+// a 429 problem with Retry-After. It is middleware through Middleware and a
+// Guard through Allow, the latter running after authentication guards so
+// principals can be keyed.
+type RateLimiter struct {
+	cfg     RateLimitConfig
+	now     func() time.Time
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter for cfg.
+func NewRateLimiter(cfg RateLimitConfig) *RateLimiter {
+	if cfg.Burst <= 0 {
+		cfg.Burst = 1
+	}
+	if cfg.Key == nil {
+		cfg.Key = RateLimitByIP
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = 10 * time.Minute
+	}
+	// Dropping a bucket before it could refill would hand a limited client a
+	// full burst, so keep buckets at least as long as a refill takes.
+	if cfg.RequestsPerSecond > 0 {
+		if refill := time.Duration(float64(cfg.Burst) / cfg.RequestsPerSecond * float64(time.Second)); cfg.IdleTTL < refill {
+			cfg.IdleTTL = refill
+		}
+	}
+	return &RateLimiter{cfg: cfg, now: time.Now, buckets: make(map[string]*tokenBucket)}
+}
+
+// RateLimit returns rate limiting middleware for cfg.
+func RateLimit(cfg RateLimitConfig) func(http.Handler) http.Handler {
+	return NewRateLimiter(cfg).Middleware
+}
+
+// Middleware rejects requests over the limit before they reach next.
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := l.Allow(r, RouteMeta{}); err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Allow implements Guard. It returns a 429 *Problem when the request's bucket
+// is empty.
+func (l *RateLimiter) Allow(r *http.Request, _ RouteMeta) (*http.Request, error) {
+	if l.cfg.RequestsPerSecond <= 0 {
+		return r, nil
+	}
+	if wait, ok := l.take(l.cfg.Key(r)); !ok {
+		problem := NewProblem(http.StatusTooManyRequests, "rate limit exceeded")
+		problem.Headers = http.Header{"Retry-After": {retryAfter(wait)}}
+		return nil, problem
+	}
+	return r, nil
+}
+
+// take removes a token from the bucket of key, or returns how long until one is
+// available.
+func (l *RateLimiter) take(key string) (time.Duration, bool) {
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.swept) >= l.cfg.IdleTTL {
+		for k, bucket := range l.buckets {
+			if now.Sub(bucket.last) >= l.cfg.IdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	burst := float64(l.cfg.Burst)
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		l.buckets[key] = bucket
+	}
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed*l.cfg.RequestsPerSecond)
+	}
+	bucket.last = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+	wait := (1 - bucket.tokens) / l.cfg.RequestsPerSecond
+	return time.Duration(wait * float64(time.Second)), false
+}
+
+func retryAfter(wait time.Duration) string {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
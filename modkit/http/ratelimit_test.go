@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_BlocksAfterBurstAndRefills(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2})
+	limiter.now = func() time.Time { return now }
+	handler := limiter.Middleware(noContent())
+
+	serve := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := serve("10.0.0.1:1234"); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: expected 204, got %d", i+1, rec.Code)
+		}
+	}
+	rec := serve("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected 429 problem, got %d %v", rec.Code, rec.Header())
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("expected Retry-After 2, got %q", got)
+	}
+	if rec := serve("10.0.0.2:1234"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected separate bucket per IP, got %d", rec.Code)
+	}
+
+	now = now.Add(2 * time.Second)
+	if rec := serve("10.0.0.1:1234"); rec.Code != http.StatusNoContent {
+		t.Fatalf("expected refilled bucket, got %d", rec.Code)
+	}
+}
+
+func TestRateLimiter_GuardKeysByPrincipal(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 1, Key: RateLimitByPrincipal})
+	allow := func(subject string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req = req.WithContext(WithPrincipal(context.Background(), Principal{Subject: subject}))
+		_, err := limiter.Allow(req, RouteMeta{})
+		return err
+	}
+
+	if err := allow("alice"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := allow("bob"); err != nil {
+		t.Fatalf("expected separate bucket per principal, got %v", err)
+	}
+	problem, ok := allow("alice").(*Problem)
+	if !ok || problem.Status != http.StatusTooManyRequests || problem.Headers.Get("Retry-After") != "1" {
+		t.Fatalf("expected 429 problem with Retry-After, got %#v", problem)
+	}
+}
+
+func TestRateLimiter_DisabledAndIdleBucketsDropped(t *testing.T) {
+	if _, err := NewRateLimiter(RateLimitConfig{}).Allow(httptest.NewRequest(http.MethodGet, "/", http.NoBody), RouteMeta{}); err != nil {
+		t.Fatalf("expected disabled limiter to allow, got %v", err)
+	}
+
+	now := time.Unix(1000, 0)
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 1, IdleTTL: time.Minute})
+	limiter.now = func() time.Time { return now }
+	limiter.take("a")
+	now = now.Add(2 * time.Minute)
+	limiter.take("b")
+	if _, ok := limiter.buckets["a"]; ok || len(limiter.buckets) != 1 {
+		t.Fatalf("expected idle bucket to be dropped, got %v", limiter.buckets)
+	}
+}
+
+func TestRateLimiter_KeepsBucketsUntilRefilled(t *testing.T) {
+	now := time.Unix(1000, 0)
+	limiter := NewRateLimiter(RateLimitConfig{RequestsPerSecond: 0.01, Burst: 2, IdleTTL: time.Minute})
+	limiter.now = func() time.Time { return now }
+	limiter.take("a")
+	limiter.take("a")
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := limiter.take("a"); !ok {
+		t.Fatalf("expected the refilled token to be available")
+	}
+	if _, ok := limiter.take("a"); ok {
+		t.Fatalf("expected a partly refilled bucket to limit, not a fresh burst")
+	}
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"testing"
@@ -53,6 +54,40 @@ func TestRegisterRoutes_ErrsOnMissingRegistrar(t *testing.T) {
 	}
 }
 
+type workerController struct{}
+
+func (workerController) Run(context.Context) error { return nil }
+
+func TestRegisterRoutes_SkipsRunnableControllers(t *testing.T) {
+	router := NewRouter()
+	ctrl := &testController{}
+
+	err := RegisterRoutes(AsRouter(router), map[string]any{"Test": ctrl, "Worker": workerController{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ctrl.called {
+		t.Fatalf("expected controller RegisterRoutes to be called")
+	}
+}
+
+type healthController struct{}
+
+func (healthController) CheckHealth(context.Context) error { return nil }
+
+type serviceController struct{}
+
+func (serviceController) RegisterServices(any) {}
+
+func TestRegisterRoutes_SkipsHealthAndServiceControllers(t *testing.T) {
+	router := NewRouter()
+
+	err := RegisterRoutes(AsRouter(router), map[string]any{"Health": healthController{}, "Service": serviceController{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestRegisterRoutes_DoesNotPartiallyRegister(t *testing.T) {
 	router := NewRouter()
 	ctrlA := &testController{}
@@ -0,0 +1,433 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+const (
+	// CacheStatusHeader reports how the server-side cache handled a response;
+	// see RFC 9211.
+	CacheStatusHeader = "Cache-Status"
+
+	// DefaultResponseCacheTTL is how long responses are cached when neither
+	// ResponseCacheConfig.TTL nor CachePolicy.ServerTTL is set.
+	DefaultResponseCacheTTL = time.Minute
+	// DefaultMemoryCacheEntries bounds a MemoryCacheStore created with a
+	// non-positive size.
+	DefaultMemoryCacheEntries = 10000
+
+	cacheStatusName = "modkit"
+)
+
+// CachedResponse is a response stored by a ResponseCache. Header only holds
+// the headers set by the route handler; middleware outside the cache sets its
+// own on every hit.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Tags label the response for invalidation.
+	Tags     []string
+	StoredAt time.Time
+	// Vary is set, with a zero Status, on the entry stored under a request's
+	// key when the response varies by request headers the key does not cover.
+	// The response itself is stored under the key extended with their values.
+	Vary []string
+}
+
+// CacheStore persists cached responses.
+type CacheStore interface {
+	// Get returns the unexpired response stored under key, and false when there
+	// is none.
+	Get(ctx context.Context, key string) (*CachedResponse, bool, error)
+	// Set stores response under key until ttl elapses.
+	Set(ctx context.Context, key string, response CachedResponse, ttl time.Duration) error
+	// Invalidate removes every response labeled with one of tags.
+	Invalidate(ctx context.Context, tags ...string) error
+}
+
+// ResponseCacheConfig configures NewResponseCache.
+type ResponseCacheConfig struct {
+	// Store holds responses (default a new MemoryCacheStore, which only suits a
+	// single process).
+	Store CacheStore
+	// TTL is how long responses are kept (default DefaultResponseCacheTTL).
+	TTL time.Duration
+	// Vary lists request headers keying every cached route, in addition to
+	// CachePolicy.Vary.
+	Vary []string
+	// Key returns the base cache key of a request (default method, host, and
+	// URL). Return "" to skip the cache, for example for requests it cannot
+	// tell apart.
+	Key func(r *http.Request) string
+}
+
+// ResponseCache is an opt-in server-side cache of GET responses. Routes use it
+// through CachePolicy.Server; providers invalidate entries by tag.
+type ResponseCache struct {
+	store CacheStore
+	ttl   time.Duration
+	vary  []string
+	key   func(r *http.Request) string
+	now   func() time.Time
+}
+
+// NewResponseCache returns a cache with cfg applied over the defaults.
+func NewResponseCache(cfg ResponseCacheConfig) *ResponseCache {
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryCacheStore(0)
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultResponseCacheTTL
+	}
+	if cfg.Key == nil {
+		cfg.Key = defaultCacheKey
+	}
+	return &ResponseCache{store: cfg.Store, ttl: cfg.TTL, vary: cfg.Vary, key: cfg.Key, now: time.Now}
+}
+
+func defaultCacheKey(r *http.Request) string {
+	return r.Host + r.URL.RequestURI()
+}
+
+// Invalidate removes the responses labeled with any of tags, for example from a
+// service after it updates the data behind them.
+func (c *ResponseCache) Invalidate(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return c.store.Invalidate(ctx, tags...)
+}
+
+// handler serves GET and HEAD requests of a route with policy from the cache
+// and stores 200 responses of GET requests. Store failures are treated as
+// misses.
+func (c *ResponseCache) handler(policy *CachePolicy, next http.Handler) http.Handler {
+	ttl := policy.ServerTTL
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	vary := append(slices.Clone(c.vary), policy.Vary...)
+	if policy.Private || policy.NoStore {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Shared caches must not reuse responses to authorized requests unless
+		// the cache is keyed by their credentials; see RFC 9111, section 3.5.
+		// Cookies carry sessions just as often, so they get the same rule.
+		if r.Header.Get("Authorization") != "" && !containsFold(vary, "Authorization") ||
+			r.Header.Get("Cookie") != "" && !containsFold(vary, "Cookie") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key := c.key(r)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key = cacheKey(key, r, vary)
+
+		ctx := r.Context()
+		if cached, ok := c.lookup(ctx, key, r); ok {
+			c.replay(w, cached)
+			return
+		}
+		if r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tags := &cacheTags{tags: slices.Clone(policy.Tags)}
+		r = r.WithContext(context.WithValue(ctx, cacheTagsKey{}, tags))
+		before := w.Header().Clone()
+		buf := newBufferedWriter(w)
+		next.ServeHTTP(buf, r)
+
+		if buf.status() == http.StatusOK && storable(w.Header()) {
+			stored := CachedResponse{
+				Status:   http.StatusOK,
+				Header:   handlerHeader(before, w.Header()),
+				Body:     bytes.Clone(buf.body.Bytes()),
+				Tags:     tags.list(),
+				StoredAt: c.now(),
+			}
+			stored.Header.Del(CacheStatusHeader)
+			if c.save(context.WithoutCancel(ctx), key, r, varyNames(w.Header(), vary), stored, ttl) == nil {
+				w.Header().Set(CacheStatusHeader, cacheStatusName+"; fwd=miss; stored")
+			}
+		}
+		buf.flush()
+	})
+}
+
+// lookup returns the response stored under key, following the entry recording
+// the extra Vary headers of the route's responses, if any.
+func (c *ResponseCache) lookup(ctx context.Context, key string, r *http.Request) (*CachedResponse, bool) {
+	cached, ok, err := c.store.Get(ctx, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	if cached.Status != 0 {
+		return cached, true
+	}
+	cached, ok, err = c.store.Get(ctx, cacheKey(key, r, cached.Vary))
+	if err != nil || !ok || cached.Status == 0 {
+		return nil, false
+	}
+	return cached, true
+}
+
+// save stores response under key. When the response varies by extra request
+// headers, which key does not cover, it is stored under key extended with their
+// values instead, and an entry listing them is stored under key.
+func (c *ResponseCache) save(ctx context.Context, key string, r *http.Request, extra []string, response CachedResponse, ttl time.Duration) error {
+	if len(extra) == 0 {
+		return c.store.Set(ctx, key, response, ttl)
+	}
+	if err := c.store.Set(ctx, key, CachedResponse{Vary: extra, StoredAt: response.StoredAt}, ttl); err != nil {
+		return err
+	}
+	return c.store.Set(ctx, cacheKey(key, r, extra), response, ttl)
+}
+
+// varyNames returns the sorted request headers named by the Vary header of h,
+// including those set by middleware outside the cache, that are not in keyed.
+func varyNames(h http.Header, keyed []string) []string {
+	var names []string
+	for _, value := range h.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" && !containsFold(keyed, name) && !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (c *ResponseCache) replay(w http.ResponseWriter, cached *CachedResponse) {
+	h := w.Header()
+	for name, values := range cached.Header {
+		h[name] = slices.Clone(values)
+	}
+	age := int64(c.now().Sub(cached.StoredAt) / time.Second)
+	h.Set("Age", strconv.FormatInt(max(age, 0), 10))
+	h.Set(CacheStatusHeader, cacheStatusName+"; hit")
+	w.WriteHeader(cached.Status)
+	_, _ = w.Write(cached.Body)
+}
+
+// cacheKey appends the values of the vary headers to the base key.
+func cacheKey(base string, r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for _, name := range vary {
+		b.WriteString("\n" + http.CanonicalHeaderKey(name) + ": ")
+		b.WriteString(strings.Join(r.Header.Values(name), ", "))
+	}
+	return b.String()
+}
+
+// storable reports whether response headers allow a shared cache to store the
+// response.
+func storable(h http.Header) bool {
+	if h.Get("Set-Cookie") != "" || h.Get("Vary") == "*" {
+		return false
+	}
+	for _, value := range h.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.ToLower(strings.TrimSpace(directive))
+			if directive == "no-store" || directive == "private" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, target string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, target) })
+}
+
+type cacheTagsKey struct{}
+
+type cacheTags struct {
+	mu   sync.Mutex
+	tags []string
+}
+
+func (t *cacheTags) list() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.tags)
+}
+
+// AddCacheTags labels the response to r with tags when a ResponseCache stores
+// it, for example "user:42" for a route serving one user. It does nothing for
+// requests the cache does not handle.
+func AddCacheTags(r *http.Request, tags ...string) {
+	t, ok := r.Context().Value(cacheTagsKey{}).(*cacheTags)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tags = append(t.tags, tags...)
+}
+
+// MemoryCacheStore is an in-process CacheStore holding a bounded number of
+// responses.
+type MemoryCacheStore struct {
+	now        func() time.Time
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]*memoryCacheEntry
+	tags       map[string]map[string]struct{}
+	swept      time.Time
+}
+
+type memoryCacheEntry struct {
+	response  CachedResponse
+	expiresAt time.Time
+}
+
+// NewMemoryCacheStore returns an empty store keeping at most maxEntries
+// responses (DefaultMemoryCacheEntries when not positive). When full, expired
+// responses are dropped first, then arbitrary ones.
+func NewMemoryCacheStore(maxEntries int) *MemoryCacheStore {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryCacheEntries
+	}
+	return &MemoryCacheStore{
+		now:        time.Now,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*memoryCacheEntry),
+		tags:       make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements CacheStore.
+func (s *MemoryCacheStore) Get(_ context.Context, key string) (*CachedResponse, bool, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !now.Before(entry.expiresAt) {
+		s.remove(key)
+		return nil, false, nil
+	}
+	response := entry.response
+	return &response, true, nil
+}
+
+// Set implements CacheStore.
+func (s *MemoryCacheStore) Set(_ context.Context, key string, response CachedResponse, ttl time.Duration) error {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(key)
+	if now.Sub(s.swept) >= time.Minute || len(s.entries) >= s.maxEntries {
+		for k, entry := range s.entries {
+			if !now.Before(entry.expiresAt) {
+				s.remove(k)
+			}
+		}
+		s.swept = now
+	}
+	for k := range s.entries {
+		if len(s.entries) < s.maxEntries {
+			break
+		}
+		s.remove(k)
+	}
+
+	response.Header = response.Header.Clone()
+	response.Body = bytes.Clone(response.Body)
+	response.Tags = slices.Clone(response.Tags)
+	response.Vary = slices.Clone(response.Vary)
+	s.entries[key] = &memoryCacheEntry{response: response, expiresAt: now.Add(ttl)}
+	for _, tag := range response.Tags {
+		keys, ok := s.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate implements CacheStore.
+func (s *MemoryCacheStore) Invalidate(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.remove(key)
+		}
+	}
+	return nil
+}
+
+// remove deletes key and its tag index entries. The caller holds s.mu.
+func (s *MemoryCacheStore) remove(key string) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	for _, tag := range entry.response.Tags {
+		delete(s.tags[tag], key)
+		if len(s.tags[tag]) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// TokenResponseCache resolves the *ResponseCache of NewResponseCacheModule.
+const TokenResponseCache module.Token = "http.response_cache"
+
+// ResponseCacheModule provides a shared ResponseCache.
+type ResponseCacheModule struct {
+	cfg ResponseCacheConfig
+}
+
+// NewResponseCacheModule constructs a module exporting TokenResponseCache.
+// Controllers resolve the cache for CachePolicy.Server, and providers resolve it
+// to invalidate responses after writes.
+func NewResponseCacheModule(cfg ResponseCacheConfig) module.Module {
+	return &ResponseCacheModule{cfg: cfg}
+}
+
+// Definition returns the module definition for graph construction.
+func (m *ResponseCacheModule) Definition() module.ModuleDef {
+	return module.ModuleDef{
+		Name: "http.response_cache",
+		Providers: []module.ProviderDef{{
+			Token: TokenResponseCache,
+			Build: func(module.Resolver) (any, error) {
+				return NewResponseCache(m.cfg), nil
+			},
+		}},
+		Exports: []module.Token{TokenResponseCache},
+	}
+}
@@ -0,0 +1,245 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func cachedRoute(router Router, pattern string, policy CachePolicy, calls *atomic.Int32, fn func(w http.ResponseWriter, r *http.Request)) {
+	router.Handle(http.MethodGet, pattern, Route(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if fn != nil {
+			fn(w, r)
+		}
+		_, _ = w.Write([]byte("response " + strconv.Itoa(int(n))))
+	}), CacheControl(policy)))
+}
+
+func TestResponseCache_ServesHitsUntilExpiry(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		now := time.Unix(1000, 0)
+		store := NewMemoryCacheStore(0)
+		store.now = func() time.Time { return now }
+		cache := NewResponseCache(ResponseCacheConfig{Store: store, TTL: time.Minute})
+		cache.now = func() time.Time { return now }
+
+		var calls atomic.Int32
+		cachedRoute(router, "/items", CachePolicy{Server: cache, MaxAge: time.Minute}, &calls, nil)
+		get := func() *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", http.NoBody))
+			return rec
+		}
+
+		rec := get()
+		if rec.Body.String() != "response 1" || rec.Header().Get(CacheStatusHeader) != "modkit; fwd=miss; stored" {
+			t.Fatalf("unexpected miss: %q %v", rec.Body.String(), rec.Header())
+		}
+		now = now.Add(30 * time.Second)
+		rec = get()
+		if rec.Body.String() != "response 1" || rec.Header().Get(CacheStatusHeader) != "modkit; hit" {
+			t.Fatalf("unexpected hit: %q %v", rec.Body.String(), rec.Header())
+		}
+		if rec.Header().Get("Age") != "30" || rec.Header().Get("Cache-Control") != "max-age=60" {
+			t.Fatalf("unexpected hit headers: %v", rec.Header())
+		}
+
+		now = now.Add(30 * time.Second)
+		if rec := get(); rec.Body.String() != "response 2" {
+			t.Fatalf("expected expired entry to be refreshed, got %q", rec.Body.String())
+		}
+	})
+}
+
+func TestResponseCache_VaryAndUncacheableRequests(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{})
+	router := NewServeMux()
+	var calls atomic.Int32
+	cachedRoute(router, "/greeting", CachePolicy{Server: cache, Vary: []string{"Accept-Language"}}, &calls, nil)
+	cachedRoute(router, "/session", CachePolicy{Server: cache}, &calls, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Set-Cookie", "session=1")
+	})
+
+	get := func(path string, header http.Header) string {
+		req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	en := http.Header{"Accept-Language": {"en"}}
+	fr := http.Header{"Accept-Language": {"fr"}}
+	if get("/greeting", en) != "response 1" || get("/greeting", fr) != "response 2" || get("/greeting", en) != "response 1" {
+		t.Fatalf("expected one entry per Accept-Language")
+	}
+	if body := get("/greeting", http.Header{"Accept-Language": {"en"}, "Authorization": {"Bearer x"}}); body != "response 3" {
+		t.Fatalf("expected authorized request to bypass the cache, got %q", body)
+	}
+	if body := get("/greeting", http.Header{"Accept-Language": {"en"}, "Cookie": {"session=1"}}); body != "response 4" {
+		t.Fatalf("expected request with cookies to bypass the cache, got %q", body)
+	}
+	if get("/session", nil) != "response 5" || get("/session", nil) != "response 6" {
+		t.Fatalf("expected responses with Set-Cookie not to be stored")
+	}
+}
+
+func TestResponseCache_VaryCookie(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{})
+	router := NewServeMux()
+	var calls atomic.Int32
+	cachedRoute(router, "/me", CachePolicy{Server: cache, Vary: []string{"Cookie"}}, &calls, nil)
+
+	get := func(cookie string) string {
+		req := httptest.NewRequest(http.MethodGet, "/me", http.NoBody)
+		req.Header.Set("Cookie", cookie)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	if get("session=a") != "response 1" || get("session=b") != "response 2" || get("session=a") != "response 1" {
+		t.Fatalf("expected one entry per cookie when Vary names Cookie")
+	}
+}
+
+func TestResponseCache_ThroughCORS(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		cache := NewResponseCache(ResponseCacheConfig{})
+		router.Use(CORS(CORSConfig{AllowedOrigins: []string{"https://a.example", "https://b.example"}}))
+		var calls atomic.Int32
+		cachedRoute(router, "/items", CachePolicy{Server: cache}, &calls, nil)
+		get := func(origin string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/items", http.NoBody)
+			req.Header.Set("Origin", origin)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec
+		}
+
+		for _, origin := range []string{"https://a.example", "https://b.example", "https://a.example", "https://b.example"} {
+			rec := get(origin)
+			if got := rec.Header().Values("Access-Control-Allow-Origin"); len(got) != 1 || got[0] != origin {
+				t.Fatalf("expected Access-Control-Allow-Origin %s, got %v", origin, got)
+			}
+			if got := rec.Header().Values("Vary"); len(got) != 1 || got[0] != "Origin" {
+				t.Fatalf("expected a single Vary: Origin, got %v", got)
+			}
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("expected one stored response per origin, got %d handler calls", calls.Load())
+		}
+	})
+}
+
+func TestResponseCache_KeysByHandlerVary(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{})
+	router := NewServeMux()
+	var calls atomic.Int32
+	cachedRoute(router, "/report", CachePolicy{Server: cache}, &calls, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		w.Header().Set("Content-Type", r.Header.Get("Accept"))
+	})
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/report", http.NoBody)
+		req.Header.Set("Accept", accept)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i, tc := range []struct{ accept, body string }{
+		{"application/json", "response 1"},
+		{"text/csv", "response 2"},
+		{"application/json", "response 1"},
+		{"text/csv", "response 2"},
+	} {
+		rec := get(tc.accept)
+		if rec.Body.String() != tc.body || rec.Header().Get("Content-Type") != tc.accept {
+			t.Fatalf("request %d: expected %q as %s, got %q as %s", i, tc.body, tc.accept, rec.Body.String(), rec.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestResponseCache_InvalidatesByTag(t *testing.T) {
+	cache := NewResponseCache(ResponseCacheConfig{})
+	router := NewServeMux()
+	var calls atomic.Int32
+	cachedRoute(router, "/users/{id}", CachePolicy{Server: cache, Tags: []string{"users"}}, &calls, func(_ http.ResponseWriter, r *http.Request) {
+		AddCacheTags(r, "user:"+PathParam(r, "id"))
+	})
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		return rec.Body.String()
+	}
+
+	get("/users/1")
+	get("/users/2")
+	if err := cache.Invalidate(context.Background(), "user:1"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if got := get("/users/1"); got != "response 3" {
+		t.Fatalf("expected invalidated entry to be refreshed, got %q", got)
+	}
+	if got := get("/users/2"); got != "response 2" {
+		t.Fatalf("expected other entry to stay cached, got %q", got)
+	}
+
+	if err := cache.Invalidate(context.Background(), "users"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if got := get("/users/2"); got != "response 4" {
+		t.Fatalf("expected route tag to invalidate every entry, got %q", got)
+	}
+}
+
+func TestMemoryCacheStore_EvictsWhenFull(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if err := store.Set(ctx, key, CachedResponse{Status: http.StatusOK, Tags: []string{"t"}}, time.Minute); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+	if len(store.entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(store.entries))
+	}
+	if _, ok, _ := store.Get(ctx, "c"); !ok {
+		t.Fatalf("expected latest entry to be kept")
+	}
+	if err := store.Invalidate(ctx, "t"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if len(store.entries) != 0 || len(store.tags) != 0 {
+		t.Fatalf("expected empty store, got %d entries and %d tags", len(store.entries), len(store.tags))
+	}
+}
+
+func TestResponseCacheModule_ExportsCache(t *testing.T) {
+	root := &mountModule{def: module.ModuleDef{
+		Name:    "root",
+		Imports: []module.Module{NewResponseCacheModule(ResponseCacheConfig{TTL: time.Hour})},
+	}}
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	cache, err := module.Get[*ResponseCache](app, TokenResponseCache)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if cache.ttl != time.Hour {
+		t.Fatalf("expected configured TTL, got %v", cache.ttl)
+	}
+}
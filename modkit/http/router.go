@@ -2,10 +2,14 @@ package http
 
 import (
 	"net/http"
+	"reflect"
 	"sort"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
 )
 
 // Router provides a minimal method-based handler registration API.
@@ -20,27 +24,196 @@ type RouteRegistrar interface {
 	RegisterRoutes(router Router)
 }
 
+// routerBackend is the routing engine behind a routerAdapter. Patterns use chi
+// syntax and are relative to the backend's scope.
+type routerBackend interface {
+	method(method, pattern string, handler http.Handler)
+	// group scopes middleware without a prefix.
+	group(fn func(routerBackend))
+	// route mounts a sub-router at pattern.
+	route(pattern string, fn func(routerBackend))
+	use(middlewares ...func(http.Handler) http.Handler)
+	// middlewares counts the middlewares wrapping the backend, including those
+	// added before it was adapted.
+	middlewares() int
+}
+
 type routerAdapter struct {
-	chi.Router
+	backend routerBackend
+	routes  *routeTable
+	prefix  string
+	parent  *routerAdapter
+	guards  []Guard
+	// uses counts the middlewares added through Use on a group.
+	uses int
+}
+
+func (r *routerAdapter) Handle(method, pattern string, handler http.Handler) {
+	next, meta, routeGuards, isRoute := unwrapRoute(handler)
+	guards := r.guardChain(routeGuards)
+	info := RouteInfo{
+		Method:     method,
+		Pattern:    joinPattern(r.prefix, pattern),
+		Middleware: r.middlewareCount(),
+		Guards:     len(guards),
+		Meta:       meta,
+		Security:   securitySchemes(guards),
+	}
+	if typed, ok := next.(TypedHandler); ok {
+		types := typed.HandlerTypes()
+		info.Types = &types
+	}
+	if isRoute || len(guards) > 0 {
+		handler = guarded(withCachePolicy(next, meta), meta, guards)
+	}
+
+	versioning, versions := r.routes.routeVersions(meta)
+	if versioning == nil {
+		if r.routes.add(info) {
+			r.backend.method(method, pattern, recordRoute(info.Pattern, handler))
+		}
+		return
+	}
+	r.handleVersions(versioning, versions, info, pattern, handler)
+}
+
+// handleVersions registers handler once per version: under a version prefix
+// with VersionURI, otherwise through a dispatcher shared by all versions of the
+// route. A nil versions slice registers a version-neutral route.
+func (r *routerAdapter) handleVersions(versioning *Versioning, versions []string, info RouteInfo, pattern string, handler http.Handler) {
+	if versions == nil {
+		versions = []string{""}
+	}
+	for _, version := range versions {
+		route := info
+		route.Version = version
+		local := pattern
+		if versioning.Type == VersionURI && version != "" {
+			local = joinPattern("/"+versioning.Prefix+version, pattern)
+			route.Pattern = joinPattern(r.prefix, local)
+		}
+		if !r.routes.add(route) {
+			continue
+		}
+
+		versioned := recordRoute(route.Pattern, versioning.wrap(version, route.Meta, handler))
+		if versioning.Type == VersionURI {
+			r.backend.method(route.Method, local, versioned)
+			continue
+		}
+		dispatcher, created := r.routes.dispatcher(route.Method, route.Pattern, versioning)
+		dispatcher.set(version, versioned)
+		if created {
+			r.backend.method(route.Method, pattern, dispatcher)
+		}
+	}
+}
+
+// UseGuards adds guards for routes registered afterwards in this scope.
+func (r *routerAdapter) UseGuards(guards ...Guard) {
+	r.guards = append(r.guards, guards...)
+}
+
+// guardChain orders guards as global (root), controller, group, then route.
+func (r *routerAdapter) guardChain(routeGuards []Guard) []Guard {
+	var scopes []*routerAdapter
+	for scope := r; scope != nil; scope = scope.parent {
+		scopes = append(scopes, scope)
+	}
+	root := scopes[len(scopes)-1]
+
+	guards := append([]Guard(nil), root.guards...)
+	guards = append(guards, r.routes.ownerGuards()...)
+	for i := len(scopes) - 2; i >= 0; i-- {
+		guards = append(guards, scopes[i].guards...)
+	}
+	return append(guards, routeGuards...)
 }
 
-func (r routerAdapter) Handle(method, pattern string, handler http.Handler) {
-	r.Method(method, pattern, handler)
+// UseVersioning enables API versioning for routes registered afterwards through
+// this adapter and every adapter sharing its route table.
+func (r *routerAdapter) UseVersioning(versioning Versioning) {
+	r.routes.setVersioning(versioning)
 }
 
-func (r routerAdapter) Group(pattern string, fn func(Router)) {
-	r.Route(pattern, func(sub chi.Router) {
-		fn(routerAdapter{Router: sub})
+// Group mounts a sub-router at pattern. An empty pattern creates an inline
+// group that only scopes middleware and guards.
+func (r *routerAdapter) Group(pattern string, fn func(Router)) {
+	if pattern == "" {
+		r.backend.group(func(sub routerBackend) {
+			fn(&routerAdapter{backend: sub, routes: r.routes, prefix: r.prefix, parent: r})
+		})
+		return
+	}
+	r.backend.route(pattern, func(sub routerBackend) {
+		fn(&routerAdapter{backend: sub, routes: r.routes, prefix: joinPattern(r.prefix, pattern), parent: r})
 	})
 }
 
-func (r routerAdapter) Use(middlewares ...func(http.Handler) http.Handler) {
-	r.Router.Use(middlewares...)
+func (r *routerAdapter) Use(middlewares ...func(http.Handler) http.Handler) {
+	r.backend.use(middlewares...)
+	r.uses += len(middlewares)
+}
+
+// Routes returns the routes registered through this adapter and its groups.
+func (r *routerAdapter) Routes() []RouteInfo {
+	return r.routes.list()
 }
 
-// AsRouter adapts a chi router to the minimal Router interface.
+func (r *routerAdapter) middlewareCount() int {
+	count := 0
+	for scope := r; scope != nil; scope = scope.parent {
+		if scope.parent == nil {
+			count += scope.backend.middlewares()
+		} else {
+			count += scope.uses
+		}
+	}
+	return count
+}
+
+func (r *routerAdapter) adapter() *routerAdapter {
+	return r
+}
+
+// adapterOf returns the adapter behind router, or nil when router is not one.
+func adapterOf(router Router) *routerAdapter {
+	if adapted, ok := router.(interface{ adapter() *routerAdapter }); ok {
+		return adapted.adapter()
+	}
+	return nil
+}
+
+// AsRouter adapts a chi router to the minimal Router interface. The returned
+// Router records every route it registers; see Routes. NewServeMux provides the
+// same adapter on top of http.ServeMux.
 func AsRouter(router chi.Router) Router {
-	return routerAdapter{Router: router}
+	return &routerAdapter{backend: chiBackend{router}, routes: newRouteTable()}
+}
+
+// chiBackend routes through a chi router.
+type chiBackend struct {
+	chi.Router
+}
+
+func (b chiBackend) method(method, pattern string, handler http.Handler) {
+	b.Method(method, pattern, handler)
+}
+
+func (b chiBackend) group(fn func(routerBackend)) {
+	b.Group(func(sub chi.Router) { fn(chiBackend{sub}) })
+}
+
+func (b chiBackend) route(pattern string, fn func(routerBackend)) {
+	b.Route(pattern, func(sub chi.Router) { fn(chiBackend{sub}) })
+}
+
+func (b chiBackend) use(middlewares ...func(http.Handler) http.Handler) {
+	b.Use(middlewares...)
+}
+
+func (b chiBackend) middlewares() int {
+	return len(b.Middlewares())
 }
 
 // NewRouter creates a chi router with baseline middleware for the HTTP adapter.
@@ -52,7 +225,13 @@ func NewRouter() chi.Router {
 	return router
 }
 
-// RegisterRoutes invokes controller route registration functions.
+// RegisterRoutes invokes controller route registration functions. Controllers that
+// only implement module.Runnable or module.CommandRegistrar belong to another
+// transport and are skipped. When router records routes (see AsRouter), each route
+// is attributed to its controller key, a GuardedController's guards apply to its
+// routes, a VersionedController's versions are the default of its routes, and a
+// route registered by two controllers returns a RouteConflictError.
+// Controllers are registered in key order; RegisterApp uses module graph order.
 func RegisterRoutes(router Router, controllers map[string]any) error {
 	keys := make([]string, 0, len(controllers))
 	for name := range controllers {
@@ -60,19 +239,64 @@ func RegisterRoutes(router Router, controllers map[string]any) error {
 	}
 	sort.Strings(keys)
 
-	registrars := make([]RouteRegistrar, 0, len(keys))
-	for _, name := range keys {
-		controller := controllers[name]
-		registrar, ok := controller.(RouteRegistrar)
+	refs := make([]kernel.ControllerRef, 0, len(keys))
+	for _, key := range keys {
+		refs = append(refs, kernel.ControllerRef{Key: key, Controller: controllers[key]})
+	}
+	return registerControllers(router, refs)
+}
+
+func registerControllers(router Router, controllers []kernel.ControllerRef) error {
+	registrars := make([]RouteRegistrar, 0, len(controllers))
+	names := make([]string, 0, len(controllers))
+	for _, ref := range controllers {
+		registrar, ok := ref.Controller.(RouteRegistrar)
 		if !ok {
-			return &RouteRegistrationError{Name: name}
+			if isNonHTTPController(ref.Controller) {
+				continue
+			}
+			return &RouteRegistrationError{Name: ref.Key}
 		}
 		registrars = append(registrars, registrar)
+		names = append(names, ref.Key)
 	}
 
-	for _, registrar := range registrars {
+	adapter := adapterOf(router)
+	if adapter != nil {
+		defer adapter.routes.setOwner("", nil, nil)
+	}
+	for i, registrar := range registrars {
+		if adapter != nil {
+			var guards []Guard
+			if guarded, ok := registrar.(GuardedController); ok {
+				guards = guarded.Guards()
+			}
+			var versions []string
+			if versioned, ok := registrar.(VersionedController); ok {
+				versions = versioned.Versions()
+			}
+			adapter.routes.setOwner(names[i], guards, versions)
+		}
 		registrar.RegisterRoutes(router)
+		if adapter != nil {
+			if err := adapter.routes.takeErr(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+func isNonHTTPController(controller any) bool {
+	switch controller.(type) {
+	case nil:
+		return false
+	case module.Runnable, module.CommandRegistrar, module.HealthChecker:
+		return true
+	}
+	// gRPC controllers (modkit/grpc.ServiceRegistrar) are matched by method name
+	// so this package does not depend on gRPC.
+	_, ok := reflect.TypeOf(controller).MethodByName("RegisterServices")
+	return ok
+}
@@ -55,80 +55,91 @@ func TestRouter_DuplicateRouteLastWins(t *testing.T) {
 }
 
 func TestRouterGroup_RegistersGroupedRoutes(t *testing.T) {
-	router := chi.NewRouter()
-	r := AsRouter(router)
-
-	called := false
-	r.Group("/api", func(sub Router) {
-		sub.Handle(http.MethodGet, "/users", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
-			called = true
-		}))
-	})
+	forEachBackend(t, func(t *testing.T, router http.Handler, r Router) {
+		called := false
+		r.Group("/api", func(sub Router) {
+			sub.Handle(http.MethodGet, "/users", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				called = true
+			}))
+		})
 
-	req := httptest.NewRequest(http.MethodGet, "/api/users", http.NoBody)
-	rec := httptest.NewRecorder()
-	router.ServeHTTP(rec, req)
+		req := httptest.NewRequest(http.MethodGet, "/api/users", http.NoBody)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
 
-	if !called {
-		t.Fatal("expected grouped handler to be called")
-	}
+		if !called {
+			t.Fatal("expected grouped handler to be called")
+		}
+	})
 }
 
 func TestRouterUse_AttachesMiddleware(t *testing.T) {
-	router := chi.NewRouter()
-	r := AsRouter(router)
-
-	middlewareCalled := false
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-			middlewareCalled = true
-			next.ServeHTTP(w, req)
+	forEachBackend(t, func(t *testing.T, router http.Handler, r Router) {
+		middlewareCalled := false
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				middlewareCalled = true
+				next.ServeHTTP(w, req)
+			})
 		})
-	})
 
-	r.Handle(http.MethodGet, "/test", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+		r.Handle(http.MethodGet, "/test", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
 
-	req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
-	rec := httptest.NewRecorder()
-	router.ServeHTTP(rec, req)
+		req := httptest.NewRequest(http.MethodGet, "/test", http.NoBody)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
 
-	if !middlewareCalled {
-		t.Fatal("expected middleware to be called")
-	}
+		if !middlewareCalled {
+			t.Fatal("expected middleware to be called")
+		}
+	})
 }
 
 func TestRouterGroup_MiddlewareScopedToGroup(t *testing.T) {
-	router := chi.NewRouter()
-	r := AsRouter(router)
-
-	groupMiddlewareCalled := false
-	groupHandlerCalled := false
-
-	r.Handle(http.MethodGet, "/public", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
-
-	r.Group("/protected", func(sub Router) {
-		sub.Use(func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
-				groupMiddlewareCalled = true
-				next.ServeHTTP(w, req)
+	forEachBackend(t, func(t *testing.T, router http.Handler, r Router) {
+		groupMiddlewareCalled := false
+		groupHandlerCalled := false
+
+		r.Handle(http.MethodGet, "/public", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+
+		r.Group("/protected", func(sub Router) {
+			sub.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					groupMiddlewareCalled = true
+					next.ServeHTTP(w, req)
+				})
 			})
+			sub.Handle(http.MethodGet, "/resource", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+				groupHandlerCalled = true
+			}))
 		})
-		sub.Handle(http.MethodGet, "/resource", http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
-			groupHandlerCalled = true
-		}))
-	})
 
-	reqPublic := httptest.NewRequest(http.MethodGet, "/public", http.NoBody)
-	router.ServeHTTP(httptest.NewRecorder(), reqPublic)
+		reqPublic := httptest.NewRequest(http.MethodGet, "/public", http.NoBody)
+		router.ServeHTTP(httptest.NewRecorder(), reqPublic)
 
-	if groupMiddlewareCalled {
-		t.Fatal("group middleware should not affect routes outside group")
-	}
+		if groupMiddlewareCalled {
+			t.Fatal("group middleware should not affect routes outside group")
+		}
 
-	reqProtected := httptest.NewRequest(http.MethodGet, "/protected/resource", http.NoBody)
-	router.ServeHTTP(httptest.NewRecorder(), reqProtected)
+		reqProtected := httptest.NewRequest(http.MethodGet, "/protected/resource", http.NoBody)
+		router.ServeHTTP(httptest.NewRecorder(), reqProtected)
 
-	if !groupMiddlewareCalled || !groupHandlerCalled {
-		t.Fatal("expected group middleware and handler to be called")
-	}
+		if !groupMiddlewareCalled || !groupHandlerCalled {
+			t.Fatal("expected group middleware and handler to be called")
+		}
+	})
+}
+
+// forEachBackend runs fn against the chi adapter and ServeMux, with the handler
+// serving the routes registered through router.
+func forEachBackend(t *testing.T, fn func(t *testing.T, mux http.Handler, router Router)) {
+	t.Helper()
+	t.Run("chi", func(t *testing.T) {
+		mux := chi.NewRouter()
+		fn(t, mux, AsRouter(mux))
+	})
+	t.Run("servemux", func(t *testing.T) {
+		mux := NewServeMux()
+		fn(t, mux, mux)
+	})
 }
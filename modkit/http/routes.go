@@ -0,0 +1,266 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// RouteInfo describes a route registered through a Router adapter.
+type RouteInfo struct {
+	Method string `json:"method"`
+	// Pattern is the full pattern, including every Group prefix.
+	Pattern string `json:"pattern"`
+	// Version is the API version served by the route; empty for routes
+	// registered without versioning or declared VersionNeutral.
+	Version string `json:"version,omitempty"`
+	// Controller is the controller key that registered the route; empty when the
+	// route was registered outside RegisterRoutes.
+	Controller string `json:"controller,omitempty"`
+	// Middleware is the number of middlewares wrapping the route.
+	Middleware int `json:"middleware"`
+	// Guards is the number of guards protecting the route.
+	Guards int `json:"guards"`
+	// Meta is the metadata attached with Route.
+	Meta RouteMeta `json:"meta"`
+	// Security lists the schemes of guards implementing SecuritySchemer.
+	Security []SecurityScheme `json:"-"`
+	// Types is set for handlers built with Handle.
+	Types *HandlerTypes `json:"-"`
+}
+
+func (r RouteInfo) String() string {
+	return r.Method + " " + r.Pattern
+}
+
+// RouteLister is implemented by routers that record their routes.
+type RouteLister interface {
+	Routes() []RouteInfo
+}
+
+// Routes returns the routes recorded by router, sorted by pattern, method, and
+// version, with numeric parts of versions compared as numbers.
+// It returns nil when router does not record routes.
+func Routes(router Router) []RouteInfo {
+	lister, ok := router.(RouteLister)
+	if !ok {
+		return nil
+	}
+	return lister.Routes()
+}
+
+// WriteRoutes prints routes as an aligned table, for example at startup.
+func WriteRoutes(w io.Writer, routes []RouteInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(tw, "METHOD\tPATTERN\tVERSION\tCONTROLLER\tMIDDLEWARE\tGUARDS"); err != nil {
+		return err
+	}
+	for _, route := range routes {
+		controller := route.Controller
+		if controller == "" {
+			controller = "-"
+		}
+		version := route.Version
+		if version == "" {
+			version = "-"
+		}
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\n", route.Method, route.Pattern, version, controller, route.Middleware, route.Guards); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// routeTable records routes shared by a root adapter and its groups.
+type routeTable struct {
+	mu       sync.Mutex
+	routes   []RouteInfo
+	index    map[string]int
+	owner    string
+	guards   []Guard
+	versions []string
+	err      error
+
+	versioning  *Versioning
+	dispatchers map[string]*versionDispatcher
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{index: make(map[string]int), dispatchers: make(map[string]*versionDispatcher)}
+}
+
+// add records a route and reports whether it should be forwarded to the
+// underlying router. A route already owned by a different controller is a
+// conflict: it is not forwarded and the first conflict is kept in err. Routes
+// registered outside RegisterRoutes have no owner and never conflict.
+func (t *routeTable) add(info RouteInfo) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info.Controller = t.owner
+	key := info.Method + " " + routeKey(info.Pattern) + " " + info.Version
+	if idx, ok := t.index[key]; ok {
+		existing := t.routes[idx]
+		if existing.Controller != "" && info.Controller != "" && existing.Controller != info.Controller {
+			if t.err == nil {
+				t.err = &RouteConflictError{
+					Method:      info.Method,
+					Pattern:     info.Pattern,
+					Controllers: []string{existing.Controller, info.Controller},
+				}
+			}
+			return false
+		}
+		t.routes[idx] = info
+		return true
+	}
+	t.index[key] = len(t.routes)
+	t.routes = append(t.routes, info)
+	return true
+}
+
+func (t *routeTable) setOwner(owner string, guards []Guard, versions []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.owner = owner
+	t.guards = guards
+	t.versions = versions
+}
+
+func (t *routeTable) ownerGuards() []Guard {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.guards
+}
+
+func (t *routeTable) setVersioning(versioning Versioning) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	versioning = versioning.withDefaults()
+	t.versioning = &versioning
+}
+
+// routeVersions returns the active versioning and the versions a route with
+// meta is registered for; versioning is nil when it is not enabled.
+func (t *routeTable) routeVersions(meta RouteMeta) (*Versioning, []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.versioning == nil {
+		return nil, nil
+	}
+	return t.versioning, t.versioning.routeVersions(meta, t.versions)
+}
+
+// dispatcher returns the version dispatcher for method and pattern and whether
+// it was created by this call.
+func (t *routeTable) dispatcher(method, pattern string, versioning *Versioning) (*versionDispatcher, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := method + " " + routeKey(pattern)
+	if d, ok := t.dispatchers[key]; ok {
+		return d, false
+	}
+	d := &versionDispatcher{versioning: versioning, handlers: make(map[string]http.Handler)}
+	t.dispatchers[key] = d
+	return d, true
+}
+
+func (t *routeTable) takeErr() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	err := t.err
+	t.err = nil
+	return err
+}
+
+func (t *routeTable) list() []RouteInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	routes := append([]RouteInfo(nil), t.routes...)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		return versionLess(routes[i].Version, routes[j].Version)
+	})
+	return routes
+}
+
+// versionLess orders versions with digit runs compared as numbers, so "v2"
+// sorts before "v10" and "1.9" before "1.10".
+func versionLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := isDigit(a[0]), isDigit(b[0])
+		if da && db {
+			na, restA := digitRun(a)
+			nb, restB := digitRun(b)
+			na, nb = strings.TrimLeft(na, "0"), strings.TrimLeft(nb, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func digitRun(s string) (string, string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func joinPattern(prefix, pattern string) string {
+	if prefix == "" {
+		return pattern
+	}
+	if pattern == "/" || pattern == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + pattern
+}
+
+// routeKey normalizes parameter names so "/users/{id}" and "/users/{userID}"
+// are detected as the same route. Regexp constraints are kept.
+func routeKey(pattern string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			b.WriteString(pattern)
+			return b.String()
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			b.WriteString(pattern)
+			return b.String()
+		}
+		param := pattern[start+1 : start+end]
+		b.WriteString(pattern[:start])
+		b.WriteByte('{')
+		if colon := strings.IndexByte(param, ':'); colon >= 0 {
+			b.WriteString(param[colon:])
+		}
+		b.WriteByte('}')
+		pattern = pattern[start+end+1:]
+	}
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type routesController struct {
+	register func(Router)
+}
+
+func (c *routesController) RegisterRoutes(router Router) {
+	c.register(router)
+}
+
+func noContent() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestRoutes_RecordsPatternControllerAndMiddleware(t *testing.T) {
+	router := AsRouter(NewRouter())
+	passthrough := func(next http.Handler) http.Handler { return next }
+
+	err := RegisterRoutes(router, map[string]any{
+		"users:UsersController": &routesController{register: func(r Router) {
+			r.Group("/api", func(api Router) {
+				api.Use(passthrough)
+				api.Group("/users", func(users Router) {
+					users.Handle(http.MethodGet, "/{id}", noContent())
+				})
+				api.Handle(http.MethodPost, "/users", noContent())
+			})
+		}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	router.Handle(http.MethodGet, "/health", noContent())
+
+	routes := Routes(router)
+	want := []RouteInfo{
+		{Method: http.MethodPost, Pattern: "/api/users", Controller: "users:UsersController", Middleware: 4},
+		{Method: http.MethodGet, Pattern: "/api/users/{id}", Controller: "users:UsersController", Middleware: 4},
+		{Method: http.MethodGet, Pattern: "/health", Middleware: 3},
+	}
+	if len(routes) != len(want) {
+		t.Fatalf("expected %d routes, got %v", len(want), routes)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(routes[i], want[i]) {
+			t.Fatalf("route %d: expected %+v, got %+v", i, want[i], routes[i])
+		}
+	}
+}
+
+func TestRegisterRoutes_ConflictAcrossControllers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		err := RegisterRoutes(router, map[string]any{
+			"a:Users": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users/{id}", noContent())
+			}},
+			"b:Admin": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users/{userID}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(http.StatusTeapot)
+				}))
+			}},
+		})
+
+		var conflict *RouteConflictError
+		if !errors.As(err, &conflict) {
+			t.Fatalf("expected RouteConflictError, got %v", err)
+		}
+		if conflict.Method != http.MethodGet || conflict.Pattern != "/users/{userID}" {
+			t.Fatalf("unexpected conflict: %+v", conflict)
+		}
+		if conflict.Controllers[0] != "a:Users" || conflict.Controllers[1] != "b:Admin" {
+			t.Fatalf("unexpected controllers: %v", conflict.Controllers)
+		}
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", http.NoBody))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("expected first registration to be kept, got %d", rec.Code)
+		}
+	})
+}
+
+func TestRegisterRoutes_SameControllerMayReregister(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, _ http.Handler, router Router) {
+		err := RegisterRoutes(router, map[string]any{
+			"a:Users": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users", noContent())
+				r.Handle(http.MethodGet, "/users", noContent())
+				r.Handle(http.MethodPost, "/users", noContent())
+			}},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := len(Routes(router)); got != 2 {
+			t.Fatalf("expected 2 routes, got %d", got)
+		}
+	})
+}
+
+func TestRoutes_NonRecordingRouter(t *testing.T) {
+	if routes := Routes(nil); routes != nil {
+		t.Fatalf("expected nil routes, got %v", routes)
+	}
+}
+
+func TestWriteRoutes(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteRoutes(&buf, []RouteInfo{
+		{Method: http.MethodGet, Pattern: "/users", Controller: "users:UsersController", Middleware: 3},
+		{Method: http.MethodGet, Pattern: "/health", Middleware: 0},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header and 2 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "METHOD") || !strings.Contains(lines[1], "users:UsersController") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 6 || fields[2] != "-" || fields[3] != "-" {
+		t.Fatalf("expected placeholder version and controller, got %q", lines[2])
+	}
+}
+
+func TestRoutes_RecordsHandlerTypesAndSecurity(t *testing.T) {
+	type getRequest struct {
+		ID    int    `path:"id"`
+		Debug bool   `query:"debug"`
+		Name  string `json:"name"`
+	}
+	forEachBackend(t, func(t *testing.T, _ http.Handler, router Router) {
+		UseGuards(router, BearerJWT(JWTConfig{Key: testJWTSecret}), RequireRoles())
+		router.Handle(http.MethodGet, "/items/{id}", Handle(func(context.Context, getRequest) (NoContent, error) {
+			return NoContent{}, nil
+		}))
+		router.Handle(http.MethodGet, "/plain", http.NotFoundHandler())
+
+		routes := Routes(router)
+		typed := routes[0]
+		if typed.Types == nil || typed.Types.Request != reflect.TypeFor[getRequest]() || typed.Types.Status != http.StatusNoContent {
+			t.Fatalf("unexpected types: %+v", typed.Types)
+		}
+		if len(typed.Types.Params) != 2 || typed.Types.Params[0].In != "path" || typed.Types.Params[1].Name != "debug" {
+			t.Fatalf("unexpected params: %+v", typed.Types.Params)
+		}
+		if len(typed.Security) != 1 || typed.Security[0].Key != "bearerAuth" {
+			t.Fatalf("unexpected security: %+v", typed.Security)
+		}
+		if routes[1].Types != nil {
+			t.Fatalf("expected no types for plain handler, got %+v", routes[1].Types)
+		}
+	})
+}
+
+func TestVersionLess(t *testing.T) {
+	ordered := []string{"", "1", "1.9", "1.10", "2", "10", "v2", "v10", "v10beta"}
+	for i := 1; i < len(ordered); i++ {
+		if !versionLess(ordered[i-1], ordered[i]) || versionLess(ordered[i], ordered[i-1]) {
+			t.Fatalf("expected %q before %q", ordered[i-1], ordered[i])
+		}
+	}
+	if versionLess("02", "2") || versionLess("2", "02") {
+		t.Fatalf("expected leading zeros to compare equal")
+	}
+}
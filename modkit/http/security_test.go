@@ -0,0 +1,45 @@
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	cfg := DefaultSecurityHeaders()
+	cfg.HSTSMaxAge = 365 * 24 * time.Hour
+	cfg.HSTSIncludeSubdomains = true
+	handler := SecurityHeaders(cfg)(noContent())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	h := rec.Header()
+	if h.Get("X-Content-Type-Options") != "nosniff" || h.Get("X-Frame-Options") != "DENY" || h.Get("Referrer-Policy") != "no-referrer" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	if h.Get("Content-Security-Policy") == "" || h.Get("Cross-Origin-Opener-Policy") != "same-origin" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+	if h.Get("Strict-Transport-Security") != "" {
+		t.Fatalf("expected no HSTS without TLS, got %v", h)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+	req.TLS = &tls.ConnectionState{}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Fatalf("unexpected HSTS header: %q", got)
+	}
+}
+
+func TestSecurityHeaders_EmptyValuesOmitted(t *testing.T) {
+	rec := httptest.NewRecorder()
+	SecurityHeaders(SecurityHeadersConfig{FrameOptions: "SAMEORIGIN"})(noContent()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+	if rec.Header().Get("X-Frame-Options") != "SAMEORIGIN" || rec.Header().Get("X-Content-Type-Options") != "" {
+		t.Fatalf("unexpected headers: %v", rec.Header())
+	}
+}
@@ -0,0 +1,298 @@
+package http
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// wildcardParam names the ServeMux wildcard standing in for a trailing chi "*".
+const wildcardParam = "modkitWildcard"
+
+// ServeMux is a Router backed by http.ServeMux, for services that do not want a
+// third-party router. It supports the features of the adapter returned by
+// AsRouter (route table, guards, versioning, groups, and middleware) and serves
+// the registered routes.
+//
+// Patterns use the same syntax as with chi: "{id}" parameters, "{id:[0-9]+}"
+// parameters with a regexp constraint, and a trailing "*" wildcard, read with
+// PathParam. Parameters must span a whole path segment. As with http.ServeMux,
+// GET routes also serve HEAD requests, and ambiguous patterns such as "/{a}/x"
+// and "/x/{b}" panic when registered.
+type ServeMux struct {
+	routerAdapter
+	root *muxBackend
+}
+
+// NewServeMux returns an empty ServeMux router.
+func NewServeMux() *ServeMux {
+	root := newMuxBackend("")
+	return &ServeMux{
+		routerAdapter: routerAdapter{backend: root, routes: newRouteTable()},
+		root:          root,
+	}
+}
+
+// ServeHTTP dispatches the request through the middleware added with Use.
+func (m *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.root.stack.ServeHTTP(w, r)
+}
+
+// muxBackend is a scope of a ServeMux router. Mounted groups get their own
+// http.ServeMux so that their middleware also runs for unmatched requests under
+// the prefix, as with chi; inline groups share their parent's mux.
+type muxBackend struct {
+	mux    *http.ServeMux
+	prefix string
+	stack  *muxStack
+	routes map[string]*muxRoute
+	// inline holds the middleware of inline groups, applied to each route.
+	inline   []func(http.Handler) http.Handler
+	isInline bool
+}
+
+func newMuxBackend(prefix string) *muxBackend {
+	mux := http.NewServeMux()
+	return &muxBackend{
+		mux:    mux,
+		prefix: prefix,
+		stack:  &muxStack{next: mux},
+		routes: make(map[string]*muxRoute),
+	}
+}
+
+func (b *muxBackend) method(method, pattern string, handler http.Handler) {
+	full := joinPattern(b.prefix, pattern)
+	handler = chain(b.inline, handler)
+	b.add(method, full, handler)
+	if b.prefix != "" && (pattern == "/" || pattern == "") {
+		// A mounted chi router serves its "/" route with and without the
+		// trailing slash.
+		b.add(method, full+"/", handler)
+	}
+}
+
+func (b *muxBackend) add(method, pattern string, handler http.Handler) {
+	converted := convertMuxPattern(pattern)
+	key := method + " " + routeKey(converted.pattern)
+	route, ok := b.routes[key]
+	if !ok {
+		route = &muxRoute{params: converted.params}
+		b.routes[key] = route
+		b.mux.Handle(method+" "+converted.pattern, route)
+	}
+	route.set(routeKey(pattern), converted, handler)
+}
+
+func (b *muxBackend) group(fn func(routerBackend)) {
+	fn(&muxBackend{
+		mux:      b.mux,
+		prefix:   b.prefix,
+		stack:    b.stack,
+		routes:   b.routes,
+		inline:   append([]func(http.Handler) http.Handler(nil), b.inline...),
+		isInline: true,
+	})
+}
+
+func (b *muxBackend) route(pattern string, fn func(routerBackend)) {
+	sub := newMuxBackend(joinPattern(b.prefix, pattern))
+	handler := chain(b.inline, sub.stack)
+	if mount := strings.TrimSuffix(sub.prefix, "/"); mount == "" {
+		b.mux.Handle("/", handler)
+	} else {
+		mount = convertMuxPattern(mount).pattern
+		b.mux.Handle(mount, handler)
+		b.mux.Handle(mount+"/", handler)
+	}
+	fn(sub)
+}
+
+func (b *muxBackend) use(middlewares ...func(http.Handler) http.Handler) {
+	if b.isInline {
+		b.inline = append(b.inline, middlewares...)
+		return
+	}
+	b.stack.use(middlewares...)
+}
+
+func (b *muxBackend) middlewares() int {
+	return b.stack.len()
+}
+
+// muxStack wraps a mux with middleware, composed on the first request.
+type muxStack struct {
+	mu          sync.Mutex
+	middlewares []func(http.Handler) http.Handler
+	next        http.Handler
+	handler     http.Handler
+}
+
+func (s *muxStack) use(middlewares ...func(http.Handler) http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.middlewares = append(s.middlewares, middlewares...)
+	s.handler = nil
+}
+
+func (s *muxStack) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.middlewares)
+}
+
+func (s *muxStack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	if s.handler == nil {
+		s.handler = chain(s.middlewares, s.next)
+	}
+	handler := s.handler
+	s.mu.Unlock()
+	handler.ServeHTTP(w, r)
+}
+
+// muxRoute serves one ServeMux pattern. Several chi patterns can share it when
+// they differ only in parameter names or constraints: constrained handlers are
+// tried before unconstrained ones, and the matched values are also stored under
+// the handler's parameter names. Re-registering a pattern replaces its handler.
+type muxRoute struct {
+	// params are the parameter names of the registered ServeMux pattern.
+	params   []string
+	mu       sync.RWMutex
+	handlers []muxHandler
+}
+
+type muxHandler struct {
+	key     string
+	pattern muxPattern
+	handler http.Handler
+}
+
+func (m *muxRoute) set(key string, pattern muxPattern, handler http.Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.handlers {
+		if m.handlers[i].key == key {
+			m.handlers[i].handler = handler
+			return
+		}
+	}
+	entry := muxHandler{key: key, pattern: pattern, handler: handler}
+	if !pattern.constrained() {
+		m.handlers = append(m.handlers, entry)
+		return
+	}
+	i := 0
+	for i < len(m.handlers) && m.handlers[i].pattern.constrained() {
+		i++
+	}
+	m.handlers = append(m.handlers[:i], append([]muxHandler{entry}, m.handlers[i:]...)...)
+}
+
+func (m *muxRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	values := make([]string, len(m.params))
+	for i, name := range m.params {
+		values[i] = r.PathValue(name)
+	}
+
+	m.mu.RLock()
+	var match *muxHandler
+	for i := range m.handlers {
+		if m.handlers[i].pattern.matches(values) {
+			match = &m.handlers[i]
+			break
+		}
+	}
+	m.mu.RUnlock()
+
+	if match == nil {
+		http.NotFound(w, r)
+		return
+	}
+	for i, name := range match.pattern.params {
+		if name != m.params[i] {
+			r.SetPathValue(name, values[i])
+		}
+	}
+	match.handler.ServeHTTP(w, r)
+}
+
+// muxPattern is a chi pattern converted for http.ServeMux.
+type muxPattern struct {
+	pattern string
+	// params lists the parameter names in order; a trailing chi "*" is named
+	// wildcardParam.
+	params []string
+	// constraints holds the regexp of each parameter, nil when unconstrained.
+	constraints []*regexp.Regexp
+}
+
+func (p muxPattern) constrained() bool {
+	for _, re := range p.constraints {
+		if re != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (p muxPattern) matches(values []string) bool {
+	for i, re := range p.constraints {
+		if re != nil && !re.MatchString(values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// convertMuxPattern converts a chi pattern to an http.ServeMux path pattern.
+// "{id:re}" becomes "{id}" constrained by re, a trailing "*" becomes a remainder
+// wildcard, and a trailing slash matches only itself.
+func convertMuxPattern(pattern string) muxPattern {
+	var converted muxPattern
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end < 0 {
+			break
+		}
+		name, expr, constrained := strings.Cut(pattern[start+1:start+end], ":")
+		var re *regexp.Regexp
+		if constrained {
+			re = regexp.MustCompile("^(?:" + expr + ")$")
+		}
+		converted.params = append(converted.params, name)
+		converted.constraints = append(converted.constraints, re)
+		b.WriteString(pattern[:start])
+		b.WriteString("{" + name + "}")
+		pattern = pattern[start+end+1:]
+	}
+	b.WriteString(pattern)
+
+	path := b.String()
+	switch {
+	case path == "":
+		path = "/"
+	case strings.HasSuffix(path, "*"):
+		path = strings.TrimSuffix(path, "*") + "{" + wildcardParam + "...}"
+		converted.params = append(converted.params, wildcardParam)
+		converted.constraints = append(converted.constraints, nil)
+	}
+	if strings.HasSuffix(path, "/") {
+		path += "{$}"
+	}
+	converted.pattern = path
+	return converted
+}
+
+func chain(middlewares []func(http.Handler) http.Handler, handler http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func paramHandler(names ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, name := range names {
+			if i > 0 {
+				_, _ = w.Write([]byte(","))
+			}
+			_, _ = w.Write([]byte(PathParam(r, name)))
+		}
+	})
+}
+
+func TestBackends_PathPatterns(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		router.Handle(http.MethodGet, "/users/{id:[0-9]+}", paramHandler("id"))
+		router.Handle(http.MethodGet, "/users/{name}", Route(paramHandler("name"), Summary("by name")))
+		router.Handle(http.MethodGet, "/files/*", paramHandler("*"))
+		router.Group("/orgs/{org}", func(orgs Router) {
+			orgs.Handle(http.MethodGet, "/", paramHandler("org"))
+			orgs.Handle(http.MethodGet, "/teams/{team}", paramHandler("org", "team"))
+		})
+
+		cases := []struct {
+			method, path string
+			status       int
+			body         string
+		}{
+			{http.MethodGet, "/users/42", http.StatusOK, "42"},
+			{http.MethodGet, "/users/ada", http.StatusOK, "ada"},
+			{http.MethodGet, "/files/a/b.txt", http.StatusOK, "a/b.txt"},
+			{http.MethodGet, "/orgs/acme", http.StatusOK, "acme"},
+			{http.MethodGet, "/orgs/acme/", http.StatusOK, "acme"},
+			{http.MethodGet, "/orgs/acme/teams/core", http.StatusOK, "acme,core"},
+			{http.MethodPost, "/users/42", http.StatusMethodNotAllowed, ""},
+			{http.MethodGet, "/users", http.StatusNotFound, ""},
+			{http.MethodGet, "/orgs/acme/missing", http.StatusNotFound, ""},
+		}
+		for _, tc := range cases {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, http.NoBody))
+			if rec.Code != tc.status || (tc.body != "" && rec.Body.String() != tc.body) {
+				t.Fatalf("%s %s: expected %d %q, got %d %q", tc.method, tc.path, tc.status, tc.body, rec.Code, rec.Body.String())
+			}
+		}
+	})
+}
+
+func TestBackends_MiddlewareScopes(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		router.Use(headerMiddlewareFunc("root"))
+		router.Group("", func(inline Router) {
+			inline.Use(headerMiddlewareFunc("inline"))
+			inline.Handle(http.MethodGet, "/inline", noContent())
+		})
+		router.Group("/api", func(api Router) {
+			api.Use(headerMiddlewareFunc("api"))
+			api.Handle(http.MethodGet, "/items", noContent())
+		})
+		router.Handle(http.MethodGet, "/plain", noContent())
+
+		for path, want := range map[string]string{
+			"/inline":    "root,inline",
+			"/api/items": "root,api",
+			"/api/none":  "root,api",
+			"/plain":     "root",
+			"/missing":   "root",
+		} {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+			if got := joinValues(rec.Header().Values("X-Chain")); got != want {
+				t.Fatalf("%s: expected middleware %q, got %q", path, want, got)
+			}
+		}
+
+		for _, route := range Routes(router) {
+			want := map[string]int{"/inline": 2, "/api/items": 2, "/plain": 1}[route.Pattern]
+			if route.Middleware != want {
+				t.Fatalf("%s: expected %d middlewares, got %d", route.Pattern, want, route.Middleware)
+			}
+		}
+	})
+}
+
+func TestBackends_TypedHandlerPathParams(t *testing.T) {
+	type request struct {
+		ID int `path:"id"`
+	}
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		router.Group("/items", func(items Router) {
+			items.Handle(http.MethodGet, "/{id}", Handle(func(_ context.Context, req request) (request, error) {
+				return req, nil
+			}))
+		})
+
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/7", http.NoBody))
+		if rec.Code != http.StatusOK || rec.Body.String() != "{\"ID\":7}\n" {
+			t.Fatalf("unexpected response: %d %q", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestServeMux_ReregisterReplacesHandler(t *testing.T) {
+	mux := NewServeMux()
+	mux.Handle(http.MethodGet, "/dup", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	mux.Handle(http.MethodGet, "/dup", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/dup", http.NoBody))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected last handler to win, got %d", rec.Code)
+	}
+}
+
+func TestConvertMuxPattern(t *testing.T) {
+	tests := map[string]string{
+		"":                   "/{$}",
+		"/":                  "/{$}",
+		"/users/":            "/users/{$}",
+		"/users/{id:[0-9]+}": "/users/{id}",
+		"/files/*":           "/files/{" + wildcardParam + "...}",
+	}
+	for pattern, want := range tests {
+		if got := convertMuxPattern(pattern).pattern; got != want {
+			t.Fatalf("%q: expected %q, got %q", pattern, want, got)
+		}
+	}
+	converted := convertMuxPattern("/users/{id:[0-9]+}")
+	if !converted.matches([]string{"12"}) || converted.matches([]string{"12a"}) {
+		t.Fatalf("unexpected constraints: %v", converted.constraints)
+	}
+}
+
+func headerMiddlewareFunc(value string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Chain", value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func joinValues(values []string) string {
+	out := ""
+	for i, v := range values {
+		if i > 0 {
+			out += ","
+		}
+		out += v
+	}
+	return out
+}
@@ -2,17 +2,25 @@ package http
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"github.com/go-modkit/modkit/modkit/logging"
 )
 
 // ShutdownTimeout controls how long the server will wait for in-flight requests
 // to finish after receiving a shutdown signal.
 var ShutdownTimeout = 30 * time.Second
 
+// PreStopDelay is how long Serve keeps accepting connections after a shutdown
+// signal, with readiness failing, before it stops listening. Load balancers
+// use that time to stop routing new requests to the process.
+var PreStopDelay time.Duration
+
 var listenAndServe = func(server *http.Server) error {
 	return server.ListenAndServe()
 }
@@ -22,13 +30,17 @@ var shutdownServer = func(ctx context.Context, server *http.Server) error {
 }
 
 // Serve starts an HTTP server on the given address using the provided handler.
-// It handles SIGINT and SIGTERM for graceful shutdown.
+// It handles SIGINT and SIGTERM for graceful shutdown: readiness fails (see
+// ReadinessHandler), connections are accepted for PreStopDelay, and in-flight
+// requests get ShutdownTimeout to finish. A second signal ends the
+// PreStopDelay early. Each phase is logged through
+// slog.Default.
 func Serve(addr string, handler http.Handler) error {
-	server := &http.Server{
+	server, drain := withDrain(&http.Server{
 		Addr:              addr,
 		Handler:           handler,
 		ReadHeaderTimeout: 15 * time.Second,
-	}
+	})
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
@@ -49,10 +61,20 @@ func Serve(addr string, handler http.Handler) error {
 		}
 		return err
 	case <-sigCh:
-		ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		// A second signal cuts the pre-stop delay short.
+		drainCtx, cancel := context.WithCancel(context.Background())
 		defer cancel()
-
-		shutdownErr := shutdownServer(ctx, server)
+		go func() {
+			if _, ok := <-sigCh; ok {
+				cancel()
+			}
+		}()
+		shutdownErr := gracefulShutdown(drainCtx, server, drain, drainConfig{
+			preStopDelay: PreStopDelay,
+			timeout:      ShutdownTimeout,
+			logger:       logging.NewSlogLogger(slog.Default()),
+			shutdown:     shutdownServer,
+		})
 		err := <-errCh
 		if err == http.ErrServerClosed {
 			err = nil
@@ -0,0 +1,276 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/logging"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// Server is a configurable HTTP server whose lifecycle is driven by a context.
+// It implements module.Runnable, so it can also be passed to kernel.App.Run.
+type Server struct {
+	// Name identifies the server in errors, for example "public" or "admin".
+	Name string
+	// Addr is the TCP address to listen on when Listener is nil.
+	Addr    string
+	Handler http.Handler
+	// Listener, when set, is used instead of listening on Addr.
+	Listener net.Listener
+
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// PreStopDelay is how long the server keeps accepting connections once its
+	// context is canceled, with ReadinessHandler failing, before it stops
+	// listening.
+	PreStopDelay time.Duration
+	// ShutdownTimeout bounds the drain of in-flight requests. Zero uses the
+	// package-level ShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// Logger reports the drain phases. Nil discards them, unless the server
+	// runs under ServeWithOptions with a Logger.
+	Logger logging.Logger
+
+	// TLSCertFile and TLSKeyFile enable TLS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+	// H2C enables HTTP/2 over cleartext alongside HTTP/1.1.
+	H2C bool
+
+	// OnReady is called with the bound address once the server is listening.
+	OnReady func(addr net.Addr)
+}
+
+// DefaultReadHeaderTimeout is applied when Server.ReadHeaderTimeout is zero.
+const DefaultReadHeaderTimeout = 15 * time.Second
+
+// Run listens and serves until ctx is canceled, then drains: readiness fails,
+// connections are accepted for PreStopDelay, and in-flight requests get
+// ShutdownTimeout to finish. The pre-stop delay ends early at the deadline of
+// ctx, if it has one. It returns nil on a clean shutdown.
+func (s *Server) Run(ctx context.Context) error {
+	return s.run(ctx, nil)
+}
+
+func (s *Server) run(ctx context.Context, fallbackLogger logging.Logger) error {
+	if s.Handler == nil {
+		return &ServerConfigError{Name: s.Name, Reason: "handler is nil"}
+	}
+	if (s.TLSCertFile == "") != (s.TLSKeyFile == "") {
+		return &ServerConfigError{Name: s.Name, Reason: "TLSCertFile and TLSKeyFile must be set together"}
+	}
+
+	ln := s.Listener
+	if ln == nil {
+		var err error
+		ln, err = net.Listen("tcp", s.Addr)
+		if err != nil {
+			return &ServerError{Name: s.Name, Addr: s.Addr, Err: err}
+		}
+	}
+
+	server, drain := s.httpServer()
+	if s.OnReady != nil {
+		s.OnReady(ln.Addr())
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		if s.TLSCertFile != "" {
+			errCh <- server.ServeTLS(ln, s.TLSCertFile, s.TLSKeyFile)
+			return
+		}
+		errCh <- server.Serve(ln)
+	}()
+
+	select {
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return &ServerError{Name: s.Name, Addr: ln.Addr().String(), Err: err}
+	case <-ctx.Done():
+	}
+
+	timeout := s.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = ShutdownTimeout
+	}
+	logger := s.Logger
+	if logger == nil {
+		logger = fallbackLogger
+	}
+	drainCtx, cancel := drainContext(ctx)
+	defer cancel()
+	shutdownErr := gracefulShutdown(drainCtx, server, drain, drainConfig{
+		name:         s.Name,
+		preStopDelay: s.PreStopDelay,
+		timeout:      timeout,
+		logger:       logger,
+		shutdown:     shutdownServer,
+	})
+	err := <-errCh
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	if shutdownErr != nil {
+		return &ServerError{Name: s.Name, Addr: ln.Addr().String(), Err: shutdownErr}
+	}
+	if err != nil {
+		return &ServerError{Name: s.Name, Addr: ln.Addr().String(), Err: err}
+	}
+	return nil
+}
+
+func (s *Server) httpServer() (*http.Server, *drainState) {
+	readHeaderTimeout := s.ReadHeaderTimeout
+	if readHeaderTimeout <= 0 {
+		readHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler,
+		ReadTimeout:       s.ReadTimeout,
+		ReadHeaderTimeout: readHeaderTimeout,
+		WriteTimeout:      s.WriteTimeout,
+		IdleTimeout:       s.IdleTimeout,
+	}
+	if s.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetUnencryptedHTTP2(true)
+		server.Protocols = protocols
+	}
+	return withDrain(server)
+}
+
+// ServeOptions configures ServeWithOptions.
+type ServeOptions struct {
+	// Servers run together; the first failure stops the others.
+	Servers []*Server
+	// Runnables, such as a modkit/grpc server, run and stop together with
+	// Servers.
+	Runnables []module.Runnable
+	// App, when set, is shut down after every server has drained: cleanup hooks
+	// run first, then provider closers.
+	App *kernel.App
+	// AppShutdownTimeout bounds App shutdown. Zero uses ShutdownTimeout.
+	AppShutdownTimeout time.Duration
+	// Logger reports the drain phases of Servers without their own Logger and
+	// of App shutdown. Nil discards them.
+	Logger logging.Logger
+}
+
+// ServeWithOptions runs every server until ctx is canceled or one of them returns,
+// drains them (see Server.Run), and then shuts down the optional App. Unlike Serve it does not
+// handle signals; pass a context from signal.NotifyContext for that.
+func ServeWithOptions(ctx context.Context, opts ServeOptions) error {
+	if len(opts.Servers) == 0 && len(opts.Runnables) == 0 {
+		return &ServerConfigError{Reason: "no servers configured"}
+	}
+	runnables := make([]module.Runnable, 0, len(opts.Servers)+len(opts.Runnables))
+	for _, server := range opts.Servers {
+		if server == nil {
+			return &ServerConfigError{Reason: "nil server"}
+		}
+		runnables = append(runnables, server)
+	}
+	for _, runnable := range opts.Runnables {
+		if runnable == nil {
+			return &ServerConfigError{Reason: "nil runnable"}
+		}
+		runnables = append(runnables, runnable)
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = logging.NewNopLogger()
+	}
+	serveErr := runServers(ctx, runnables, logger)
+	if opts.App == nil {
+		return serveErr
+	}
+
+	timeout := opts.AppShutdownTimeout
+	if timeout <= 0 {
+		timeout = ShutdownTimeout
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	logger.Info("http drain: shutting down app", "timeout", timeout)
+	start := time.Now()
+	if err := shutdownApp(shutdownCtx, opts.App); err != nil {
+		logger.Error("http drain: app shutdown failed", "elapsed", time.Since(start), "error", err)
+		return errors.Join(serveErr, err)
+	}
+	logger.Info("http drain: app shut down", "elapsed", time.Since(start))
+	return serveErr
+}
+
+// runServers runs servers as one group, like kernel.App.Run: the first to
+// return, with or without an error, cancels the others, panics are reported as
+// errors, and context errors after cancellation count as a clean stop.
+func runServers(ctx context.Context, servers []module.Runnable, logger logging.Logger) error {
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server module.Runnable) {
+			defer wg.Done()
+			defer cancel()
+			if err := runServer(groupCtx, server, logger); err != nil {
+				if groupCtx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+					return
+				}
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(server)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+func runServer(ctx context.Context, server module.Runnable, logger logging.Logger) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	if s, ok := server.(*Server); ok {
+		return s.run(ctx, logger)
+	}
+	return server.Run(ctx)
+}
+
+func shutdownApp(ctx context.Context, app *kernel.App) error {
+	var errs []error
+	for _, hook := range app.CleanupHooks() {
+		if err := hook(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := app.CloseContext(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
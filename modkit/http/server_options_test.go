@@ -0,0 +1,386 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+type cleanupModule struct {
+	cleanup func(context.Context) error
+}
+
+func (m *cleanupModule) Definition() module.ModuleDef {
+	return module.ModuleDef{
+		Name: "app",
+		Providers: []module.ProviderDef{{
+			Token:   "app.resource",
+			Build:   func(module.Resolver) (any, error) { return struct{}{}, nil },
+			Cleanup: m.cleanup,
+		}},
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	})
+}
+
+func startServe(t *testing.T, ctx context.Context, opts ServeOptions) <-chan error {
+	t.Helper()
+	errCh := make(chan error, 1)
+	go func() { errCh <- ServeWithOptions(ctx, opts) }()
+	return errCh
+}
+
+func waitErr(t *testing.T, errCh <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeWithOptions did not return")
+		return nil
+	}
+}
+
+func getBody(t *testing.T, client *http.Client, url string) (string, *http.Response) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp
+}
+
+func TestServeWithOptions_ReadyCallbackAndContextShutdown(t *testing.T) {
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := startServe(t, ctx, ServeOptions{Servers: []*Server{{
+		Addr:    "127.0.0.1:0",
+		Handler: okHandler(),
+		OnReady: func(addr net.Addr) { ready <- addr },
+	}}})
+
+	addr := <-ready
+	if body, _ := getBody(t, http.DefaultClient, "http://"+addr.String()); body != "ok" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	cancel()
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServeWithOptions_CustomListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := startServe(t, ctx, ServeOptions{Servers: []*Server{{
+		Listener: ln,
+		Handler:  okHandler(),
+		OnReady:  func(addr net.Addr) { ready <- addr },
+	}}})
+
+	if addr := <-ready; addr.String() != ln.Addr().String() {
+		t.Fatalf("expected ready addr %s, got %s", ln.Addr(), addr)
+	}
+	cancel()
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServeWithOptions_AppShutdownRunsAfterDrain(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	app, err := kernel.Bootstrap(&cleanupModule{cleanup: func(context.Context) error {
+		record("app cleanup")
+		return nil
+	}})
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if _, err := app.Get("app.resource"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	requestStarted := make(chan struct{})
+	releaseRequest := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(requestStarted)
+		<-releaseRequest
+		record("request done")
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := startServe(t, ctx, ServeOptions{
+		Servers: []*Server{{Addr: "127.0.0.1:0", Handler: handler, OnReady: func(addr net.Addr) { ready <- addr }}},
+		App:     app,
+	})
+	addr := <-ready
+
+	clientErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr.String())
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		clientErr <- err
+	}()
+	<-requestStarted
+	cancel()
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("expected drain to wait for in-flight request, got %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(releaseRequest)
+
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	if err := <-clientErr; err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] != "request done" || events[1] != "app cleanup" {
+		t.Fatalf("unexpected shutdown order: %v", events)
+	}
+}
+
+func TestServeWithOptions_FailureStopsOtherServers(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer occupied.Close()
+
+	err = ServeWithOptions(context.Background(), ServeOptions{Servers: []*Server{
+		{Name: "public", Addr: "127.0.0.1:0", Handler: okHandler()},
+		{Name: "admin", Addr: occupied.Addr().String(), Handler: okHandler()},
+	}})
+
+	var serverErr *ServerError
+	if !errors.As(err, &serverErr) {
+		t.Fatalf("expected ServerError, got %v", err)
+	}
+	if serverErr.Name != "admin" {
+		t.Fatalf("expected admin server to fail, got %q", serverErr.Name)
+	}
+}
+
+type runnableFunc func(ctx context.Context) error
+
+func (f runnableFunc) Run(ctx context.Context) error { return f(ctx) }
+
+func TestServeWithOptions_RunsRunnablesWithServers(t *testing.T) {
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := startServe(t, ctx, ServeOptions{
+		Servers: []*Server{{Addr: "127.0.0.1:0", Handler: okHandler(), OnReady: func(net.Addr) { cancel() }}},
+		Runnables: []module.Runnable{runnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			close(stopped)
+			return ctx.Err()
+		})},
+	})
+
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected runnable to stop before ServeWithOptions returned")
+	}
+}
+
+func TestServeWithOptions_RunnableReturningStopsServers(t *testing.T) {
+	errCh := startServe(t, context.Background(), ServeOptions{
+		Servers:   []*Server{{Addr: "127.0.0.1:0", Handler: okHandler()}},
+		Runnables: []module.Runnable{runnableFunc(func(context.Context) error { return nil })},
+	})
+
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServeWithOptions_RunnablePanicAndDeadline(t *testing.T) {
+	errCh := startServe(t, context.Background(), ServeOptions{
+		Servers:   []*Server{{Addr: "127.0.0.1:0", Handler: okHandler()}},
+		Runnables: []module.Runnable{runnableFunc(func(context.Context) error { panic("boom") })},
+	})
+	if err := waitErr(t, errCh); err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("expected the panic to be reported, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	errCh = startServe(t, ctx, ServeOptions{
+		Servers: []*Server{{Addr: "127.0.0.1:0", Handler: okHandler()}},
+		Runnables: []module.Runnable{runnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})},
+	})
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected a deadline to be a clean stop, got %v", err)
+	}
+}
+
+func TestServeWithOptions_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ServeOptions
+	}{
+		{name: "no servers", opts: ServeOptions{}},
+		{name: "nil server", opts: ServeOptions{Servers: []*Server{nil}}},
+		{name: "nil runnable", opts: ServeOptions{Runnables: []module.Runnable{nil}}},
+		{name: "nil handler", opts: ServeOptions{Servers: []*Server{{Addr: "127.0.0.1:0"}}}},
+		{name: "cert without key", opts: ServeOptions{Servers: []*Server{{
+			Addr: "127.0.0.1:0", Handler: okHandler(), TLSCertFile: "cert.pem",
+		}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ServeWithOptions(context.Background(), tt.opts)
+			var cfgErr *ServerConfigError
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("expected ServerConfigError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_H2C(t *testing.T) {
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{Addr: "127.0.0.1:0", Handler: okHandler(), H2C: true, OnReady: func(addr net.Addr) { ready <- addr }}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+	addr := <-ready
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	body, resp := getBody(t, client, "http://"+addr.String())
+	if body != "ok" || resp.ProtoMajor != 2 {
+		t.Fatalf("expected HTTP/2 response, got proto %s body %q", resp.Proto, body)
+	}
+
+	cancel()
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func TestServer_TLS(t *testing.T) {
+	certFile, keyFile, pool := writeTestCert(t)
+	ready := make(chan net.Addr, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{
+		Addr:        "127.0.0.1:0",
+		Handler:     okHandler(),
+		TLSCertFile: certFile,
+		TLSKeyFile:  keyFile,
+		OnReady:     func(addr net.Addr) { ready <- addr },
+	}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+	addr := <-ready
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}}}
+	if body, _ := getBody(t, client, "https://"+addr.String()); body != "ok" {
+		t.Fatalf("unexpected body %q", body)
+	}
+
+	cancel()
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
+
+func writeTestCert(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+	return certFile, keyFile, pool
+}
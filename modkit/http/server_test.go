@@ -99,6 +99,59 @@ func TestServe_HandlesSignals_ReturnsNil(t *testing.T) {
 	}
 }
 
+func TestServe_SecondSignalEndsPreStopDelay(t *testing.T) {
+	originalListen := listenAndServe
+	originalShutdown := shutdownServer
+	originalDelay := PreStopDelay
+	defer func() {
+		listenAndServe = originalListen
+		shutdownServer = originalShutdown
+		PreStopDelay = originalDelay
+	}()
+
+	PreStopDelay = time.Minute
+	served := make(chan *http.Server, 1)
+	shutdownRequested := make(chan struct{})
+	listenAndServe = func(server *http.Server) error {
+		served <- server
+		<-shutdownRequested
+		return http.ErrServerClosed
+	}
+	shutdownServer = func(_ context.Context, _ *http.Server) error {
+		close(shutdownRequested)
+		return nil
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Serve("127.0.0.1:12345", NewRouter()) //nolint:gosec
+	}()
+	drainCtx := (<-served).BaseContext(nil)
+
+	proc, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("failed to find process: %v", err)
+	}
+	if err := proc.Signal(os.Interrupt); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+	for !Stopping(drainCtx) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := proc.Signal(os.Interrupt); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatalf("expected nil on clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second signal to end the pre-stop delay")
+	}
+}
+
 func TestServe_ShutdownWaitsForInFlightRequest(t *testing.T) {
 	originalListen := listenAndServe
 	originalShutdown := shutdownServer
@@ -0,0 +1,214 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSSEHeartbeat is the heartbeat interval used when SSEOptions.Heartbeat
+// is zero.
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// SSEOptions configures Server-Sent Event streams.
+type SSEOptions struct {
+	// Heartbeat is the interval of comment lines that keep idle connections
+	// open through proxies (default DefaultSSEHeartbeat; negative disables).
+	Heartbeat time.Duration
+	// Retry, when positive, tells clients how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// SSEEvent is a Server-Sent Event. Data may span several lines.
+type SSEEvent struct {
+	// ID is stored by the client and sent back as Last-Event-ID on reconnect.
+	ID string
+	// Event is the event type; empty dispatches a "message" event.
+	Event string
+	Data  string
+}
+
+// ErrSSEClosed is returned by SSEStream.Send after the stream is done.
+var ErrSSEClosed = errors.New("sse stream closed")
+
+// SSEStream writes Server-Sent Events to one client. Send is safe for
+// concurrent use.
+type SSEStream struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	r       *http.Request
+	mu      sync.Mutex
+	done    chan struct{}
+	once    sync.Once
+	stopped chan struct{}
+}
+
+// NewSSEStream starts an event stream on w: it writes the event-stream headers
+// and flushes them. The stream is done when the client disconnects, the server
+// drains (see Draining), or Close is called; a heartbeat runs until then. It
+// returns a 500 *Problem, without writing anything, when w cannot flush.
+func NewSSEStream(w http.ResponseWriter, r *http.Request, opts SSEOptions) (*SSEStream, error) {
+	if !canFlush(w) {
+		return nil, NewProblem(http.StatusInternalServerError, "streaming is not supported")
+	}
+	s := &SSEStream{
+		w:       w,
+		rc:      http.NewResponseController(w),
+		r:       r,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if opts.Retry > 0 {
+		_, _ = w.Write([]byte("retry: " + strconv.FormatInt(opts.Retry.Milliseconds(), 10) + "\n\n"))
+	}
+	if err := s.rc.Flush(); err != nil {
+		return nil, err
+	}
+
+	heartbeat := opts.Heartbeat
+	if heartbeat == 0 {
+		heartbeat = DefaultSSEHeartbeat
+	}
+	go s.watch(heartbeat)
+	return s, nil
+}
+
+// watch ends the stream on disconnect or drain and sends heartbeats.
+func (s *SSEStream) watch(heartbeat time.Duration) {
+	defer close(s.stopped)
+	var tick <-chan time.Time
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-s.r.Context().Done():
+			s.Close()
+			return
+		case <-Draining(s.r.Context()):
+			s.Close()
+			return
+		case <-s.done:
+			return
+		case <-tick:
+			if err := s.write(": heartbeat\n\n"); err != nil {
+				s.Close()
+				return
+			}
+		}
+	}
+}
+
+// LastEventID returns the Last-Event-ID header of a reconnecting client, so
+// the handler can resume after that event.
+func (s *SSEStream) LastEventID() string {
+	return s.r.Header.Get("Last-Event-ID")
+}
+
+// Done is closed when the stream ends.
+func (s *SSEStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// Send writes and flushes event. It returns ErrSSEClosed once the stream is
+// done and an error for IDs or event types containing line breaks.
+func (s *SSEStream) Send(event SSEEvent) error {
+	if strings.ContainsAny(event.ID, "\r\n\x00") || strings.ContainsAny(event.Event, "\r\n") {
+		return errors.New("sse: id and event must not contain line breaks")
+	}
+	var b strings.Builder
+	if event.ID != "" {
+		b.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		b.WriteString("event: " + event.Event + "\n")
+	}
+	// SSE treats CRLF, LF, and a bare CR as line ends; split on all of them so
+	// data cannot inject its own fields.
+	data := strings.ReplaceAll(strings.ReplaceAll(event.Data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+
+	if err := s.write(b.String()); err != nil {
+		s.Close()
+		return err
+	}
+	return nil
+}
+
+// SendJSON sends v encoded as JSON data with the given event type and ID.
+func (s *SSEStream) SendJSON(id, event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(SSEEvent{ID: id, Event: event, Data: string(data)})
+}
+
+func (s *SSEStream) write(chunk string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-s.done:
+		return ErrSSEClosed
+	default:
+	}
+	if _, err := s.w.Write([]byte(chunk)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// Close ends the stream. The handler should return once the stream is done.
+func (s *SSEStream) Close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		close(s.done)
+		s.mu.Unlock()
+	})
+}
+
+// SSE returns a handler that opens an event stream and calls fn with it. The
+// stream is closed when fn returns; fn should return when stream.Done() is
+// closed. Register it with Router.Handle like any other handler.
+func SSE(fn func(stream *SSEStream), opts SSEOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := NewSSEStream(w, r, opts)
+		if err != nil {
+			WriteError(w, r, err)
+			return
+		}
+		fn(stream)
+		stream.Close()
+		<-stream.stopped
+	})
+}
+
+// canFlush reports whether w, or a writer it wraps, implements http.Flusher.
+func canFlush(w http.ResponseWriter) bool {
+	for {
+		switch rw := w.(type) {
+		case http.Flusher:
+			return true
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return false
+		}
+	}
+}
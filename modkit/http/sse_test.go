@@ -0,0 +1,165 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSE_StreamsEvents(t *testing.T) {
+	srv := httptest.NewServer(SSE(func(stream *SSEStream) {
+		_ = stream.Send(SSEEvent{ID: "2", Event: "resume", Data: stream.LastEventID()})
+		_ = stream.Send(SSEEvent{Data: "line one\nline two"})
+		_ = stream.SendJSON("3", "user", map[string]string{"name": "ada"})
+	}, SSEOptions{Retry: 2 * time.Second}))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Fatalf("expected event-stream content type, got %q", got)
+	}
+	if got := resp.Header.Get("Cache-Control"); got != "no-cache" {
+		t.Fatalf("expected no-cache, got %q", got)
+	}
+	want := "retry: 2000\n\n" +
+		"id: 2\nevent: resume\ndata: 1\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"id: 3\nevent: user\ndata: {\"name\":\"ada\"}\n\n"
+	if string(body) != want {
+		t.Fatalf("unexpected stream:\n%q\nwant:\n%q", body, want)
+	}
+}
+
+func TestSSEStream_RejectsLineBreaksInID(t *testing.T) {
+	srv := httptest.NewServer(SSE(func(stream *SSEStream) {
+		if err := stream.Send(SSEEvent{ID: "1\n2", Data: "x"}); err == nil {
+			t.Error("expected error for multi-line id")
+		}
+	}, SSEOptions{}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestSSEStream_SplitsDataOnBareCR(t *testing.T) {
+	srv := httptest.NewServer(SSE(func(stream *SSEStream) {
+		_ = stream.Send(SSEEvent{Data: "x\rid: 9\revent: admin"})
+	}, SSEOptions{}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	want := "data: x\ndata: id: 9\ndata: event: admin\n\n"
+	if string(body) != want {
+		t.Fatalf("unexpected stream:\n%q\nwant:\n%q", body, want)
+	}
+}
+
+func TestSSE_HeartbeatAndDisconnect(t *testing.T) {
+	finished := make(chan error, 1)
+	srv := httptest.NewServer(SSE(func(stream *SSEStream) {
+		<-stream.Done()
+		finished <- stream.Send(SSEEvent{Data: "late"})
+	}, SSEOptions{Heartbeat: 10 * time.Millisecond}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != ": heartbeat\n" {
+		t.Fatalf("expected heartbeat, got %q (%v)", line, err)
+	}
+	_ = resp.Body.Close()
+
+	select {
+	case err := <-finished:
+		if !errors.Is(err, ErrSSEClosed) {
+			t.Fatalf("expected ErrSSEClosed after disconnect, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after client disconnect")
+	}
+}
+
+func TestNewSSEStream_RequiresFlusher(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := struct{ http.ResponseWriter }{rec}
+
+	_, err := NewSSEStream(w, httptest.NewRequest(http.MethodGet, "/", nil), SSEOptions{})
+
+	var problem *Problem
+	if !errors.As(err, &problem) || problem.Status != http.StatusInternalServerError {
+		t.Fatalf("expected 500 problem, got %v", err)
+	}
+	if rec.Header().Get("Content-Type") != "" {
+		t.Fatal("expected nothing written")
+	}
+}
+
+func TestSSE_EndsWhenServerDrains(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ended := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{Listener: ln, Handler: SSE(func(stream *SSEStream) {
+		<-stream.Done()
+		close(ended)
+	}, SSEOptions{Heartbeat: -1}), ShutdownTimeout: 5 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String())
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	cancel()
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end on drain")
+	}
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+	rest, _ := io.ReadAll(resp.Body)
+	if strings.TrimSpace(string(rest)) != "" {
+		t.Fatalf("unexpected data after drain: %q", rest)
+	}
+}
+
+func TestDraining_NilOutsideServer(t *testing.T) {
+	if Draining(context.Background()) != nil {
+		t.Fatal("expected nil channel outside Serve")
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/go-modkit/modkit/modkit/logging"
+	"github.com/go-modkit/modkit/modkit/tracing"
+)
+
+// TracingConfig configures the Tracing middleware.
+type TracingConfig struct {
+	// Tracer starts the server span of each request; the default propagates
+	// trace context without exporting spans.
+	Tracer *tracing.Tracer
+	// Logger is the base of the request-scoped logger; the default discards
+	// everything.
+	Logger logging.Logger
+}
+
+// Tracing returns middleware that continues the W3C trace context of the
+// incoming traceparent and tracestate headers, or starts a new trace, with a
+// server span per request. The span context, tracer, and a request-scoped
+// logger carrying request_id, trace_id, and span_id are stored in the request
+// context; read them with tracing.SpanContextFromContext and
+// logging.FromContext, and propagate to outgoing requests with tracing.Inject.
+// The span is named after the method and route pattern.
+//
+// Place it after chi's RequestID middleware so the request ID is known.
+func Tracing(cfg TracingConfig) func(http.Handler) http.Handler {
+	tracer := cfg.Tracer
+	if tracer == nil {
+		tracer = tracing.NewTracer(nil)
+	}
+	base := cfg.Logger
+	if base == nil {
+		base = logging.NewNopLogger()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if remote, ok := tracing.Extract(r.Header); ok {
+				ctx = tracing.ContextWithSpanContext(ctx, remote)
+			}
+			ctx, span := tracer.Start(ctx, r.Method)
+			defer span.End()
+
+			sc := span.SpanContext()
+			args := []any{"trace_id", sc.TraceID.String(), "span_id", sc.SpanID.String()}
+			if id := middleware.GetReqID(ctx); id != "" {
+				args = append([]any{"request_id", id}, args...)
+			}
+			ctx = logging.NewContext(ctx, base.With(args...))
+
+			r, pattern := CaptureRoute(r.WithContext(ctx))
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if route := pattern(); route != "" {
+				span.SetName(r.Method + " " + route)
+				span.SetAttribute("http.route", route)
+			}
+			span.SetAttribute("http.request.method", r.Method)
+			span.SetAttribute("http.response.status_code", status)
+			if status >= http.StatusInternalServerError {
+				span.RecordError(&statusError{status: status})
+			}
+		})
+	}
+}
+
+// statusError records a server error status on a span.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return "http status " + strconv.Itoa(e.status)
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/go-modkit/modkit/modkit/logging"
+	"github.com/go-modkit/modkit/modkit/tracing"
+)
+
+// fieldLogger records the fields added with With and the messages logged.
+type fieldLogger struct {
+	fields   map[string]any
+	messages *[]string
+}
+
+func newFieldLogger() *fieldLogger {
+	return &fieldLogger{fields: map[string]any{}, messages: &[]string{}}
+}
+
+func (l *fieldLogger) Debug(msg string, _ ...any) { *l.messages = append(*l.messages, msg) }
+func (l *fieldLogger) Info(msg string, _ ...any)  { *l.messages = append(*l.messages, msg) }
+func (l *fieldLogger) Warn(msg string, _ ...any)  { *l.messages = append(*l.messages, msg) }
+func (l *fieldLogger) Error(msg string, _ ...any) { *l.messages = append(*l.messages, msg) }
+func (l *fieldLogger) With(args ...any) logging.Logger {
+	next := &fieldLogger{fields: map[string]any{}, messages: l.messages}
+	for k, v := range l.fields {
+		next.fields[k] = v
+	}
+	for i := 0; i+1 < len(args); i += 2 {
+		next.fields[args[i].(string)] = args[i+1]
+	}
+	return next
+}
+
+func TestTracing_ContinuesIncomingTrace(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	base := newFieldLogger()
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID)
+	router.Use(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter), Logger: base}))
+
+	var scoped *fieldLogger
+	var spanCtx tracing.SpanContext
+	AsRouter(router).Handle(http.MethodGet, "/orders/{id}", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scoped, _ = logging.FromContext(r.Context()).(*fieldLogger)
+		spanCtx, _ = tracing.SpanContextFromContext(r.Context())
+		logging.FromContext(r.Context()).Info("handled")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("Tracestate", "congo=t61rcWkgMzE")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if spanCtx.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || spanCtx.TraceState != "congo=t61rcWkgMzE" {
+		t.Fatalf("expected incoming trace to continue, got %+v", spanCtx)
+	}
+	if scoped == nil {
+		t.Fatalf("expected request-scoped logger")
+	}
+	if id, _ := scoped.fields["request_id"].(string); id == "" {
+		t.Fatalf("expected request-scoped logger with request_id, got %+v", scoped)
+	}
+	if scoped.fields["trace_id"] != spanCtx.TraceID.String() || scoped.fields["span_id"] != spanCtx.SpanID.String() {
+		t.Fatalf("expected trace fields, got %+v", scoped.fields)
+	}
+	if len(*base.messages) != 1 {
+		t.Fatalf("expected scoped logger to log through the base logger")
+	}
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 exported span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /orders/{id}" || span.Parent.SpanID.String() != "00f067aa0ba902b7" || !span.Parent.Remote {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+	if span.Attributes["http.response.status_code"] != http.StatusCreated || span.Attributes["http.route"] != "/orders/{id}" {
+		t.Fatalf("unexpected attributes: %+v", span.Attributes)
+	}
+}
+
+func TestTracing_StartsNewTraceAndRecordsServerErrors(t *testing.T) {
+	exporter := tracing.NewInMemoryExporter()
+	router := chi.NewRouter()
+	router.Use(Tracing(TracingConfig{Tracer: tracing.NewTracer(exporter)}))
+	router.Get("/fail", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := logging.FromContext(r.Context()).(*fieldLogger); ok {
+			t.Fatalf("unexpected logger type")
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("Traceparent", "invalid")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if spans[0].Parent.IsValid() || spans[0].Err == nil || spans[0].Name != "GET /fail" {
+		t.Fatalf("expected root span with error, got %+v", spans[0])
+	}
+}
+
+func TestRequestLogger_AddsCorrelationFields(t *testing.T) {
+	var args []any
+	logger := &argsLogger{record: func(a []any) { args = a }}
+	router := chi.NewRouter()
+	router.Use(middleware.RequestID, Tracing(TracingConfig{}), RequestLogger(logger))
+	router.Get("/", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	fields := map[string]bool{}
+	for i := 0; i < len(args); i += 2 {
+		fields[args[i].(string)] = true
+	}
+	for _, key := range []string{"request_id", "trace_id", "span_id"} {
+		if !fields[key] {
+			t.Fatalf("expected %s in %v", key, args)
+		}
+	}
+}
+
+type argsLogger struct {
+	record func([]any)
+}
+
+func (l *argsLogger) Debug(string, ...any)       {}
+func (l *argsLogger) Info(_ string, args ...any) { l.record(args) }
+func (l *argsLogger) Warn(string, ...any)        {}
+func (l *argsLogger) Error(string, ...any)       {}
+func (l *argsLogger) With(...any) logging.Logger { return l }
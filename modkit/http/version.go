@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VersioningType selects where the requested API version is read from.
+type VersioningType string
+
+const (
+	// VersionURI serves each version under its own path prefix, for example
+	// "/v1/users" and "/v2/users".
+	VersionURI VersioningType = "uri"
+	// VersionHeader reads the version from a request header.
+	VersionHeader VersioningType = "header"
+	// VersionMediaType reads the version from an Accept media type parameter,
+	// for example "Accept: application/json;v=2".
+	VersionMediaType VersioningType = "media-type"
+)
+
+// VersionNeutral marks a route that is served regardless of the requested
+// version and, with VersionURI, without a version prefix.
+const VersionNeutral = "neutral"
+
+// Versioning configures API versioning for an adapter created by AsRouter.
+type Versioning struct {
+	// Type is the strategy; defaults to VersionURI.
+	Type VersioningType
+	// Prefix precedes the version in URIs (default "v").
+	Prefix string
+	// Header names the version header (default "X-API-Version").
+	Header string
+	// Key names the Accept media type parameter (default "v").
+	Key string
+	// Default is the version of routes that declare none and, with VersionHeader
+	// and VersionMediaType, of requests that do not ask for one.
+	Default string
+	// Deprecated adds deprecation headers to every route of a version.
+	Deprecated map[string]Deprecation
+}
+
+// Deprecation describes the Deprecation (RFC 9745), Sunset (RFC 8594), and
+// Link headers sent with deprecated routes.
+type Deprecation struct {
+	// Date is when the route was deprecated; when zero the header is "true".
+	Date time.Time `json:"date,omitzero"`
+	// Sunset is when the route stops being served.
+	Sunset time.Time `json:"sunset,omitzero"`
+	// Link points to migration documentation.
+	Link string `json:"link,omitempty"`
+}
+
+// VersionedController is implemented by controllers whose routes default to
+// the returned versions.
+type VersionedController interface {
+	Versions() []string
+}
+
+// VersioningRouter is implemented by routers that support API versioning.
+type VersioningRouter interface {
+	UseVersioning(versioning Versioning)
+}
+
+// UseVersioning enables versioning for routes registered afterwards on router.
+// It reports false when router does not support versioning.
+func UseVersioning(router Router, versioning Versioning) bool {
+	versioned, ok := router.(VersioningRouter)
+	if !ok {
+		return false
+	}
+	versioned.UseVersioning(versioning)
+	return true
+}
+
+// Versions declares the API versions served by the route, overriding the
+// controller's versions. Include VersionNeutral to serve it for any version.
+func Versions(versions ...string) RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Versions = append(h.meta.Versions, versions...)
+	}
+}
+
+// Deprecated adds deprecation headers to the route's responses.
+func Deprecated(deprecation Deprecation) RouteOption {
+	return func(h *routeHandler) {
+		h.meta.Deprecation = &deprecation
+	}
+}
+
+type versionKey struct{}
+
+// VersionFromContext returns the API version of the route serving the request.
+func VersionFromContext(ctx context.Context) (string, bool) {
+	version, ok := ctx.Value(versionKey{}).(string)
+	return version, ok
+}
+
+func (v Versioning) withDefaults() Versioning {
+	if v.Type == "" {
+		v.Type = VersionURI
+	}
+	if v.Prefix == "" {
+		v.Prefix = "v"
+	}
+	if v.Header == "" {
+		v.Header = "X-API-Version"
+	}
+	if v.Key == "" {
+		v.Key = "v"
+	}
+	return v
+}
+
+// routeVersions returns the versions a route is registered for; nil means the
+// route is version neutral.
+func (v *Versioning) routeVersions(meta RouteMeta, owner []string) []string {
+	versions := meta.Versions
+	if len(versions) == 0 {
+		versions = owner
+	}
+	if len(versions) == 0 && v.Default != "" {
+		versions = []string{v.Default}
+	}
+	if slices.Contains(versions, VersionNeutral) {
+		return nil
+	}
+	return versions
+}
+
+// requested returns the version asked for by the request, or "".
+func (v *Versioning) requested(r *http.Request) string {
+	switch v.Type {
+	case VersionHeader:
+		return strings.TrimSpace(r.Header.Get(v.Header))
+	case VersionMediaType:
+		for _, accept := range r.Header.Values("Accept") {
+			for _, mediaRange := range strings.Split(accept, ",") {
+				params := strings.Split(mediaRange, ";")
+				for _, param := range params[1:] {
+					key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+					if ok && strings.EqualFold(key, v.Key) {
+						return strings.Trim(value, `"`)
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// wrap stores version in the request context and writes Vary and deprecation
+// headers.
+func (v *Versioning) wrap(version string, meta RouteMeta, next http.Handler) http.Handler {
+	deprecation := meta.Deprecation
+	if deprecation == nil {
+		if d, ok := v.Deprecated[version]; ok {
+			deprecation = &d
+		}
+	}
+	vary := ""
+	switch v.Type {
+	case VersionHeader:
+		vary = v.Header
+	case VersionMediaType:
+		vary = "Accept"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if vary != "" {
+			w.Header().Add("Vary", vary)
+		}
+		if deprecation != nil {
+			deprecation.writeHeaders(w.Header())
+		}
+		if version != "" {
+			r = r.WithContext(context.WithValue(r.Context(), versionKey{}, version))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (d *Deprecation) writeHeaders(header http.Header) {
+	if d.Date.IsZero() {
+		header.Set("Deprecation", "true")
+	} else {
+		header.Set("Deprecation", "@"+strconv.FormatInt(d.Date.Unix(), 10))
+	}
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Link != "" {
+		header.Add("Link", fmt.Sprintf("<%s>; rel=\"deprecation\"", d.Link))
+	}
+}
+
+// versionDispatcher serves one method and pattern for VersionHeader and
+// VersionMediaType, choosing the handler by requested version. Handlers
+// registered without a version serve requests no other handler matches.
+type versionDispatcher struct {
+	versioning *Versioning
+	mu         sync.RWMutex
+	handlers   map[string]http.Handler
+}
+
+func (d *versionDispatcher) set(version string, handler http.Handler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[version] = handler
+}
+
+func (d *versionDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	version := d.versioning.requested(r)
+	if version == "" {
+		version = d.versioning.Default
+	}
+
+	d.mu.RLock()
+	handler, ok := d.handlers[version]
+	if !ok {
+		handler, ok = d.handlers[""]
+	}
+	d.mu.RUnlock()
+
+	if !ok {
+		detail := "API version is required"
+		if version != "" {
+			detail = fmt.Sprintf("API version %q is not supported", version)
+		}
+		WriteError(w, r, NewProblem(http.StatusNotFound, detail))
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func versionHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, _ := VersionFromContext(r.Context())
+		_, _ = w.Write([]byte(name + ":" + version))
+	})
+}
+
+type versionedController struct{}
+
+func (c *versionedController) Versions() []string { return []string{"1", "2"} }
+
+func (c *versionedController) RegisterRoutes(router Router) {
+	router.Handle(http.MethodGet, "/users", versionHandler("list"))
+	router.Handle(http.MethodGet, "/users/{id}", Route(versionHandler("get"), Versions("2")))
+	router.Handle(http.MethodGet, "/status", Route(versionHandler("status"), Versions(VersionNeutral)))
+}
+
+func serveVersion(t *testing.T, mux http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVersioning_URI(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		if !UseVersioning(router, Versioning{}) {
+			t.Fatal("expected adapter to support versioning")
+		}
+		router.Group("/api", func(api Router) {
+			if err := RegisterRoutes(api, map[string]any{"users:UsersController": &versionedController{}}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+
+		for path, want := range map[string]string{
+			"/api/v1/users":   "list:1",
+			"/api/v2/users":   "list:2",
+			"/api/v2/users/7": "get:2",
+			"/api/status":     "status:",
+		} {
+			rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != http.StatusOK || rec.Body.String() != want {
+				t.Fatalf("%s: expected %q, got %d %q", path, want, rec.Code, rec.Body.String())
+			}
+		}
+		if rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, "/api/v1/users/7", nil)); rec.Code != http.StatusNotFound {
+			t.Fatalf("expected 404 for undeclared version, got %d", rec.Code)
+		}
+
+		var routes []string
+		for _, route := range Routes(router) {
+			routes = append(routes, route.Pattern+" "+route.Version)
+		}
+		want := []string{"/api/status ", "/api/v1/users 1", "/api/v2/users 2", "/api/v2/users/{id} 2"}
+		if !reflect.DeepEqual(routes, want) {
+			t.Fatalf("unexpected routes: %v", routes)
+		}
+	})
+}
+
+func TestVersioning_Header(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		UseVersioning(router, Versioning{Type: VersionHeader, Default: "1"})
+		if err := RegisterRoutes(router, map[string]any{"users:UsersController": &versionedController{}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		cases := []struct {
+			path, version, want string
+			status              int
+		}{
+			{"/users", "2", "list:2", http.StatusOK},
+			{"/users", "", "list:1", http.StatusOK},
+			{"/users/7", "2", "get:2", http.StatusOK},
+			{"/users/7", "", "", http.StatusNotFound},
+			{"/users", "3", "", http.StatusNotFound},
+			{"/status", "3", "status:", http.StatusOK},
+		}
+		for _, tc := range cases {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.version != "" {
+				req.Header.Set("X-API-Version", tc.version)
+			}
+			rec := serveVersion(t, mux, req)
+			if rec.Code != tc.status || (tc.want != "" && rec.Body.String() != tc.want) {
+				t.Fatalf("%s@%s: expected %d %q, got %d %q", tc.path, tc.version, tc.status, tc.want, rec.Code, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK && tc.path == "/users" && rec.Header().Get("Vary") != "X-API-Version" {
+				t.Fatalf("expected Vary header, got %v", rec.Header())
+			}
+		}
+	})
+}
+
+func TestVersioning_MediaType(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		UseVersioning(router, Versioning{Type: VersionMediaType})
+		router.Handle(http.MethodGet, "/users", Route(versionHandler("list"), Versions("1", "2")))
+
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set("Accept", `text/html, application/json; q=0.9; v="2"`)
+		if rec := serveVersion(t, mux, req); rec.Body.String() != "list:2" || rec.Header().Get("Vary") != "Accept" {
+			t.Fatalf("unexpected response: %q %v", rec.Body.String(), rec.Header())
+		}
+
+		rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, "/users", nil))
+		if rec.Code != http.StatusNotFound || rec.Header().Get("Content-Type") != "application/problem+json" {
+			t.Fatalf("expected problem for missing version, got %d %v", rec.Code, rec.Header())
+		}
+	})
+}
+
+func TestVersioning_DeprecationHeaders(t *testing.T) {
+	deprecated := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		UseVersioning(router, Versioning{Deprecated: map[string]Deprecation{
+			"1": {Date: deprecated, Sunset: sunset, Link: "https://example.com/migrate"},
+		}})
+		router.Handle(http.MethodGet, "/users", Route(versionHandler("list"), Versions("1", "2")))
+		router.Handle(http.MethodGet, "/legacy", Route(versionHandler("legacy"), Versions("2"), Deprecated(Deprecation{})))
+
+		rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, "/v1/users", nil))
+		if got := rec.Header().Get("Deprecation"); got != "@1735689600" {
+			t.Fatalf("unexpected Deprecation header: %q", got)
+		}
+		if got := rec.Header().Get("Sunset"); got != "Thu, 01 Jan 2026 00:00:00 GMT" {
+			t.Fatalf("unexpected Sunset header: %q", got)
+		}
+		if got := rec.Header().Get("Link"); got != `<https://example.com/migrate>; rel="deprecation"` {
+			t.Fatalf("unexpected Link header: %q", got)
+		}
+
+		if rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, "/v2/users", nil)); rec.Header().Get("Deprecation") != "" {
+			t.Fatalf("expected version 2 not deprecated, got %v", rec.Header())
+		}
+		if rec := serveVersion(t, mux, httptest.NewRequest(http.MethodGet, "/v2/legacy", nil)); rec.Header().Get("Deprecation") != "true" {
+			t.Fatalf("expected route deprecation, got %v", rec.Header())
+		}
+	})
+}
+
+func TestVersioning_ConflictsPerVersion(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, _ http.Handler, router Router) {
+		UseVersioning(router, Versioning{Type: VersionHeader})
+		err := RegisterRoutes(router, map[string]any{
+			"a:V1": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users", Route(versionHandler("a"), Versions("1")))
+			}},
+			"b:V2": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users", Route(versionHandler("b"), Versions("2")))
+			}},
+			"c:V2": &routesController{register: func(r Router) {
+				r.Handle(http.MethodGet, "/users", Route(versionHandler("c"), Versions("2")))
+			}},
+		})
+		conflict, ok := err.(*RouteConflictError)
+		if !ok || !reflect.DeepEqual(conflict.Controllers, []string{"b:V2", "c:V2"}) {
+			t.Fatalf("expected conflict between version 2 controllers, got %v", err)
+		}
+	})
+}
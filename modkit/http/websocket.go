@@ -0,0 +1,340 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by the WebSocket handshake.
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// WebSocket close codes used by the gateway; see RFC 6455, section 7.4.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// WebSocketCloseError is returned by WebSocketConn reads once the connection is
+// closed, with the close code and reason sent by the peer or the server.
+type WebSocketCloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket closed: code=%d", e.Code)
+	}
+	return fmt.Sprintf("websocket closed: code=%d reason=%q", e.Code, e.Reason)
+}
+
+// WebSocketConn is an upgraded WebSocket connection. Writes are safe for
+// concurrent use; reads belong to the gateway's read loop.
+type WebSocketConn struct {
+	id      string
+	conn    net.Conn
+	rw      *bufio.ReadWriter
+	request *http.Request
+	ctx     context.Context
+	cancel  context.CancelFunc
+	opts    WebSocketOptions
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closeErr  *WebSocketCloseError
+}
+
+// ID returns a random identifier of the connection.
+func (c *WebSocketConn) ID() string { return c.id }
+
+// Request returns the upgrade request.
+func (c *WebSocketConn) Request() *http.Request { return c.request }
+
+// Context returns a context canceled when the connection closes.
+func (c *WebSocketConn) Context() context.Context { return c.ctx }
+
+// WriteText sends a text message.
+func (c *WebSocketConn) WriteText(data []byte) error {
+	return c.writeFrame(wsOpText, data)
+}
+
+// WriteBinary sends a binary message.
+func (c *WebSocketConn) WriteBinary(data []byte) error {
+	return c.writeFrame(wsOpBinary, data)
+}
+
+// Close sends a close frame with code and reason and closes the connection.
+// Closing an already closed connection does nothing.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.closeErr = &WebSocketCloseError{Code: code, Reason: reason}
+		payload := make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code)) //nolint:gosec // close codes fit in 16 bits
+		payload = append(payload, reason...)
+		if len(payload) > 125 {
+			payload = payload[:125]
+		}
+		err = c.writeFrame(wsOpClose, payload)
+		c.cancel()
+		_ = c.conn.Close()
+	})
+	return err
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.ctx.Err() != nil {
+		return net.ErrClosed
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	if c.opts.WriteTimeout > 0 {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(c.opts.WriteTimeout))
+	}
+	if _, err := c.rw.Write(header); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// readMessage returns the next text or binary message, answering pings and
+// close frames on the way.
+func (c *WebSocketConn) readMessage() (opcode byte, message []byte, err error) {
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			code, reason := CloseNormal, ""
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+				reason = string(payload[2:])
+			}
+			_ = c.Close(code, "")
+			return 0, nil, &WebSocketCloseError{Code: code, Reason: reason}
+		case wsOpText, wsOpBinary:
+			if opcode != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "expected continuation frame")
+			}
+			opcode = op
+		case wsOpContinuation:
+			if opcode == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message))+int64(len(payload)) > c.opts.MaxMessageSize {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		message = append(message, payload...)
+		if fin {
+			if opcode == wsOpText && !utf8.Valid(message) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8")
+			}
+			return opcode, message, nil
+		}
+	}
+}
+
+func (c *WebSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.rw, head[:]); err != nil {
+		return false, 0, nil, c.readErr(err)
+	}
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "reserved bits set")
+	}
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, c.fail(CloseProtocolError, "client frames must be masked")
+	}
+
+	length := int64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, c.readErr(err)
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+			return false, 0, nil, c.readErr(err)
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]) & (1<<63 - 1))
+	}
+	if opcode >= wsOpClose && (length > 125 || !fin) {
+		return false, 0, nil, c.fail(CloseProtocolError, "invalid control frame")
+	}
+	if length > c.opts.MaxMessageSize {
+		return false, 0, nil, c.fail(CloseMessageTooBig, "message too big")
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+		return false, 0, nil, c.readErr(err)
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, c.readErr(err)
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+// fail closes the connection for a protocol violation by the client.
+func (c *WebSocketConn) fail(code int, reason string) error {
+	_ = c.Close(code, reason)
+	return &WebSocketCloseError{Code: code, Reason: reason}
+}
+
+// readErr reports reads failing because the server closed the connection as
+// that close.
+func (c *WebSocketConn) readErr(err error) error {
+	if c.ctx.Err() != nil && c.closeErr != nil {
+		return c.closeErr
+	}
+	return err
+}
+
+// upgradeWebSocket performs the server handshake of RFC 6455.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request, opts WebSocketOptions) (*WebSocketConn, error) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		return nil, NewProblem(http.StatusMethodNotAllowed, "websocket upgrade requires GET")
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		problem := NewProblem(http.StatusUpgradeRequired, "websocket upgrade required")
+		problem.Headers = http.Header{"Upgrade": {"websocket"}}
+		return nil, problem
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		problem := NewProblem(http.StatusUpgradeRequired, "unsupported websocket version")
+		problem.Headers = http.Header{"Sec-WebSocket-Version": {"13"}}
+		return nil, problem
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, NewProblem(http.StatusBadRequest, "invalid Sec-WebSocket-Key")
+	}
+	if !opts.CheckOrigin(r) {
+		return nil, NewProblem(http.StatusForbidden, "origin not allowed")
+	}
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, NewProblem(http.StatusInternalServerError, "websocket upgrade not supported")
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	sum := sha1.Sum([]byte(key + wsGUID)) //nolint:gosec // mandated by RFC 6455
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := rw.WriteString(response); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	return &WebSocketConn{
+		id:      hex.EncodeToString(id[:]),
+		conn:    conn,
+		rw:      rw,
+		request: r,
+		ctx:     ctx,
+		cancel:  cancel,
+		opts:    opts,
+	}, nil
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SameOrigin allows upgrade requests without an Origin header or whose Origin
+// host matches the request host. It is the default WebSocketOptions.CheckOrigin.
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+var errUnknownMessageType = errors.New("unknown message type")
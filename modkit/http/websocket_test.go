@@ -0,0 +1,379 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// wsClient is a minimal WebSocket client speaking masked frames.
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, addr string, header http.Header) (*wsClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/ws", nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatalf("write handshake: %v", err)
+	}
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		t.Fatalf("read handshake: %v", err)
+	}
+	return &wsClient{t: t, conn: conn, r: r}, resp
+}
+
+func (c *wsClient) writeFrame(opcode byte, payload []byte) {
+	c.t.Helper()
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	}
+	mask := [4]byte{1, 2, 3, 4}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatalf("write frame: %v", err)
+	}
+}
+
+func (c *wsClient) readFrame() (byte, []byte) {
+	c.t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		c.t.Fatalf("read frame: %v", err)
+	}
+	length := int(head[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		_, _ = io.ReadFull(c.r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		c.t.Fatalf("read payload: %v", err)
+	}
+	return head[0] & 0x0F, payload
+}
+
+func (c *wsClient) send(messageType string, data any) {
+	c.t.Helper()
+	raw, _ := json.Marshal(data)
+	payload, _ := json.Marshal(WebSocketMessage{Type: messageType, Data: raw})
+	c.writeFrame(wsOpText, payload)
+}
+
+func (c *wsClient) receive() WebSocketMessage {
+	c.t.Helper()
+	opcode, payload := c.readFrame()
+	if opcode != wsOpText {
+		c.t.Fatalf("expected text frame, got opcode %d", opcode)
+	}
+	var message WebSocketMessage
+	if err := json.Unmarshal(payload, &message); err != nil {
+		c.t.Fatalf("decode message: %v", err)
+	}
+	return message
+}
+
+func (c *wsClient) expectClose(code int) {
+	c.t.Helper()
+	opcode, payload := c.readFrame()
+	if opcode != wsOpClose || len(payload) < 2 {
+		c.t.Fatalf("expected close frame, got opcode %d", opcode)
+	}
+	if got := int(binary.BigEndian.Uint16(payload)); got != code {
+		c.t.Fatalf("expected close code %d, got %d", code, got)
+	}
+}
+
+type chatGateway struct {
+	mu           sync.Mutex
+	connected    []string
+	disconnected chan error
+	reject       bool
+}
+
+func (g *chatGateway) Messages() map[string]MessageHandler {
+	return map[string]MessageHandler{
+		"echo": func(_ *WebSocketConn, data json.RawMessage) (any, error) {
+			var text string
+			if err := json.Unmarshal(data, &text); err != nil {
+				return nil, NewProblem(http.StatusBadRequest, "expected a string")
+			}
+			return strings.ToUpper(text), nil
+		},
+		"notify": func(conn *WebSocketConn, _ json.RawMessage) (any, error) {
+			return nil, conn.Send("notice", map[string]string{"id": conn.ID()})
+		},
+		"fail": func(*WebSocketConn, json.RawMessage) (any, error) {
+			return nil, errors.New("secret failure")
+		},
+	}
+}
+
+func (g *chatGateway) OnConnect(conn *WebSocketConn) error {
+	if g.reject {
+		return errors.New("rejected")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.connected = append(g.connected, conn.ID())
+	return nil
+}
+
+func (g *chatGateway) OnDisconnect(_ *WebSocketConn, err error) {
+	g.disconnected <- err
+}
+
+func newChatServer(t *testing.T, gateway *chatGateway, opts WebSocketOptions) string {
+	t.Helper()
+	srv := httptest.NewServer(WebSocket(gateway, opts))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestWebSocket_RoutesMessagesByType(t *testing.T) {
+	gateway := &chatGateway{disconnected: make(chan error, 1)}
+	client, resp := dialWebSocket(t, newChatServer(t, gateway, WebSocketOptions{}), nil)
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("unexpected accept key %q", got)
+	}
+
+	client.send("echo", "hello")
+	if msg := client.receive(); msg.Type != "echo" || string(msg.Data) != `"HELLO"` {
+		t.Fatalf("unexpected reply %+v", msg)
+	}
+
+	client.send("notify", nil)
+	msg := client.receive()
+	var notice map[string]string
+	_ = json.Unmarshal(msg.Data, &notice)
+	if msg.Type != "notice" || notice["id"] == "" || notice["id"] != gateway.connected[0] {
+		t.Fatalf("unexpected notice %+v", msg)
+	}
+
+	client.send("echo", 42)
+	var problem Problem
+	msg = client.receive()
+	_ = json.Unmarshal(msg.Data, &problem)
+	if msg.Type != ErrorMessageType || problem.Status != http.StatusBadRequest || problem.Detail != "expected a string" {
+		t.Fatalf("unexpected error message %+v", msg)
+	}
+
+	client.send("fail", nil)
+	msg = client.receive()
+	if msg.Type != ErrorMessageType || strings.Contains(string(msg.Data), "secret") {
+		t.Fatalf("expected mapped 500 without error text, got %s", msg.Data)
+	}
+
+	client.send("missing", nil)
+	msg = client.receive()
+	_ = json.Unmarshal(msg.Data, &problem)
+	if msg.Type != ErrorMessageType || problem.Status != http.StatusNotFound {
+		t.Fatalf("expected unknown type error, got %+v", msg)
+	}
+
+	client.writeFrame(wsOpText, []byte("not json"))
+	msg = client.receive()
+	_ = json.Unmarshal(msg.Data, &problem)
+	if msg.Type != ErrorMessageType || problem.Status != http.StatusBadRequest {
+		t.Fatalf("expected invalid message error, got %+v", msg)
+	}
+}
+
+func TestWebSocket_CloseHandshakeAndPing(t *testing.T) {
+	gateway := &chatGateway{disconnected: make(chan error, 1)}
+	client, _ := dialWebSocket(t, newChatServer(t, gateway, WebSocketOptions{}), nil)
+
+	client.writeFrame(wsOpPing, []byte("are you there"))
+	if opcode, payload := client.readFrame(); opcode != wsOpPong || string(payload) != "are you there" {
+		t.Fatalf("expected pong echo, got opcode %d %q", opcode, payload)
+	}
+
+	client.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, CloseNormal))
+	client.expectClose(CloseNormal)
+	if err := <-gateway.disconnected; err != nil {
+		t.Fatalf("expected nil disconnect error, got %v", err)
+	}
+}
+
+func TestWebSocket_ServerPings(t *testing.T) {
+	gateway := &chatGateway{disconnected: make(chan error, 1)}
+	client, _ := dialWebSocket(t, newChatServer(t, gateway, WebSocketOptions{PingInterval: 10 * time.Millisecond}), nil)
+
+	if opcode, _ := client.readFrame(); opcode != wsOpPing {
+		t.Fatalf("expected ping, got opcode %d", opcode)
+	}
+}
+
+func TestWebSocket_ProtocolViolations(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  WebSocketOptions
+		write func(c *wsClient)
+		code  int
+	}{
+		{
+			name:  "binary message",
+			write: func(c *wsClient) { c.writeFrame(wsOpBinary, []byte{1, 2}) },
+			code:  CloseUnsupportedData,
+		},
+		{
+			name:  "too big",
+			opts:  WebSocketOptions{MaxMessageSize: 8},
+			write: func(c *wsClient) { c.send("echo", "a long message") },
+			code:  CloseMessageTooBig,
+		},
+		{
+			name:  "invalid utf-8",
+			write: func(c *wsClient) { c.writeFrame(wsOpText, []byte{0xff, 0xfe}) },
+			code:  CloseInvalidPayload,
+		},
+		{
+			name: "unmasked frame",
+			write: func(c *wsClient) {
+				_, _ = c.conn.Write([]byte{0x81, 0x01, 'x'})
+			},
+			code: CloseProtocolError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gateway := &chatGateway{disconnected: make(chan error, 1)}
+			client, _ := dialWebSocket(t, newChatServer(t, gateway, tt.opts), nil)
+
+			tt.write(client)
+			client.expectClose(tt.code)
+
+			var closeErr *WebSocketCloseError
+			if err := <-gateway.disconnected; !errors.As(err, &closeErr) || closeErr.Code != tt.code {
+				t.Fatalf("expected close error %d, got %v", tt.code, err)
+			}
+		})
+	}
+}
+
+func TestWebSocket_ConnectHookRejects(t *testing.T) {
+	gateway := &chatGateway{disconnected: make(chan error, 1), reject: true}
+	client, _ := dialWebSocket(t, newChatServer(t, gateway, WebSocketOptions{}), nil)
+
+	client.expectClose(ClosePolicyViolation)
+	select {
+	case <-gateway.disconnected:
+		t.Fatal("OnDisconnect must not run for rejected connections")
+	default:
+	}
+}
+
+func TestWebSocket_HandshakeErrors(t *testing.T) {
+	gateway := &chatGateway{disconnected: make(chan error, 1)}
+	addr := newChatServer(t, gateway, WebSocketOptions{})
+
+	resp, err := http.Get("http://" + addr + "/ws")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUpgradeRequired || resp.Header.Get("Upgrade") != "websocket" {
+		t.Fatalf("expected 426 with Upgrade header, got %d", resp.StatusCode)
+	}
+	if resp.Header.Get("Content-Type") != ProblemContentType {
+		t.Fatalf("expected problem response, got %q", resp.Header.Get("Content-Type"))
+	}
+
+	_, resp = dialWebSocket(t, addr, http.Header{"Origin": {"https://evil.example"}})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for cross-origin upgrade, got %d", resp.StatusCode)
+	}
+
+	_, resp = dialWebSocket(t, addr, http.Header{"Sec-Websocket-Key": {"short"}})
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for invalid key, got %d", resp.StatusCode)
+	}
+}
+
+func TestWebSocket_RegistersThroughRouter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mux http.Handler, router Router) {
+		gateway := &chatGateway{disconnected: make(chan error, 1)}
+		router.Handle(http.MethodGet, "/ws", WebSocket(gateway, WebSocketOptions{}))
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		client, resp := dialWebSocket(t, strings.TrimPrefix(srv.URL, "http://"), nil)
+		if resp.StatusCode != http.StatusSwitchingProtocols {
+			t.Fatalf("expected 101, got %d", resp.StatusCode)
+		}
+		client.send("echo", "routed")
+		if msg := client.receive(); string(msg.Data) != `"ROUTED"` {
+			t.Fatalf("unexpected reply %+v", msg)
+		}
+	})
+}
+
+func TestWebSocket_ClosesWhenServerDrains(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	gateway := &chatGateway{disconnected: make(chan error, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := &Server{Listener: ln, Handler: WebSocket(gateway, WebSocketOptions{}), ShutdownTimeout: 5 * time.Second}
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.Run(ctx) }()
+
+	client, resp := dialWebSocket(t, ln.Addr().String(), nil)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101, got %d", resp.StatusCode)
+	}
+	client.send("echo", "before")
+	client.receive()
+
+	cancel()
+	client.expectClose(CloseGoingAway)
+	if err := <-gateway.disconnected; err != nil {
+		t.Fatalf("expected nil disconnect error for going away, got %v", err)
+	}
+	if err := waitErr(t, errCh); err != nil {
+		t.Fatalf("expected clean shutdown, got %v", err)
+	}
+}
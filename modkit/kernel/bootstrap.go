@@ -17,6 +17,7 @@ type bootstrapConfig struct {
 	firstOptionByTok  map[module.Token]int
 	optionNames       map[module.Token][]string
 	currentOptionIdx  int
+	observers         []Observer
 	err               error
 }
 
@@ -122,7 +123,7 @@ func BootstrapWithOptions(root module.Module, opts ...BootstrapOption) (*App, er
 		if !ok {
 			return nil, &OverrideTokenNotFoundError{Token: override.Token}
 		}
-		if !visibility[graph.Root][override.Token] {
+		if !visibility.visible(graph.Root, override.Token) {
 			return nil, &OverrideTokenNotVisibleFromRootError{Root: graph.Root, Token: override.Token}
 		}
 		entry.build = override.Build
@@ -130,7 +131,8 @@ func BootstrapWithOptions(root module.Module, opts ...BootstrapOption) (*App, er
 		providers[override.Token] = entry
 	}
 
-	container := newContainerWithProviders(providers, visibility)
+	container := newContainerWithProviders(graph, providers, visibility)
+	container.observers = cfg.observers
 
 	controllers := make(map[string]any)
 	perModule := make(map[string]map[string]bool)
@@ -2,6 +2,8 @@ package kernel
 
 import (
 	"context"
+	"slices"
+	"time"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
@@ -32,3 +34,37 @@ func WithProviderOverrides(overrides ...ProviderOverride) BootstrapOption {
 	copy(cloned, overrides)
 	return providerOverridesOption{overrides: cloned}
 }
+
+// ProviderBuildEvent describes the build of a provider. Duration includes
+// building the provider's dependencies on first use.
+type ProviderBuildEvent struct {
+	Module   string
+	Token    module.Token
+	Duration time.Duration
+	// Err is the build error, nil when the provider was built.
+	Err error
+}
+
+// Observer receives container lifecycle events. Implementations must be safe
+// for concurrent use; providers may be resolved from several goroutines.
+type Observer interface {
+	ProviderBuilt(event ProviderBuildEvent)
+}
+
+type observersOption struct {
+	observers []Observer
+}
+
+func (o observersOption) apply(cfg *bootstrapConfig) {
+	for _, observer := range o.observers {
+		if observer != nil {
+			cfg.observers = append(cfg.observers, observer)
+		}
+	}
+}
+
+// WithObservers registers observers notified of every provider build, including
+// those triggered by controllers during bootstrap.
+func WithObservers(observers ...Observer) BootstrapOption {
+	return observersOption{observers: slices.Clone(observers)}
+}
@@ -2,6 +2,7 @@ package kernel_test
 
 import (
 	"errors"
+	"sync"
 	"testing"
 
 	"github.com/go-modkit/modkit/modkit/kernel"
@@ -286,3 +287,57 @@ func TestBootstrapWithOptions_RejectsNilOverrideBuild(t *testing.T) {
 		t.Fatalf("unexpected error type: %T", err)
 	}
 }
+
+type recordingObserver struct {
+	mu     sync.Mutex
+	events []kernel.ProviderBuildEvent
+}
+
+func (o *recordingObserver) ProviderBuilt(event kernel.ProviderBuildEvent) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+}
+
+func TestBootstrapWithOptions_ObserversReceiveProviderBuilds(t *testing.T) {
+	okToken := module.Token("svc.ok")
+	failToken := module.Token("svc.fail")
+	boom := errors.New("boom")
+
+	root := mod("root", nil,
+		[]module.ProviderDef{
+			{Token: okToken, Build: func(module.Resolver) (any, error) { return "value", nil }},
+			{Token: failToken, Build: func(module.Resolver) (any, error) { return nil, boom }},
+		},
+		[]module.ControllerDef{{
+			Name: "Controller",
+			Build: func(r module.Resolver) (any, error) {
+				return r.Get(okToken)
+			},
+		}},
+		[]module.Token{okToken, failToken},
+	)
+
+	observer := &recordingObserver{}
+	app, err := kernel.BootstrapWithOptions(root, kernel.WithObservers(observer, nil))
+	if err != nil {
+		t.Fatalf("BootstrapWithOptions failed: %v", err)
+	}
+	if _, err := app.Get(failToken); !errors.Is(err, boom) {
+		t.Fatalf("expected build error, got %v", err)
+	}
+	if _, err := app.Get(okToken); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if len(observer.events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", observer.events)
+	}
+	built, failed := observer.events[0], observer.events[1]
+	if built.Module != "root" || built.Token != okToken || built.Err != nil || built.Duration < 0 {
+		t.Fatalf("unexpected build event: %+v", built)
+	}
+	if failed.Token != failToken || !errors.Is(failed.Err, boom) {
+		t.Fatalf("unexpected failure event: %+v", failed)
+	}
+}
@@ -0,0 +1,621 @@
+package kernel
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrorCode is a stable, machine-readable identifier for a kernel problem.
+// Codes never change once published, so tools and tests can match on them
+// instead of parsing error strings.
+type ErrorCode string
+
+// Graph and module definition codes.
+const (
+	// CodeRootModuleNil reports a nil root module.
+	CodeRootModuleNil ErrorCode = "MODKIT_E_ROOT_MODULE_NIL"
+	// CodeInvalidModuleName reports an empty or invalid module name.
+	CodeInvalidModuleName ErrorCode = "MODKIT_E_INVALID_MODULE_NAME"
+	// CodeModuleNotPointer reports a module passed by value.
+	CodeModuleNotPointer ErrorCode = "MODKIT_E_MODULE_NOT_POINTER"
+	// CodeNilImport reports a nil entry in a module's Imports.
+	CodeNilImport ErrorCode = "MODKIT_E_NIL_IMPORT"
+	// CodeInvalidModuleDef reports invalid module metadata without a more specific code.
+	CodeInvalidModuleDef ErrorCode = "MODKIT_E_INVALID_MODULE_DEF"
+	// CodeModuleNameEmpty reports a module with an empty name.
+	CodeModuleNameEmpty ErrorCode = "MODKIT_E_MODULE_NAME_EMPTY"
+	// CodeProviderTokenEmpty reports a provider with an empty token.
+	CodeProviderTokenEmpty ErrorCode = "MODKIT_E_PROVIDER_TOKEN_EMPTY"
+	// CodeProviderBuildNil reports a provider without a Build function.
+	CodeProviderBuildNil ErrorCode = "MODKIT_E_PROVIDER_BUILD_NIL"
+	// CodeControllerNameEmpty reports a controller with an empty name.
+	CodeControllerNameEmpty ErrorCode = "MODKIT_E_CONTROLLER_NAME_EMPTY"
+	// CodeControllerBuildNil reports a controller without a Build function.
+	CodeControllerBuildNil ErrorCode = "MODKIT_E_CONTROLLER_BUILD_NIL"
+	// CodeExportTokenEmpty reports an empty token in a module's Exports.
+	CodeExportTokenEmpty ErrorCode = "MODKIT_E_EXPORT_TOKEN_EMPTY"
+	// CodeDuplicateModuleName reports two distinct modules sharing a name.
+	CodeDuplicateModuleName ErrorCode = "MODKIT_E_DUPLICATE_MODULE_NAME"
+	// CodeModuleCycle reports a cycle in module imports.
+	CodeModuleCycle ErrorCode = "MODKIT_E_MODULE_CYCLE"
+	// CodeDuplicateProviderToken reports a token provided by more than one module.
+	CodeDuplicateProviderToken ErrorCode = "MODKIT_E_DUPLICATE_PROVIDER_TOKEN"
+	// CodeDuplicateControllerName reports two controllers with the same name in one module.
+	CodeDuplicateControllerName ErrorCode = "MODKIT_E_DUPLICATE_CONTROLLER_NAME"
+	// CodeExportNotVisible reports an export the module cannot see.
+	CodeExportNotVisible ErrorCode = "MODKIT_E_EXPORT_NOT_VISIBLE"
+	// CodeExportAmbiguous reports a re-export provided by several imports.
+	CodeExportAmbiguous ErrorCode = "MODKIT_E_EXPORT_AMBIGUOUS"
+	// CodeGraphValidation reports an aggregated ValidateGraph failure.
+	CodeGraphValidation ErrorCode = "MODKIT_E_GRAPH_VALIDATION"
+	// CodeNilGraph reports an operation that received a nil graph.
+	CodeNilGraph ErrorCode = "MODKIT_E_NIL_GRAPH"
+	// CodeNilApp reports an operation that received a nil app.
+	CodeNilApp ErrorCode = "MODKIT_E_NIL_APP"
+	// CodeGraphNodeNotFound reports a graph node lookup that failed.
+	CodeGraphNodeNotFound ErrorCode = "MODKIT_E_GRAPH_NODE_NOT_FOUND"
+	// CodeUnsupportedGraphFormat reports an unknown graph export format.
+	CodeUnsupportedGraphFormat ErrorCode = "MODKIT_E_UNSUPPORTED_GRAPH_FORMAT"
+	// CodeUnsupportedDiagnosticFormat reports an unknown Diagnose format.
+	CodeUnsupportedDiagnosticFormat ErrorCode = "MODKIT_E_UNSUPPORTED_DIAGNOSTIC_FORMAT"
+)
+
+// Resolution and bootstrap codes.
+const (
+	// CodeTokenNotVisible reports a resolution of a token the module cannot see.
+	CodeTokenNotVisible ErrorCode = "MODKIT_E_TOKEN_NOT_VISIBLE"
+	// CodeProviderNotFound reports a resolution of a token no module provides.
+	CodeProviderNotFound ErrorCode = "MODKIT_E_PROVIDER_NOT_FOUND"
+	// CodeProviderCycle reports a provider that depends on itself.
+	CodeProviderCycle ErrorCode = "MODKIT_E_PROVIDER_CYCLE"
+	// CodeProviderBuildFailed reports a provider Build function that returned an error.
+	CodeProviderBuildFailed ErrorCode = "MODKIT_E_PROVIDER_BUILD_FAILED"
+	// CodeControllerBuildFailed reports a controller Build function that returned an error.
+	CodeControllerBuildFailed ErrorCode = "MODKIT_E_CONTROLLER_BUILD_FAILED"
+	// CodeOverrideTokenNotFound reports an override for a token no module provides.
+	CodeOverrideTokenNotFound ErrorCode = "MODKIT_E_OVERRIDE_TOKEN_NOT_FOUND"
+	// CodeOverrideTokenNotVisibleFromRoot reports an override for a token the root cannot see.
+	CodeOverrideTokenNotVisibleFromRoot ErrorCode = "MODKIT_E_OVERRIDE_TOKEN_NOT_VISIBLE_FROM_ROOT"
+	// CodeDuplicateOverrideToken reports a token overridden twice in one option.
+	CodeDuplicateOverrideToken ErrorCode = "MODKIT_E_DUPLICATE_OVERRIDE_TOKEN"
+	// CodeBootstrapOptionConflict reports several options mutating the same token.
+	CodeBootstrapOptionConflict ErrorCode = "MODKIT_E_BOOTSTRAP_OPTION_CONFLICT"
+	// CodeNilBootstrapOption reports a nil bootstrap option.
+	CodeNilBootstrapOption ErrorCode = "MODKIT_E_NIL_BOOTSTRAP_OPTION"
+	// CodeOverrideBuildNil reports an override without a Build function.
+	CodeOverrideBuildNil ErrorCode = "MODKIT_E_OVERRIDE_BUILD_NIL"
+)
+
+// Runner and command codes.
+const (
+	// CodeRunnableFailed reports a runnable started by App.Run that failed.
+	CodeRunnableFailed ErrorCode = "MODKIT_E_RUNNABLE_FAILED"
+	// CodeNilRunnable reports a nil runnable passed to App.Run.
+	CodeNilRunnable ErrorCode = "MODKIT_E_NIL_RUNNABLE"
+	// CodeInvalidCommand reports a command registered without a name or run func.
+	CodeInvalidCommand ErrorCode = "MODKIT_E_INVALID_COMMAND"
+	// CodeDuplicateCommand reports a command name registered by several controllers.
+	CodeDuplicateCommand ErrorCode = "MODKIT_E_DUPLICATE_COMMAND"
+	// CodeCommandNotFound reports a RunCommand call for an unknown command.
+	CodeCommandNotFound ErrorCode = "MODKIT_E_COMMAND_NOT_FOUND"
+	// CodeHealthCheckFailed reports a controller health check that failed.
+	CodeHealthCheckFailed ErrorCode = "MODKIT_E_HEALTH_CHECK_FAILED"
+)
+
+// DiagnosticError is implemented by errors that carry a stable code, structured
+// fields, and a remediation hint. Kernel, config, and data errors implement it.
+// Codes are plain strings so packages outside the kernel can implement the
+// interface without importing it.
+type DiagnosticError interface {
+	error
+	Code() string
+	Fields() map[string]any
+	Hint() string
+}
+
+var sentinelCodes = map[error]ErrorCode{
+	ErrNilGraph:          CodeNilGraph,
+	ErrNilApp:            CodeNilApp,
+	ErrGraphNodeNotFound: CodeGraphNodeNotFound,
+	ErrExportAmbiguous:   CodeExportAmbiguous,
+}
+
+// CodeOf returns the stable code of the outermost coded error in err's chain,
+// or an empty string if none is found.
+func CodeOf(err error) string {
+	for err != nil {
+		if code := ownCode(err); code != "" {
+			return code
+		}
+		err = errors.Unwrap(err)
+	}
+	return ""
+}
+
+func ownCode(err error) string {
+	if d, ok := err.(DiagnosticError); ok {
+		return d.Code()
+	}
+	if code, ok := sentinelCodes[err]; ok {
+		return string(code)
+	}
+	return ""
+}
+
+// errorCode maps a structural kernel error to its stable code.
+func errorCode(err error) ErrorCode {
+	return ErrorCode(ownCode(err))
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, 0, len(items))
+	for _, item := range items {
+		quoted = append(quoted, fmt.Sprintf("%q", item))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Code returns the stable error code.
+func (e *UnsupportedGraphFormatError) Code() string { return string(CodeUnsupportedGraphFormat) }
+
+// Fields returns structured error fields.
+func (e *UnsupportedGraphFormatError) Fields() map[string]any {
+	return map[string]any{"format": string(e.Format)}
+}
+
+// Hint returns a remediation hint.
+func (e *UnsupportedGraphFormatError) Hint() string {
+	return fmt.Sprintf("use %q or %q as the graph format", GraphFormatMermaid, GraphFormatDOT)
+}
+
+// Code returns the stable error code.
+func (e *GraphNodeNotFoundError) Code() string { return string(CodeGraphNodeNotFound) }
+
+// Fields returns structured error fields.
+func (e *GraphNodeNotFoundError) Fields() map[string]any {
+	return map[string]any{"node": e.Node}
+}
+
+// Hint returns a remediation hint.
+func (e *GraphNodeNotFoundError) Hint() string {
+	return fmt.Sprintf("build the graph with BuildGraph so Nodes contains %q", e.Node)
+}
+
+// Code returns the stable error code.
+func (e *RootModuleNilError) Code() string { return string(CodeRootModuleNil) }
+
+// Fields returns structured error fields.
+func (e *RootModuleNilError) Fields() map[string]any { return map[string]any{} }
+
+// Hint returns a remediation hint.
+func (e *RootModuleNilError) Hint() string {
+	return "pass a non-nil root module pointer to Bootstrap"
+}
+
+// Code returns the stable error code.
+func (e *InvalidModuleNameError) Code() string { return string(CodeInvalidModuleName) }
+
+// Fields returns structured error fields.
+func (e *InvalidModuleNameError) Fields() map[string]any {
+	return map[string]any{"name": e.Name}
+}
+
+// Hint returns a remediation hint.
+func (e *InvalidModuleNameError) Hint() string {
+	return "give the module a unique, non-empty Name"
+}
+
+// Code returns the stable error code.
+func (e *ModuleNotPointerError) Code() string { return string(CodeModuleNotPointer) }
+
+// Fields returns structured error fields.
+func (e *ModuleNotPointerError) Fields() map[string]any {
+	return map[string]any{"module": e.Module}
+}
+
+// Hint returns a remediation hint.
+func (e *ModuleNotPointerError) Hint() string {
+	return fmt.Sprintf("pass module %q by pointer (for example &MyModule{}) so its identity is stable", e.Module)
+}
+
+// Code returns the stable error code. Module definition errors found during graph
+// construction carry a specific code such as MODKIT_E_PROVIDER_BUILD_NIL.
+func (e *InvalidModuleDefError) Code() string {
+	if e.code != "" {
+		return string(e.code)
+	}
+	return string(CodeInvalidModuleDef)
+}
+
+// Fields returns structured error fields.
+func (e *InvalidModuleDefError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "reason": e.Reason}
+}
+
+// Hint returns a remediation hint.
+func (e *InvalidModuleDefError) Hint() string {
+	switch e.code {
+	case CodeModuleNameEmpty:
+		return "set ModuleDef.Name to a unique, non-empty name"
+	case CodeProviderTokenEmpty:
+		return fmt.Sprintf("give every ProviderDef in module %q a non-empty Token", e.Module)
+	case CodeProviderBuildNil:
+		return fmt.Sprintf("set Build on every ProviderDef in module %q", e.Module)
+	case CodeControllerNameEmpty:
+		return fmt.Sprintf("give every ControllerDef in module %q a non-empty Name", e.Module)
+	case CodeControllerBuildNil:
+		return fmt.Sprintf("set Build on every ControllerDef in module %q", e.Module)
+	case CodeExportTokenEmpty:
+		return fmt.Sprintf("remove empty tokens from Exports of module %q", e.Module)
+	default:
+		return fmt.Sprintf("fix the definition of module %q: %s", e.Module, e.Reason)
+	}
+}
+
+// Code returns the stable error code.
+func (e *NilImportError) Code() string { return string(CodeNilImport) }
+
+// Fields returns structured error fields.
+func (e *NilImportError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "index": e.Index}
+}
+
+// Hint returns a remediation hint.
+func (e *NilImportError) Hint() string {
+	return fmt.Sprintf("remove or initialize Imports[%d] of module %q", e.Index, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *DuplicateModuleNameError) Code() string { return string(CodeDuplicateModuleName) }
+
+// Fields returns structured error fields.
+func (e *DuplicateModuleNameError) Fields() map[string]any {
+	return map[string]any{"name": e.Name}
+}
+
+// Hint returns a remediation hint.
+func (e *DuplicateModuleNameError) Hint() string {
+	return fmt.Sprintf("rename one of the modules named %q, or import the same module pointer instead of constructing it twice", e.Name)
+}
+
+// Code returns the stable error code.
+func (e *ModuleCycleError) Code() string { return string(CodeModuleCycle) }
+
+// Fields returns structured error fields.
+func (e *ModuleCycleError) Fields() map[string]any {
+	return map[string]any{"path": append([]string(nil), e.Path...)}
+}
+
+// Hint returns a remediation hint.
+func (e *ModuleCycleError) Hint() string {
+	return fmt.Sprintf("break the import cycle %s by moving the shared providers into a module both sides import", strings.Join(e.Path, " -> "))
+}
+
+// Code returns the stable error code.
+func (e *DuplicateProviderTokenError) Code() string { return string(CodeDuplicateProviderToken) }
+
+// Fields returns structured error fields.
+func (e *DuplicateProviderTokenError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token), "modules": append([]string(nil), e.Modules...)}
+}
+
+// Hint returns a remediation hint.
+func (e *DuplicateProviderTokenError) Hint() string {
+	return fmt.Sprintf("provide %q from a single module (%s) and import it where needed", e.Token, quoteList(e.Modules))
+}
+
+// Code returns the stable error code.
+func (e *DuplicateControllerNameError) Code() string { return string(CodeDuplicateControllerName) }
+
+// Fields returns structured error fields.
+func (e *DuplicateControllerNameError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "name": e.Name}
+}
+
+// Hint returns a remediation hint.
+func (e *DuplicateControllerNameError) Hint() string {
+	return fmt.Sprintf("rename one of the controllers named %q in module %q", e.Name, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *TokenNotVisibleError) Code() string { return string(CodeTokenNotVisible) }
+
+// Fields returns structured error fields.
+func (e *TokenNotVisibleError) Fields() map[string]any {
+	fields := map[string]any{"module": e.Module, "token": string(e.Token)}
+	if exp := e.Explanation; exp != nil {
+		if exp.Owner != "" {
+			fields["owner"] = exp.Owner
+		}
+		if len(exp.Path) > 0 {
+			fields["path"] = append([]string(nil), exp.Path...)
+		}
+		if exp.BrokenAt != "" {
+			fields["broken_at"] = exp.BrokenAt
+		}
+		if len(exp.Candidates) > 0 {
+			fields["candidates"] = append([]string(nil), exp.Candidates...)
+		}
+	}
+	return fields
+}
+
+// Hint returns a remediation hint.
+func (e *TokenNotVisibleError) Hint() string {
+	if e.Explanation != nil && !e.Explanation.Visible {
+		return e.Explanation.remediation()
+	}
+	return fmt.Sprintf("add %q to Exports of the module that provides it and import that module into %q", e.Token, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *ExportNotVisibleError) Code() string { return string(CodeExportNotVisible) }
+
+// Fields returns structured error fields.
+func (e *ExportNotVisibleError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *ExportNotVisibleError) Hint() string {
+	return fmt.Sprintf("provide %q in module %q or import a module that exports it before re-exporting", e.Token, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *ExportAmbiguousError) Code() string { return string(CodeExportAmbiguous) }
+
+// Fields returns structured error fields.
+func (e *ExportAmbiguousError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "token": string(e.Token), "imports": append([]string(nil), e.Imports...)}
+}
+
+// Hint returns a remediation hint.
+func (e *ExportAmbiguousError) Hint() string {
+	return fmt.Sprintf("re-export %q from only one of %s, or remove it from Exports of module %q", e.Token, quoteList(e.Imports), e.Module)
+}
+
+// Code returns the stable error code.
+func (e *ProviderNotFoundError) Code() string { return string(CodeProviderNotFound) }
+
+// Fields returns structured error fields.
+func (e *ProviderNotFoundError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *ProviderNotFoundError) Hint() string {
+	return fmt.Sprintf("register a ProviderDef with Token %q in a module reachable from the root", e.Token)
+}
+
+// Code returns the stable error code.
+func (e *ProviderCycleError) Code() string { return string(CodeProviderCycle) }
+
+// Fields returns structured error fields.
+func (e *ProviderCycleError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *ProviderCycleError) Hint() string {
+	return fmt.Sprintf("break the provider dependency cycle through %q by extracting the shared dependency into its own provider", e.Token)
+}
+
+// Code returns the stable error code.
+func (e *ProviderBuildError) Code() string { return string(CodeProviderBuildFailed) }
+
+// Fields returns structured error fields.
+func (e *ProviderBuildError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *ProviderBuildError) Hint() string {
+	return fmt.Sprintf("fix the Build function of provider %q in module %q; the cause is listed below", e.Token, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *ControllerBuildError) Code() string { return string(CodeControllerBuildFailed) }
+
+// Fields returns structured error fields.
+func (e *ControllerBuildError) Fields() map[string]any {
+	return map[string]any{"module": e.Module, "controller": e.Controller}
+}
+
+// Hint returns a remediation hint.
+func (e *ControllerBuildError) Hint() string {
+	return fmt.Sprintf("fix the Build function of controller %q in module %q; the cause is listed below", e.Controller, e.Module)
+}
+
+// Code returns the stable error code.
+func (e *OverrideTokenNotFoundError) Code() string { return string(CodeOverrideTokenNotFound) }
+
+// Fields returns structured error fields.
+func (e *OverrideTokenNotFoundError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *OverrideTokenNotFoundError) Hint() string {
+	return fmt.Sprintf("override only tokens that a module in the graph provides; check the spelling of %q", e.Token)
+}
+
+// Code returns the stable error code.
+func (e *OverrideTokenNotVisibleFromRootError) Code() string {
+	return string(CodeOverrideTokenNotVisibleFromRoot)
+}
+
+// Fields returns structured error fields.
+func (e *OverrideTokenNotVisibleFromRootError) Fields() map[string]any {
+	return map[string]any{"root": e.Root, "token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *OverrideTokenNotVisibleFromRootError) Hint() string {
+	return fmt.Sprintf("export %q along the import chain up to root module %q before overriding it", e.Token, e.Root)
+}
+
+// Code returns the stable error code.
+func (e *DuplicateOverrideTokenError) Code() string { return string(CodeDuplicateOverrideToken) }
+
+// Fields returns structured error fields.
+func (e *DuplicateOverrideTokenError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *DuplicateOverrideTokenError) Hint() string {
+	return fmt.Sprintf("list %q only once per WithProviderOverrides call", e.Token)
+}
+
+// Code returns the stable error code.
+func (e *BootstrapOptionConflictError) Code() string { return string(CodeBootstrapOptionConflict) }
+
+// Fields returns structured error fields.
+func (e *BootstrapOptionConflictError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token), "options": append([]string(nil), e.Options...)}
+}
+
+// Hint returns a remediation hint.
+func (e *BootstrapOptionConflictError) Hint() string {
+	return fmt.Sprintf("override %q in a single option instead of %s", e.Token, quoteList(e.Options))
+}
+
+// Code returns the stable error code.
+func (e *NilBootstrapOptionError) Code() string { return string(CodeNilBootstrapOption) }
+
+// Fields returns structured error fields.
+func (e *NilBootstrapOptionError) Fields() map[string]any {
+	return map[string]any{"index": e.Index}
+}
+
+// Hint returns a remediation hint.
+func (e *NilBootstrapOptionError) Hint() string {
+	return fmt.Sprintf("remove the nil bootstrap option at index %d", e.Index)
+}
+
+// Code returns the stable error code.
+func (e *OverrideBuildNilError) Code() string { return string(CodeOverrideBuildNil) }
+
+// Fields returns structured error fields.
+func (e *OverrideBuildNilError) Fields() map[string]any {
+	return map[string]any{"token": string(e.Token)}
+}
+
+// Hint returns a remediation hint.
+func (e *OverrideBuildNilError) Hint() string {
+	return fmt.Sprintf("set Build on the override for %q", e.Token)
+}
+
+// Code returns the stable error code.
+func (e *GraphValidationError) Code() string { return string(CodeGraphValidation) }
+
+// Fields returns structured error fields.
+func (e *GraphValidationError) Fields() map[string]any {
+	codes := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		codes = append(codes, string(issue.Code))
+	}
+	return map[string]any{"issues": len(e.Issues), "codes": codes}
+}
+
+// Hint returns a remediation hint.
+func (e *GraphValidationError) Hint() string {
+	return "fix each issue listed below; every issue carries its own code and hint"
+}
+
+// Code returns the stable error code.
+func (e *UnsupportedDiagnosticFormatError) Code() string {
+	return string(CodeUnsupportedDiagnosticFormat)
+}
+
+// Fields returns structured error fields.
+func (e *UnsupportedDiagnosticFormatError) Fields() map[string]any {
+	return map[string]any{"format": string(e.Format)}
+}
+
+// Hint returns a remediation hint.
+func (e *UnsupportedDiagnosticFormatError) Hint() string {
+	return fmt.Sprintf("use %q or %q as the diagnostic format", DiagnosticFormatText, DiagnosticFormatJSON)
+}
+
+// Code returns the stable error code.
+func (e *RunnableError) Code() string { return string(CodeRunnableFailed) }
+
+// Fields returns structured error fields.
+func (e *RunnableError) Fields() map[string]any {
+	return map[string]any{"name": e.Name}
+}
+
+// Hint returns a remediation hint.
+func (e *RunnableError) Hint() string {
+	return fmt.Sprintf("fix runnable %q; the other runnables were stopped because it failed", e.Name)
+}
+
+// Code returns the stable error code.
+func (e *NilRunnableError) Code() string { return string(CodeNilRunnable) }
+
+// Fields returns structured error fields.
+func (e *NilRunnableError) Fields() map[string]any {
+	return map[string]any{"index": e.Index}
+}
+
+// Hint returns a remediation hint.
+func (e *NilRunnableError) Hint() string {
+	return fmt.Sprintf("remove the nil runnable at index %d", e.Index)
+}
+
+// Code returns the stable error code.
+func (e *InvalidCommandError) Code() string { return string(CodeInvalidCommand) }
+
+// Fields returns structured error fields.
+func (e *InvalidCommandError) Fields() map[string]any {
+	return map[string]any{"controller": e.Controller, "name": e.Name, "reason": e.Reason}
+}
+
+// Hint returns a remediation hint.
+func (e *InvalidCommandError) Hint() string {
+	return fmt.Sprintf("register commands in controller %q with a non-empty name and a run func", e.Controller)
+}
+
+// Code returns the stable error code.
+func (e *DuplicateCommandError) Code() string { return string(CodeDuplicateCommand) }
+
+// Fields returns structured error fields.
+func (e *DuplicateCommandError) Fields() map[string]any {
+	return map[string]any{"name": e.Name, "controllers": append([]string(nil), e.Controllers...)}
+}
+
+// Hint returns a remediation hint.
+func (e *DuplicateCommandError) Hint() string {
+	return fmt.Sprintf("rename command %q in one of %s", e.Name, quoteList(e.Controllers))
+}
+
+// Code returns the stable error code.
+func (e *CommandNotFoundError) Code() string { return string(CodeCommandNotFound) }
+
+// Fields returns structured error fields.
+func (e *CommandNotFoundError) Fields() map[string]any {
+	return map[string]any{"name": e.Name, "available": append([]string(nil), e.Available...)}
+}
+
+// Hint returns a remediation hint.
+func (e *CommandNotFoundError) Hint() string {
+	if len(e.Available) == 0 {
+		return "no controller registers commands; implement module.CommandRegistrar"
+	}
+	return fmt.Sprintf("use one of %s", quoteList(e.Available))
+}
+
+// Code returns the stable error code.
+func (e *HealthCheckError) Code() string { return string(CodeHealthCheckFailed) }
+
+// Fields returns structured error fields.
+func (e *HealthCheckError) Fields() map[string]any {
+	return map[string]any{"name": e.Name}
+}
+
+// Hint returns a remediation hint.
+func (e *HealthCheckError) Hint() string {
+	return fmt.Sprintf("check the dependency behind controller %q; readiness fails until its check passes", e.Name)
+}
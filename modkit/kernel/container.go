@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
@@ -17,24 +18,26 @@ type providerEntry struct {
 // Container is the dependency injection container that manages provider instances,
 // enforces visibility rules, and tracks cleanup hooks.
 type Container struct {
+	graph        *Graph
 	providers    map[module.Token]providerEntry
 	instances    map[module.Token]any
-	visibility   Visibility
+	visibility   *visibilityIndex
 	locks        map[module.Token]*sync.Mutex
 	waitingOn    map[module.Token]module.Token
 	cleanupHooks []func(context.Context) error
 	closers      []io.Closer
 	buildOrder   []module.Token
+	observers    []Observer
 	mu           sync.Mutex
 }
 
-func newContainer(graph *Graph, visibility Visibility) (*Container, error) {
+func newContainer(graph *Graph, visibility *visibilityIndex) (*Container, error) {
 	providers, err := providerEntriesFromGraph(graph)
 	if err != nil {
 		return nil, err
 	}
 
-	return newContainerWithProviders(providers, visibility), nil
+	return newContainerWithProviders(graph, providers, visibility), nil
 }
 
 func providerEntriesFromGraph(graph *Graph) (map[module.Token]providerEntry, error) {
@@ -59,13 +62,14 @@ func providerEntriesFromGraph(graph *Graph) (map[module.Token]providerEntry, err
 	return providers, nil
 }
 
-func newContainerWithProviders(providers map[module.Token]providerEntry, visibility Visibility) *Container {
+func newContainerWithProviders(graph *Graph, providers map[module.Token]providerEntry, visibility *visibilityIndex) *Container {
 	providerCopy := make(map[module.Token]providerEntry, len(providers))
 	for token, entry := range providers {
 		providerCopy[token] = entry
 	}
 
 	return &Container{
+		graph:        graph,
 		providers:    providerCopy,
 		instances:    make(map[module.Token]any),
 		visibility:   visibility,
@@ -126,7 +130,9 @@ func (c *Container) getWithStack(token module.Token, requester string, stack []m
 		stack:        nextStack,
 		requestToken: token,
 	}
+	start := time.Now()
 	instance, err := entry.build(resolver)
+	c.notifyBuilt(entry.moduleName, token, time.Since(start), err)
 	if err != nil {
 		return nil, &ProviderBuildError{Module: entry.moduleName, Token: token, Err: err}
 	}
@@ -144,6 +150,16 @@ func (c *Container) getWithStack(token module.Token, requester string, stack []m
 	return instance, nil
 }
 
+func (c *Container) notifyBuilt(moduleName string, token module.Token, duration time.Duration, err error) {
+	if len(c.observers) == 0 {
+		return
+	}
+	event := ProviderBuildEvent{Module: moduleName, Token: token, Duration: duration, Err: err}
+	for _, observer := range c.observers {
+		observer.ProviderBuilt(event)
+	}
+}
+
 func (c *Container) cleanupHooksLIFO() []func(context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -192,9 +208,8 @@ type moduleResolver struct {
 }
 
 func (r moduleResolver) Get(token module.Token) (any, error) {
-	visibility := r.container.visibility[r.moduleName]
-	if !visibility[token] {
-		return nil, &TokenNotVisibleError{Module: r.moduleName, Token: token}
+	if !r.container.visibility.visible(r.moduleName, token) {
+		return nil, r.container.tokenNotVisible(r.moduleName, token)
 	}
 
 	c := r.container
@@ -229,6 +244,16 @@ func detectsWaitCycle(waitingOn map[module.Token]module.Token, start module.Toke
 	return false
 }
 
+func (c *Container) tokenNotVisible(moduleName string, token module.Token) *TokenNotVisibleError {
+	err := &TokenNotVisibleError{Module: moduleName, Token: token}
+	if c.graph != nil {
+		if _, ok := c.graph.Nodes[moduleName]; ok {
+			err.Explanation = explainVisibility(c.graph, c.visibility, moduleName, token)
+		}
+	}
+	return err
+}
+
 func (c *Container) resolverFor(moduleName string) module.Resolver {
 	return moduleResolver{
 		container:  c,
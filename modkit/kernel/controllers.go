@@ -0,0 +1,60 @@
+package kernel
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// ControllerRef identifies a controller instance and the module declaring it.
+type ControllerRef struct {
+	// Key is the key in App.Controllers ("module:Name").
+	Key        string
+	Module     string
+	Name       string
+	Controller any
+}
+
+// ControllerRefs returns the controllers in module graph order: imported modules
+// before the modules importing them, and declaration order within a module.
+// Entries added to App.Controllers outside Bootstrap follow, sorted by key.
+func (a *App) ControllerRefs() []ControllerRef {
+	refs := make([]ControllerRef, 0, len(a.Controllers))
+	seen := make(map[string]bool, len(a.Controllers))
+	if a.Graph != nil {
+		for _, node := range a.Graph.Modules {
+			for _, def := range node.Def.Controllers {
+				key := controllerKey(node.Name, def.Name)
+				instance, ok := a.Controllers[key]
+				if !ok || seen[key] {
+					continue
+				}
+				seen[key] = true
+				refs = append(refs, ControllerRef{Key: key, Module: node.Name, Name: def.Name, Controller: instance})
+			}
+		}
+	}
+
+	var rest []string
+	for key := range a.Controllers {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		moduleName, name, _ := strings.Cut(key, ":")
+		refs = append(refs, ControllerRef{Key: key, Module: moduleName, Name: name, Controller: a.Controllers[key]})
+	}
+	return refs
+}
+
+// ModuleResolver returns a resolver scoped to the named module, seeing its own
+// providers and the exports of its imports.
+func (a *App) ModuleResolver(name string) (module.Resolver, error) {
+	if a.Graph == nil || a.Graph.Nodes[name] == nil {
+		return nil, &GraphNodeNotFoundError{Node: name}
+	}
+	return a.container.resolverFor(name), nil
+}
@@ -0,0 +1,79 @@
+package kernel_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func controllerDef(name string) module.ControllerDef {
+	return module.ControllerDef{Name: name, Build: func(module.Resolver) (any, error) { return name, nil }}
+}
+
+func TestControllerRefs_GraphOrder(t *testing.T) {
+	leaf := mod("z-leaf", nil, nil, []module.ControllerDef{controllerDef("Second"), controllerDef("First")}, nil)
+	root := mod("a-root", []module.Module{leaf}, nil, []module.ControllerDef{controllerDef("Root")}, nil)
+
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	app.Controllers["extra:Manual"] = "manual"
+
+	var keys []string
+	for _, ref := range app.ControllerRefs() {
+		keys = append(keys, ref.Key)
+	}
+	want := []string{"z-leaf:Second", "z-leaf:First", "a-root:Root", "extra:Manual"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected %v, got %v", want, keys)
+	}
+	if refs := app.ControllerRefs(); refs[3].Module != "extra" || refs[3].Name != "Manual" {
+		t.Fatalf("unexpected manual ref: %+v", refs[3])
+	}
+}
+
+func TestModuleResolver(t *testing.T) {
+	provider := module.ProviderDef{Token: "leaf.private", Build: func(module.Resolver) (any, error) { return 1, nil }}
+	leaf := mod("leaf", nil, []module.ProviderDef{provider}, nil, nil)
+	app, err := kernel.Bootstrap(mod("root", []module.Module{leaf}, nil, nil, nil))
+	if err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+
+	resolver, err := app.ModuleResolver("leaf")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if value, err := resolver.Get("leaf.private"); err != nil || value != 1 {
+		t.Fatalf("expected private provider from leaf scope, got %v %v", value, err)
+	}
+	if _, err := app.Get("leaf.private"); err == nil {
+		t.Fatalf("expected private provider hidden from root")
+	}
+
+	_, err = app.ModuleResolver("missing")
+	var notFound *kernel.GraphNodeNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected GraphNodeNotFoundError, got %v", err)
+	}
+}
+
+func TestBuildGraph_InvalidHTTPDef(t *testing.T) {
+	root := &testModule{def: module.ModuleDef{
+		Name: "root",
+		HTTP: module.HTTPDef{Prefix: "users", Middleware: []module.Token{""}},
+	}}
+
+	err := kernel.ValidateGraph(root)
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	var defErr *kernel.InvalidModuleDefError
+	if !errors.As(err, &defErr) || kernel.CodeOf(defErr) != string(kernel.CodeInvalidModuleDef) {
+		t.Fatalf("expected InvalidModuleDefError, got %v", err)
+	}
+}
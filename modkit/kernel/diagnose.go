@@ -0,0 +1,159 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DiagnosticFormat selects the rendering of Diagnose output.
+type DiagnosticFormat string
+
+const (
+	// DiagnosticFormatText renders an indented, human-readable report.
+	DiagnosticFormatText DiagnosticFormat = "text"
+	// DiagnosticFormatJSON renders the diagnostic tree as JSON.
+	DiagnosticFormatJSON DiagnosticFormat = "json"
+)
+
+// Diagnostic is one error in a diagnosed causal chain. Causes holds the wrapped
+// errors, so nested ProviderBuildErrors appear as a tree from the outermost
+// failure down to the root cause.
+type Diagnostic struct {
+	Code    string         `json:"code,omitempty"`
+	Type    string         `json:"type"`
+	Message string         `json:"message"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Hint    string         `json:"hint,omitempty"`
+	Causes  []Diagnostic   `json:"causes,omitempty"`
+}
+
+// NewDiagnostic builds the diagnostic tree for err. It returns nil for a nil error.
+func NewDiagnostic(err error) *Diagnostic {
+	if err == nil {
+		return nil
+	}
+	d := diagnose(err)
+	return &d
+}
+
+// Diagnose renders err and its causal chain as text or JSON, including the stable
+// code, structured fields, and remediation hint of every coded error in the chain.
+// It returns an empty string for a nil error.
+func Diagnose(err error, format DiagnosticFormat) (string, error) {
+	switch format {
+	case DiagnosticFormatText, DiagnosticFormatJSON:
+	default:
+		return "", &UnsupportedDiagnosticFormatError{Format: format}
+	}
+	if err == nil {
+		return "", nil
+	}
+
+	d := diagnose(err)
+	if format == DiagnosticFormatJSON {
+		data, marshalErr := json.MarshalIndent(d, "", "  ")
+		if marshalErr != nil {
+			return "", marshalErr
+		}
+		return string(data), nil
+	}
+
+	var b strings.Builder
+	writeDiagnostic(&b, &d, 0)
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func diagnose(err error) Diagnostic {
+	causes := unwrapAll(err)
+	d := Diagnostic{
+		Code:    ownCode(err),
+		Type:    fmt.Sprintf("%T", err),
+		Message: ownMessage(err, causes),
+	}
+	if de, ok := err.(DiagnosticError); ok {
+		fields := de.Fields()
+		if len(fields) > 0 {
+			d.Fields = fields
+		}
+		d.Hint = de.Hint()
+	}
+	for _, cause := range causes {
+		d.Causes = append(d.Causes, diagnose(cause))
+	}
+	return d
+}
+
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		var out []error
+		for _, cause := range u.Unwrap() {
+			if cause != nil {
+				out = append(out, cause)
+			}
+		}
+		return out
+	case interface{ Unwrap() error }:
+		if cause := u.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// ownMessage strips the text an error repeats from its causes so each level of
+// the report only shows what it adds.
+func ownMessage(err error, causes []error) string {
+	if s, ok := err.(interface{ summary() string }); ok {
+		return s.summary()
+	}
+	msg := err.Error()
+	switch len(causes) {
+	case 0:
+		return msg
+	case 1:
+		if trimmed, ok := strings.CutSuffix(msg, ": "+causes[0].Error()); ok {
+			return trimmed
+		}
+		return msg
+	default:
+		parts := make([]string, 0, len(causes))
+		for _, cause := range causes {
+			parts = append(parts, cause.Error())
+		}
+		if msg == strings.Join(parts, "\n") {
+			return fmt.Sprintf("%d errors", len(causes))
+		}
+		return msg
+	}
+}
+
+func writeDiagnostic(b *strings.Builder, d *Diagnostic, depth int) {
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent)
+	if d.Code != "" {
+		b.WriteString("[" + d.Code + "] ")
+	}
+	b.WriteString(d.Message)
+	b.WriteString("\n")
+
+	keys := make([]string, 0, len(d.Fields))
+	for key := range d.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(b, "%s  %s: %v\n", indent, key, d.Fields[key])
+	}
+	if d.Hint != "" {
+		fmt.Fprintf(b, "%s  hint: %s\n", indent, d.Hint)
+	}
+	if len(d.Causes) > 0 {
+		fmt.Fprintf(b, "%s  caused by:\n", indent)
+		for i := range d.Causes {
+			writeDiagnostic(b, &d.Causes[i], depth+2)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package kernel_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func nestedBuildFailure(t *testing.T) error {
+	t.Helper()
+	outer := module.Token("outer.service")
+	inner := module.Token("inner.service")
+	hidden := module.Token("hidden.token")
+
+	other := mod("Other", nil, []module.ProviderDef{{Token: hidden, Build: buildNoop}}, nil, nil)
+	root := mod("Root", []module.Module{other}, []module.ProviderDef{
+		{Token: outer, Build: func(r module.Resolver) (any, error) { return r.Get(inner) }},
+		{Token: inner, Build: func(r module.Resolver) (any, error) { return r.Get(hidden) }},
+	}, nil, nil)
+
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	_, err = app.Get(outer)
+	if err == nil {
+		t.Fatalf("expected build error")
+	}
+	return err
+}
+
+func TestDiagnose_TextRendersNestedChain(t *testing.T) {
+	err := nestedBuildFailure(t)
+
+	out, diagErr := kernel.Diagnose(err, kernel.DiagnosticFormatText)
+	if diagErr != nil {
+		t.Fatalf("Diagnose failed: %v", diagErr)
+	}
+
+	if strings.Count(out, "[MODKIT_E_PROVIDER_BUILD_FAILED]") != 2 {
+		t.Fatalf("expected two provider build levels:\n%s", out)
+	}
+	if !strings.Contains(out, "[MODKIT_E_TOKEN_NOT_VISIBLE]") {
+		t.Fatalf("expected root cause code:\n%s", out)
+	}
+	if !strings.Contains(out, `hint: add "hidden.token" to Exports`) {
+		t.Fatalf("expected remediation hint:\n%s", out)
+	}
+	if !strings.Contains(out, "caused by:") {
+		t.Fatalf("expected causal chain:\n%s", out)
+	}
+	firstLine := strings.SplitN(out, "\n", 2)[0]
+	if strings.Contains(firstLine, "token not visible") {
+		t.Fatalf("expected outer message to omit repeated cause text: %q", firstLine)
+	}
+}
+
+func TestDiagnose_JSONRendersTree(t *testing.T) {
+	err := fmt.Errorf("startup: %w", nestedBuildFailure(t))
+
+	out, diagErr := kernel.Diagnose(err, kernel.DiagnosticFormatJSON)
+	if diagErr != nil {
+		t.Fatalf("Diagnose failed: %v", diagErr)
+	}
+
+	var d kernel.Diagnostic
+	if err := json.Unmarshal([]byte(out), &d); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if d.Code != "" || d.Message != "startup" {
+		t.Fatalf("unexpected wrapper diagnostic: %+v", d)
+	}
+
+	depth := 0
+	cur := &d
+	for len(cur.Causes) > 0 {
+		cur = &cur.Causes[0]
+		depth++
+	}
+	if depth != 3 {
+		t.Fatalf("expected chain depth 3, got %d", depth)
+	}
+	if cur.Code != string(kernel.CodeTokenNotVisible) {
+		t.Fatalf("unexpected root cause code: %q", cur.Code)
+	}
+	if cur.Fields["module"] != "Root" || cur.Fields["token"] != "hidden.token" {
+		t.Fatalf("unexpected root cause fields: %v", cur.Fields)
+	}
+}
+
+func TestDiagnose_GraphValidationListsIssues(t *testing.T) {
+	root := mod("Root", []module.Module{nil}, nil, nil, []module.Token{"missing"})
+	err := kernel.ValidateGraph(root)
+
+	d := kernel.NewDiagnostic(err)
+	if d.Code != string(kernel.CodeGraphValidation) {
+		t.Fatalf("unexpected code: %q", d.Code)
+	}
+	if len(d.Causes) != 2 {
+		t.Fatalf("expected two causes, got %d", len(d.Causes))
+	}
+	if strings.Contains(d.Message, "MODKIT_E_NIL_IMPORT") {
+		t.Fatalf("expected summary message, got %q", d.Message)
+	}
+}
+
+func TestDiagnose_JoinedErrors(t *testing.T) {
+	err := errors.Join(&kernel.NilImportError{Module: "A", Index: 0}, errors.New("plain"))
+
+	d := kernel.NewDiagnostic(err)
+	if d.Message != "2 errors" {
+		t.Fatalf("unexpected message: %q", d.Message)
+	}
+	if d.Causes[0].Code != string(kernel.CodeNilImport) || d.Causes[1].Code != "" {
+		t.Fatalf("unexpected causes: %+v", d.Causes)
+	}
+}
+
+func TestDiagnose_NilAndUnsupportedFormat(t *testing.T) {
+	out, err := kernel.Diagnose(nil, kernel.DiagnosticFormatText)
+	if err != nil || out != "" {
+		t.Fatalf("expected empty output for nil error, got %q, %v", out, err)
+	}
+	if kernel.NewDiagnostic(nil) != nil {
+		t.Fatalf("expected nil diagnostic for nil error")
+	}
+
+	_, err = kernel.Diagnose(errors.New("x"), kernel.DiagnosticFormat("yaml"))
+	var formatErr *kernel.UnsupportedDiagnosticFormatError
+	if !errors.As(err, &formatErr) {
+		t.Fatalf("expected UnsupportedDiagnosticFormatError, got %T", err)
+	}
+}
+
+func TestCodeOf_FindsOutermostCode(t *testing.T) {
+	err := fmt.Errorf("wrap: %w", &kernel.ProviderBuildError{Module: "m", Token: "t", Err: &kernel.ProviderCycleError{Token: "t"}})
+	if got := kernel.CodeOf(err); got != string(kernel.CodeProviderBuildFailed) {
+		t.Fatalf("unexpected code: %q", got)
+	}
+	if got := kernel.CodeOf(fmt.Errorf("wrap: %w", kernel.ErrNilGraph)); got != string(kernel.CodeNilGraph) {
+		t.Fatalf("unexpected sentinel code: %q", got)
+	}
+	if got := kernel.CodeOf(errors.New("plain")); got != "" {
+		t.Fatalf("expected empty code, got %q", got)
+	}
+}
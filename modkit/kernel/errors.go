@@ -3,6 +3,7 @@ package kernel
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
@@ -28,6 +29,15 @@ func (e *UnsupportedGraphFormatError) Error() string {
 	return fmt.Sprintf("unsupported graph format: %q", e.Format)
 }
 
+// UnsupportedDiagnosticFormatError is returned when Diagnose receives an unsupported format.
+type UnsupportedDiagnosticFormatError struct {
+	Format DiagnosticFormat
+}
+
+func (e *UnsupportedDiagnosticFormatError) Error() string {
+	return fmt.Sprintf("unsupported diagnostic format: %q", e.Format)
+}
+
 // GraphNodeNotFoundError is returned when graph export cannot find a node by name.
 type GraphNodeNotFoundError struct {
 	Node string
@@ -70,6 +80,7 @@ func (e *ModuleNotPointerError) Error() string {
 type InvalidModuleDefError struct {
 	Module string
 	Reason string
+	code   ErrorCode
 }
 
 func (e *InvalidModuleDefError) Error() string {
@@ -132,12 +143,17 @@ func (e *DuplicateControllerNameError) Error() string {
 }
 
 // TokenNotVisibleError is returned when a module attempts to resolve a token that isn't visible to it.
+// Errors returned by the container carry an Explanation of where the import/export chain breaks.
 type TokenNotVisibleError struct {
-	Module string
-	Token  module.Token
+	Module      string
+	Token       module.Token
+	Explanation *VisibilityExplanation
 }
 
 func (e *TokenNotVisibleError) Error() string {
+	if e.Explanation != nil && !e.Explanation.Visible {
+		return fmt.Sprintf("token not visible: module=%q token=%q: %s", e.Module, e.Token, e.Explanation.reason())
+	}
 	return fmt.Sprintf("token not visible: module=%q token=%q", e.Module, e.Token)
 }
 
@@ -273,3 +289,116 @@ type OverrideBuildNilError struct {
 func (e *OverrideBuildNilError) Error() string {
 	return fmt.Sprintf("override build is nil: token=%q", e.Token)
 }
+
+// GraphValidationError aggregates every structural problem found by ValidateGraph.
+type GraphValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *GraphValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return fmt.Sprintf("graph validation failed: [%s] %s", e.Issues[0].Code, e.Issues[0].Message)
+	}
+	parts := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		parts = append(parts, fmt.Sprintf("[%s] %s", issue.Code, issue.Message))
+	}
+	return fmt.Sprintf("graph validation failed: %d issues: %s", len(e.Issues), strings.Join(parts, "; "))
+}
+
+// Unwrap returns the underlying issue errors for errors.Is/errors.As matching.
+func (e *GraphValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		if issue.Err != nil {
+			errs = append(errs, issue.Err)
+		}
+	}
+	return errs
+}
+
+func (e *GraphValidationError) summary() string {
+	return fmt.Sprintf("graph validation failed: %d issue(s)", len(e.Issues))
+}
+
+// HasCode reports whether any issue carries the given code.
+func (e *GraphValidationError) HasCode(code ErrorCode) bool {
+	for _, issue := range e.Issues {
+		if issue.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RunnableError wraps the first failure returned by a runnable started by App.Run.
+type RunnableError struct {
+	Name string
+	Err  error
+}
+
+func (e *RunnableError) Error() string {
+	return fmt.Sprintf("runnable failed: name=%q: %v", e.Name, e.Err)
+}
+
+func (e *RunnableError) Unwrap() error {
+	return e.Err
+}
+
+// NilRunnableError is returned when App.Run receives a nil extra runnable.
+type NilRunnableError struct {
+	Index int
+}
+
+func (e *NilRunnableError) Error() string {
+	return fmt.Sprintf("nil runnable: index=%d", e.Index)
+}
+
+// HealthCheckError reports a failed check of a controller implementing
+// module.HealthChecker.
+type HealthCheckError struct {
+	Name string
+	Err  error
+}
+
+func (e *HealthCheckError) Error() string {
+	return fmt.Sprintf("health check failed: name=%q: %v", e.Name, e.Err)
+}
+
+func (e *HealthCheckError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidCommandError is returned when a controller registers an invalid CLI command.
+type InvalidCommandError struct {
+	Controller string
+	Name       string
+	Reason     string
+}
+
+func (e *InvalidCommandError) Error() string {
+	return fmt.Sprintf("invalid command: controller=%q name=%q reason=%s", e.Controller, e.Name, e.Reason)
+}
+
+// DuplicateCommandError is returned when multiple controllers register the same command name.
+type DuplicateCommandError struct {
+	Name        string
+	Controllers []string
+}
+
+func (e *DuplicateCommandError) Error() string {
+	return fmt.Sprintf("duplicate command: %q (controllers %v)", e.Name, e.Controllers)
+}
+
+// CommandNotFoundError is returned when RunCommand cannot find the requested command.
+type CommandNotFoundError struct {
+	Name      string
+	Available []string
+}
+
+func (e *CommandNotFoundError) Error() string {
+	if e.Name == "" {
+		return "no command given"
+	}
+	return fmt.Sprintf("command not found: %q", e.Name)
+}
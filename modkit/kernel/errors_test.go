@@ -2,14 +2,19 @@ package kernel
 
 import (
 	"errors"
+	"strings"
 	"testing"
+
+	"github.com/go-modkit/modkit/modkit/module"
 )
 
-func TestKernelErrorStrings(t *testing.T) {
-	tests := []struct {
-		name string
-		err  error
-	}{
+type namedError struct {
+	name string
+	err  error
+}
+
+func kernelErrors() []namedError {
+	return []namedError{
 		{"NilGraph", ErrNilGraph},
 		{"NilApp", ErrNilApp},
 		{"GraphNodeNotFound", ErrGraphNodeNotFound},
@@ -37,8 +42,21 @@ func TestKernelErrorStrings(t *testing.T) {
 		{"BootstrapOptionConflict", &BootstrapOptionConflictError{Token: "t", Options: []string{"a", "b"}}},
 		{"NilBootstrapOption", &NilBootstrapOptionError{Index: 0}},
 		{"OverrideBuildNil", &OverrideBuildNilError{Token: "t"}},
+		{"GraphValidation", &GraphValidationError{Issues: []ValidationIssue{{Code: CodeModuleCycle, Message: "cycle"}}}},
+		{"GraphValidationMulti", &GraphValidationError{Issues: []ValidationIssue{{Code: CodeModuleCycle}, {Code: CodeNilImport}}}},
+		{"UnsupportedDiagnosticFormat", &UnsupportedDiagnosticFormatError{Format: DiagnosticFormat("yaml")}},
+		{"Runnable", &RunnableError{Name: "worker", Err: errors.New("boom")}},
+		{"NilRunnable", &NilRunnableError{Index: 0}},
+		{"InvalidCommand", &InvalidCommandError{Controller: "m:c", Name: "", Reason: "bad"}},
+		{"DuplicateCommand", &DuplicateCommandError{Name: "migrate", Controllers: []string{"a:c", "b:c"}}},
+		{"CommandNotFound", &CommandNotFoundError{Name: "x", Available: []string{"migrate"}}},
+		{"CommandNotFoundEmpty", &CommandNotFoundError{}},
+		{"HealthCheck", &HealthCheckError{Name: "db:health", Err: errors.New("down")}},
 	}
-	for _, tc := range tests {
+}
+
+func TestKernelErrorStrings(t *testing.T) {
+	for _, tc := range kernelErrors() {
 		if tc.err == nil {
 			t.Fatalf("%s produced nil error", tc.name)
 		}
@@ -67,3 +85,45 @@ func TestErrorWraps(t *testing.T) {
 		t.Fatalf("expected GraphNodeNotFoundError to unwrap to ErrGraphNodeNotFound")
 	}
 }
+
+func TestKernelErrorsAreDiagnostic(t *testing.T) {
+	seen := make(map[string]string)
+	for _, tc := range kernelErrors() {
+		if tc.err == ErrNilGraph || tc.err == ErrNilApp || tc.err == ErrGraphNodeNotFound {
+			if CodeOf(tc.err) == "" {
+				t.Fatalf("%s sentinel has no code", tc.name)
+			}
+			continue
+		}
+		de, ok := tc.err.(DiagnosticError)
+		if !ok {
+			t.Fatalf("%s does not implement DiagnosticError", tc.name)
+		}
+		if !strings.HasPrefix(de.Code(), "MODKIT_E_") {
+			t.Fatalf("%s has unexpected code %q", tc.name, de.Code())
+		}
+		if de.Hint() == "" {
+			t.Fatalf("%s has no hint", tc.name)
+		}
+		if de.Fields() == nil {
+			t.Fatalf("%s has nil fields", tc.name)
+		}
+		if other, ok := seen[de.Code()]; ok && !strings.HasPrefix(tc.name, other) && !strings.HasPrefix(other, tc.name) {
+			t.Fatalf("%s and %s share code %q", tc.name, other, de.Code())
+		}
+		seen[de.Code()] = tc.name
+	}
+}
+
+func TestInvalidModuleDefErrorSpecificCode(t *testing.T) {
+	issues := moduleDefIssues(&module.ModuleDef{Name: "m", Providers: []module.ProviderDef{{Token: "t"}}})
+	if len(issues) != 1 {
+		t.Fatalf("expected one issue, got %d", len(issues))
+	}
+	if CodeOf(issues[0]) != string(CodeProviderBuildNil) {
+		t.Fatalf("unexpected code: %q", CodeOf(issues[0]))
+	}
+	if CodeOf(&InvalidModuleDefError{Module: "m", Reason: "bad"}) != string(CodeInvalidModuleDef) {
+		t.Fatalf("expected generic code for hand-built error")
+	}
+}
@@ -0,0 +1,8 @@
+package kernel
+
+// BuildVisibilityIndex builds the bitset visibility index without converting
+// it to a Visibility map, for benchmarks in package kernel_test.
+func BuildVisibilityIndex(graph *Graph) error {
+	_, err := buildVisibility(graph)
+	return err
+}
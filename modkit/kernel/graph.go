@@ -3,6 +3,7 @@ package kernel
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
@@ -153,29 +154,49 @@ func BuildGraph(root module.Module) (*Graph, error) {
 }
 
 func validateModuleDef(def *module.ModuleDef) error {
+	if issues := moduleDefIssues(def); len(issues) > 0 {
+		return issues[0]
+	}
+	return nil
+}
+
+// moduleDefIssues returns every metadata problem in a module definition, in declaration order.
+func moduleDefIssues(def *module.ModuleDef) []error {
+	var issues []error
+	invalid := func(code ErrorCode, reason string) {
+		issues = append(issues, &InvalidModuleDefError{Module: def.Name, Reason: reason, code: code})
+	}
 	if def.Name == "" {
-		return &InvalidModuleDefError{Module: def.Name, Reason: "module name is empty"}
+		invalid(CodeModuleNameEmpty, "module name is empty")
 	}
 	for i, provider := range def.Providers {
 		if provider.Token == "" {
-			return &InvalidModuleDefError{Module: def.Name, Reason: fmt.Sprintf("provider[%d] token is empty", i)}
+			invalid(CodeProviderTokenEmpty, fmt.Sprintf("provider[%d] token is empty", i))
 		}
 		if provider.Build == nil {
-			return &InvalidModuleDefError{Module: def.Name, Reason: fmt.Sprintf("provider[%d] build is nil", i)}
+			invalid(CodeProviderBuildNil, fmt.Sprintf("provider[%d] build is nil", i))
 		}
 	}
 	for i, controller := range def.Controllers {
 		if controller.Name == "" {
-			return &InvalidModuleDefError{Module: def.Name, Reason: fmt.Sprintf("controller[%d] name is empty", i)}
+			invalid(CodeControllerNameEmpty, fmt.Sprintf("controller[%d] name is empty", i))
 		}
 		if controller.Build == nil {
-			return &InvalidModuleDefError{Module: def.Name, Reason: fmt.Sprintf("controller[%d] build is nil", i)}
+			invalid(CodeControllerBuildNil, fmt.Sprintf("controller[%d] build is nil", i))
 		}
 	}
 	for i, token := range def.Exports {
 		if token == "" {
-			return &InvalidModuleDefError{Module: def.Name, Reason: fmt.Sprintf("export[%d] token is empty", i)}
+			invalid(CodeExportTokenEmpty, fmt.Sprintf("export[%d] token is empty", i))
 		}
 	}
-	return nil
+	if def.HTTP.Prefix != "" && !strings.HasPrefix(def.HTTP.Prefix, "/") {
+		invalid(CodeInvalidModuleDef, fmt.Sprintf("http prefix %q must start with /", def.HTTP.Prefix))
+	}
+	for i, token := range def.HTTP.Middleware {
+		if token == "" {
+			invalid(CodeInvalidModuleDef, fmt.Sprintf("http middleware[%d] token is empty", i))
+		}
+	}
+	return issues
 }
@@ -0,0 +1,222 @@
+package kernel
+
+import (
+	"reflect"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// ValidationIssue describes a single structural problem found by ValidateGraph.
+type ValidationIssue struct {
+	Code    ErrorCode `json:"code"`
+	Module  string    `json:"module,omitempty"`
+	Message string    `json:"message"`
+	Hint    string    `json:"hint,omitempty"`
+	Err     error     `json:"-"`
+}
+
+// ValidateGraph checks the module graph rooted at root and reports every structural
+// problem in one pass instead of stopping at the first one: invalid module metadata,
+// nil imports, duplicate module names, cycles, duplicate provider tokens, duplicate
+// controller names, and invalid or ambiguous exports.
+//
+// It returns nil when the graph is valid, otherwise a *GraphValidationError.
+// Provider and controller Build functions are never called.
+func ValidateGraph(root module.Module) error {
+	if root == nil {
+		return &GraphValidationError{Issues: []ValidationIssue{newValidationIssue("", &RootModuleNilError{})}}
+	}
+	rootVal := reflect.ValueOf(root)
+	if rootVal.Kind() == reflect.Ptr && rootVal.IsNil() {
+		return &GraphValidationError{Issues: []ValidationIssue{newValidationIssue("", &RootModuleNilError{})}}
+	}
+
+	v := &graphValidator{
+		graph:         &Graph{Nodes: make(map[string]*ModuleNode)},
+		state:         make(map[uintptr]int),
+		added:         make(map[uintptr]bool),
+		names:         make(map[string]uintptr),
+		reportedNames: make(map[string]bool),
+		skipExports:   make(map[string]bool),
+	}
+	v.visit(root, "", -1)
+	v.graph.Root = root.Definition().Name
+	for i := range v.graph.Modules {
+		v.graph.Nodes[v.graph.Modules[i].Name] = &v.graph.Modules[i]
+	}
+
+	v.checkProviders()
+	v.checkControllers()
+
+	_, visibilityIssues := computeVisibility(v.graph, true, v.skipExports)
+	for _, err := range visibilityIssues {
+		v.report("", err)
+	}
+
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return &GraphValidationError{Issues: v.issues}
+}
+
+type graphValidator struct {
+	graph         *Graph
+	issues        []ValidationIssue
+	state         map[uintptr]int
+	added         map[uintptr]bool
+	names         map[string]uintptr
+	reportedNames map[string]bool
+	skipExports   map[string]bool
+	stack         []uintptr
+	stackNames    []string
+}
+
+// visit walks a module and its imports depth-first. It reports whether the module
+// was added to the graph, and whether its exports are fully known. Exports are only
+// unknown for modules on an import cycle (and their importers); invalid imports simply
+// contribute no exports.
+func (v *graphValidator) visit(m module.Module, parent string, index int) (name string, added, complete bool) {
+	if m == nil {
+		v.report(parent, &NilImportError{Module: parent, Index: index})
+		return "", false, true
+	}
+	val := reflect.ValueOf(m)
+	if val.Kind() == reflect.Ptr && val.IsNil() {
+		v.report(parent, &NilImportError{Module: parent, Index: index})
+		return "", false, true
+	}
+	def := m.Definition()
+	name = def.Name
+	if val.Kind() != reflect.Ptr {
+		v.report(name, &ModuleNotPointerError{Module: name})
+		return name, false, true
+	}
+	id := val.Pointer()
+
+	switch v.state[id] {
+	case 1:
+		idx := 0
+		for i, item := range v.stack {
+			if item == id {
+				idx = i
+				break
+			}
+		}
+		path := append(append([]string{}, v.stackNames[idx:]...), name)
+		v.report(name, &ModuleCycleError{Path: path})
+		return name, false, false
+	case 2:
+		return name, v.added[id], !v.skipExports[name]
+	}
+
+	if name != "" {
+		if existing, ok := v.names[name]; ok && existing != id {
+			if !v.reportedNames[name] {
+				v.reportedNames[name] = true
+				v.report(name, &DuplicateModuleNameError{Name: name})
+			}
+			return name, false, true
+		}
+		v.names[name] = id
+	}
+
+	for _, err := range moduleDefIssues(&def) {
+		v.report(name, err)
+	}
+
+	v.state[id] = 1
+	v.stack = append(v.stack, id)
+	v.stackNames = append(v.stackNames, name)
+
+	complete = true
+	imports := make([]string, 0, len(def.Imports))
+	for idx, imp := range def.Imports {
+		impName, impAdded, impComplete := v.visit(imp, name, idx)
+		if impAdded {
+			imports = append(imports, impName)
+		}
+		if !impComplete {
+			complete = false
+		}
+	}
+
+	v.stack = v.stack[:len(v.stack)-1]
+	v.stackNames = v.stackNames[:len(v.stackNames)-1]
+	v.state[id] = 2
+
+	if name == "" {
+		return name, false, complete
+	}
+
+	v.added[id] = true
+	if !complete {
+		v.skipExports[name] = true
+	}
+	v.graph.Modules = append(v.graph.Modules, ModuleNode{
+		Name:    name,
+		Module:  m,
+		Def:     def,
+		Imports: imports,
+	})
+	return name, true, complete
+}
+
+func (v *graphValidator) checkProviders() {
+	owners := make(map[module.Token]string)
+	for i := range v.graph.Modules {
+		node := &v.graph.Modules[i]
+		for _, provider := range node.Def.Providers {
+			if provider.Token == "" {
+				continue
+			}
+			if existing, ok := owners[provider.Token]; ok {
+				v.report(node.Name, &DuplicateProviderTokenError{
+					Token:   provider.Token,
+					Modules: []string{existing, node.Name},
+				})
+				continue
+			}
+			owners[provider.Token] = node.Name
+		}
+	}
+}
+
+func (v *graphValidator) checkControllers() {
+	for i := range v.graph.Modules {
+		node := &v.graph.Modules[i]
+		seen := make(map[string]bool, len(node.Def.Controllers))
+		for _, controller := range node.Def.Controllers {
+			if controller.Name == "" {
+				continue
+			}
+			if seen[controller.Name] {
+				v.report(node.Name, &DuplicateControllerNameError{Module: node.Name, Name: controller.Name})
+				continue
+			}
+			seen[controller.Name] = true
+		}
+	}
+}
+
+func (v *graphValidator) report(moduleName string, err error) {
+	v.issues = append(v.issues, newValidationIssue(moduleName, err))
+}
+
+func newValidationIssue(moduleName string, err error) ValidationIssue {
+	switch e := err.(type) {
+	case *ExportNotVisibleError:
+		moduleName = e.Module
+	case *ExportAmbiguousError:
+		moduleName = e.Module
+	}
+	issue := ValidationIssue{
+		Code:    errorCode(err),
+		Module:  moduleName,
+		Message: err.Error(),
+		Err:     err,
+	}
+	if d, ok := err.(DiagnosticError); ok {
+		issue.Hint = d.Hint()
+	}
+	return issue
+}
@@ -0,0 +1,161 @@
+package kernel_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func validationError(t *testing.T, err error) *kernel.GraphValidationError {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+	var gve *kernel.GraphValidationError
+	if !errors.As(err, &gve) {
+		t.Fatalf("unexpected error type: %T", err)
+	}
+	return gve
+}
+
+func issueCodes(gve *kernel.GraphValidationError) []kernel.ErrorCode {
+	codes := make([]kernel.ErrorCode, 0, len(gve.Issues))
+	for _, issue := range gve.Issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+func TestValidateGraph_ValidGraphReturnsNil(t *testing.T) {
+	token := module.Token("shared.token")
+	imported := mod("Imported", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, []module.Token{token})
+	root := mod("Root", []module.Module{imported}, nil, nil, []module.Token{token})
+
+	if err := kernel.ValidateGraph(root); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateGraph_NilRoot(t *testing.T) {
+	gve := validationError(t, kernel.ValidateGraph(nil))
+	if !gve.HasCode(kernel.CodeRootModuleNil) {
+		t.Fatalf("expected root nil code, got %v", issueCodes(gve))
+	}
+
+	var root *testModule
+	gve = validationError(t, kernel.ValidateGraph(root))
+	if !gve.HasCode(kernel.CodeRootModuleNil) {
+		t.Fatalf("expected root nil code for typed nil, got %v", issueCodes(gve))
+	}
+}
+
+func TestValidateGraph_CollectsAllIssues(t *testing.T) {
+	dup := module.Token("dup.token")
+	missing := module.Token("missing.token")
+	shared := module.Token("shared.token")
+
+	left := mod("Left", nil, []module.ProviderDef{{Token: dup, Build: buildNoop}, {Token: shared, Build: buildNoop}}, nil, []module.Token{shared})
+	right := mod("Right", []module.Module{left}, []module.ProviderDef{{Token: dup, Build: buildNoop}}, nil, []module.Token{shared})
+	unnamed := mod("", nil, nil, nil, nil)
+	broken := mod("Broken", nil,
+		[]module.ProviderDef{{Token: "broken.token"}},
+		[]module.ControllerDef{{Name: "Ctrl", Build: buildNoop}, {Name: "Ctrl", Build: buildNoop}, {Name: "NoBuild"}},
+		[]module.Token{missing},
+	)
+	root := mod("Root", []module.Module{left, right, unnamed, broken, nil}, nil, nil, []module.Token{shared})
+
+	gve := validationError(t, kernel.ValidateGraph(root))
+
+	for _, code := range []kernel.ErrorCode{
+		kernel.CodeModuleNameEmpty,
+		kernel.CodeProviderBuildNil,
+		kernel.CodeControllerBuildNil,
+		kernel.CodeNilImport,
+		kernel.CodeDuplicateProviderToken,
+		kernel.CodeDuplicateControllerName,
+		kernel.CodeExportNotVisible,
+		kernel.CodeExportAmbiguous,
+	} {
+		if !gve.HasCode(code) {
+			t.Fatalf("expected code %s, got %v", code, issueCodes(gve))
+		}
+	}
+
+	var ambiguous *kernel.ExportAmbiguousError
+	if !errors.As(gve, &ambiguous) {
+		t.Fatalf("expected ExportAmbiguousError via errors.As")
+	}
+	if ambiguous.Module != "Root" {
+		t.Fatalf("unexpected ambiguous module: %q", ambiguous.Module)
+	}
+	if !errors.Is(gve, module.ErrInvalidModuleDef) {
+		t.Fatalf("expected ErrInvalidModuleDef via errors.Is")
+	}
+}
+
+func TestValidateGraph_ReportsCycleAndKeepsGoing(t *testing.T) {
+	a := &testModule{}
+	b := &testModule{}
+	a.def = module.ModuleDef{Name: "A", Imports: []module.Module{b}, Providers: []module.ProviderDef{{Token: "a.token"}}}
+	b.def = module.ModuleDef{Name: "B", Imports: []module.Module{a}, Exports: []module.Token{"b.token"}}
+
+	gve := validationError(t, kernel.ValidateGraph(a))
+
+	if !gve.HasCode(kernel.CodeModuleCycle) {
+		t.Fatalf("expected cycle code, got %v", issueCodes(gve))
+	}
+	if !gve.HasCode(kernel.CodeProviderBuildNil) {
+		t.Fatalf("expected provider build nil code, got %v", issueCodes(gve))
+	}
+	if gve.HasCode(kernel.CodeExportNotVisible) {
+		t.Fatalf("exports of modules in a cycle should not be validated, got %v", issueCodes(gve))
+	}
+
+	var cycleErr *kernel.ModuleCycleError
+	if !errors.As(gve, &cycleErr) {
+		t.Fatalf("expected ModuleCycleError via errors.As")
+	}
+	if len(cycleErr.Path) != 3 || cycleErr.Path[0] != "A" || cycleErr.Path[2] != "A" {
+		t.Fatalf("unexpected cycle path: %v", cycleErr.Path)
+	}
+}
+
+func TestValidateGraph_DuplicateModuleNameAndNonPointer(t *testing.T) {
+	first := mod("Shared", nil, nil, nil, nil)
+	second := mod("Shared", nil, nil, nil, nil)
+	byValue := valueModule{def: module.ModuleDef{Name: "ByValue"}}
+	root := mod("Root", []module.Module{first, second, byValue}, nil, nil, nil)
+
+	gve := validationError(t, kernel.ValidateGraph(root))
+
+	if !gve.HasCode(kernel.CodeDuplicateModuleName) {
+		t.Fatalf("expected duplicate module name code, got %v", issueCodes(gve))
+	}
+	if !gve.HasCode(kernel.CodeModuleNotPointer) {
+		t.Fatalf("expected module not pointer code, got %v", issueCodes(gve))
+	}
+}
+
+func TestValidateGraph_IssuesAreJSONSerializable(t *testing.T) {
+	root := mod("Root", nil, nil, nil, []module.Token{"missing"})
+	gve := validationError(t, kernel.ValidateGraph(root))
+
+	data, err := json.Marshal(gve.Issues)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+
+	var decoded []map[string]string
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(decoded) != 1 {
+		t.Fatalf("expected one issue, got %d", len(decoded))
+	}
+	if decoded[0]["code"] != string(kernel.CodeExportNotVisible) || decoded[0]["module"] != "Root" {
+		t.Fatalf("unexpected issue: %v", decoded[0])
+	}
+}
@@ -0,0 +1,36 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// CheckHealth runs the check of every controller implementing
+// module.HealthChecker, in key order, and returns the failures joined as
+// *HealthCheckError values. A panicking check counts as a failure. An app
+// without health checkers is healthy.
+func (a *App) CheckHealth(ctx context.Context) error {
+	var errs []error
+	for _, key := range a.sortedControllerKeys() {
+		checker, ok := a.Controllers[key].(module.HealthChecker)
+		if !ok {
+			continue
+		}
+		if err := checkSafely(ctx, checker); err != nil {
+			errs = append(errs, &HealthCheckError{Name: key, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkSafely(ctx context.Context, checker module.HealthChecker) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return checker.CheckHealth(ctx)
+}
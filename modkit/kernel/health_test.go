@@ -0,0 +1,40 @@
+package kernel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+)
+
+type healthFunc func(ctx context.Context) error
+
+func (f healthFunc) CheckHealth(ctx context.Context) error { return f(ctx) }
+
+func TestAppCheckHealth_HealthyWithoutCheckers(t *testing.T) {
+	app := appWithControllers(map[string]any{"http:api": struct{}{}})
+
+	if err := app.CheckHealth(context.Background()); err != nil {
+		t.Fatalf("expected healthy app, got %v", err)
+	}
+}
+
+func TestAppCheckHealth_JoinsFailures(t *testing.T) {
+	down := errors.New("connection refused")
+	app := appWithControllers(map[string]any{
+		"db:health":    healthFunc(func(context.Context) error { return down }),
+		"cache:health": healthFunc(func(context.Context) error { panic("boom") }),
+		"queue:health": healthFunc(func(context.Context) error { return nil }),
+	})
+
+	err := app.CheckHealth(context.Background())
+
+	if !errors.Is(err, down) {
+		t.Fatalf("expected wrapped check error, got %v", err)
+	}
+	var checkErr *kernel.HealthCheckError
+	if !errors.As(err, &checkErr) || checkErr.Name != "cache:health" {
+		t.Fatalf("expected first failure from cache:health, got %v", err)
+	}
+}
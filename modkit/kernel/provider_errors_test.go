@@ -39,7 +39,7 @@ func mod(
 
 func newTestContainer(t *testing.T, graph *Graph) *testContainer {
 	t.Helper()
-	visibility, err := BuildVisibility(graph)
+	visibility, err := buildVisibility(graph)
 	if err != nil {
 		t.Fatalf("build visibility: %v", err)
 	}
@@ -51,8 +51,7 @@ func newTestContainer(t *testing.T, graph *Graph) *testContainer {
 }
 
 func (c *testContainer) Get(moduleName string, token module.Token) (any, error) {
-	visibility := c.container.visibility[moduleName]
-	if !visibility[token] {
+	if !c.container.visibility.visible(moduleName, token) {
 		return nil, &TokenNotVisibleError{Module: moduleName, Token: token}
 	}
 	return c.container.Get(token)
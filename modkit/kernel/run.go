@@ -0,0 +1,169 @@
+package kernel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// Run starts every controller implementing module.Runnable, plus any extra
+// runnables (for example an HTTP server), and blocks until all of them return.
+//
+// Runnables share a context derived from ctx. The first runnable to return, with
+// or without an error, cancels that context so the others shut down together,
+// and Run returns the first failure wrapped in a RunnableError. Runnables that
+// return after cancellation, with nil or a context error, are treated as a clean
+// stop. One-off work belongs in a command (see RunCommand) rather than a
+// Runnable. Run does not trap OS signals;
+// pass a context from signal.NotifyContext for that. Run does not close the app.
+func (a *App) Run(ctx context.Context, extra ...module.Runnable) error {
+	runnables := make([]namedRunnable, 0, len(extra))
+	for _, key := range a.sortedControllerKeys() {
+		if r, ok := a.Controllers[key].(module.Runnable); ok {
+			runnables = append(runnables, namedRunnable{name: key, runnable: r})
+		}
+	}
+	for idx, r := range extra {
+		if r == nil {
+			return &NilRunnableError{Index: idx}
+		}
+		runnables = append(runnables, namedRunnable{name: fmt.Sprintf("extra[%d]", idx), runnable: r})
+	}
+	if len(runnables) == 0 {
+		return nil
+	}
+
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, nr := range runnables {
+		wg.Add(1)
+		go func(nr namedRunnable) {
+			defer wg.Done()
+			// Any exit stops the group, so an early return starts shutdown.
+			defer cancel()
+			if err := runSafely(groupCtx, nr.runnable); err != nil {
+				if groupCtx.Err() != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+					return
+				}
+				errOnce.Do(func() {
+					firstErr = &RunnableError{Name: nr.name, Err: err}
+					cancel()
+				})
+			}
+		}(nr)
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+type namedRunnable struct {
+	name     string
+	runnable module.Runnable
+}
+
+func runSafely(ctx context.Context, r module.Runnable) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return r.Run(ctx)
+}
+
+// Command is a CLI command contributed by a controller through module.CommandRegistrar.
+type Command struct {
+	Name       string
+	Summary    string
+	Controller string
+	Run        module.CommandFunc
+}
+
+type commandRegistry struct {
+	controller string
+	commands   map[string]Command
+	err        error
+}
+
+func (r *commandRegistry) Command(name, summary string, run module.CommandFunc) {
+	if r.err != nil {
+		return
+	}
+	if name == "" {
+		r.err = &InvalidCommandError{Controller: r.controller, Name: name, Reason: "command name is empty"}
+		return
+	}
+	if run == nil {
+		r.err = &InvalidCommandError{Controller: r.controller, Name: name, Reason: "command run func is nil"}
+		return
+	}
+	if existing, ok := r.commands[name]; ok {
+		r.err = &DuplicateCommandError{Name: name, Controllers: []string{existing.Controller, r.controller}}
+		return
+	}
+	r.commands[name] = Command{Name: name, Summary: summary, Controller: r.controller, Run: run}
+}
+
+// Commands collects the CLI commands of every controller implementing
+// module.CommandRegistrar, sorted by name.
+func (a *App) Commands() ([]Command, error) {
+	registry := &commandRegistry{commands: make(map[string]Command)}
+	for _, key := range a.sortedControllerKeys() {
+		registrar, ok := a.Controllers[key].(module.CommandRegistrar)
+		if !ok {
+			continue
+		}
+		registry.controller = key
+		registrar.RegisterCommands(registry)
+		if registry.err != nil {
+			return nil, registry.err
+		}
+	}
+
+	commands := make([]Command, 0, len(registry.commands))
+	for _, cmd := range registry.commands {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands, nil
+}
+
+// RunCommand runs the command named by args[0] with the remaining arguments.
+func (a *App) RunCommand(ctx context.Context, args []string) error {
+	commands, err := a.Commands()
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+	}
+	available := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd.Run(ctx, args[1:])
+		}
+		available = append(available, cmd.Name)
+	}
+	return &CommandNotFoundError{Name: name, Available: available}
+}
+
+func (a *App) sortedControllerKeys() []string {
+	keys := make([]string, 0, len(a.Controllers))
+	for key := range a.Controllers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
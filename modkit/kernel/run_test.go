@@ -0,0 +1,241 @@
+package kernel_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+type runFunc func(ctx context.Context) error
+
+func (f runFunc) Run(ctx context.Context) error { return f(ctx) }
+
+type commandController struct {
+	names []string
+	calls *[]string
+}
+
+func (c *commandController) RegisterCommands(registry module.CommandRegistry) {
+	for _, name := range c.names {
+		registry.Command(name, "runs "+name, func(_ context.Context, args []string) error {
+			*c.calls = append(*c.calls, name)
+			*c.calls = append(*c.calls, args...)
+			return nil
+		})
+	}
+}
+
+func appWithControllers(controllers map[string]any) *kernel.App {
+	return &kernel.App{Controllers: controllers}
+}
+
+func TestAppRun_StopsOnContextCancel(t *testing.T) {
+	var stopped atomic.Int32
+	worker := runFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Add(1)
+		return ctx.Err()
+	})
+	app := appWithControllers(map[string]any{"jobs:worker": worker, "jobs:other": worker, "http:api": struct{}{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.Run(ctx) }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean stop, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+	if stopped.Load() != 2 {
+		t.Fatalf("expected 2 runnables to stop, got %d", stopped.Load())
+	}
+}
+
+func TestAppRun_FirstErrorCancelsOthers(t *testing.T) {
+	boom := errors.New("boom")
+	failing := runFunc(func(context.Context) error { return boom })
+	waiting := runFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	app := appWithControllers(map[string]any{"jobs:waiting": waiting})
+
+	err := app.Run(context.Background(), failing)
+
+	var runErr *kernel.RunnableError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("expected RunnableError, got %T", err)
+	}
+	if runErr.Name != "extra[0]" || !errors.Is(err, boom) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAppRun_EarlyReturnCancelsOthers(t *testing.T) {
+	finished := runFunc(func(context.Context) error { return nil })
+	var stopped atomic.Bool
+	waiting := runFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		stopped.Store(true)
+		return ctx.Err()
+	})
+	app := appWithControllers(map[string]any{"jobs:waiting": waiting})
+
+	done := make(chan error, 1)
+	go func() { done <- app.Run(context.Background(), finished) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected clean stop, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after a runnable returned")
+	}
+	if !stopped.Load() {
+		t.Fatal("expected the waiting runnable to be canceled")
+	}
+}
+
+func TestAppRun_RecoversPanics(t *testing.T) {
+	app := appWithControllers(map[string]any{
+		"jobs:panics": runFunc(func(context.Context) error { panic("bad") }),
+	})
+
+	err := app.Run(context.Background())
+
+	var runErr *kernel.RunnableError
+	if !errors.As(err, &runErr) || runErr.Name != "jobs:panics" {
+		t.Fatalf("expected RunnableError for jobs:panics, got %v", err)
+	}
+}
+
+func TestAppRun_NoRunnables(t *testing.T) {
+	if err := appWithControllers(nil).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestAppRun_NilExtra(t *testing.T) {
+	err := appWithControllers(nil).Run(context.Background(), nil)
+
+	var nilErr *kernel.NilRunnableError
+	if !errors.As(err, &nilErr) {
+		t.Fatalf("expected NilRunnableError, got %T", err)
+	}
+}
+
+func TestAppRun_WithBootstrappedWorker(t *testing.T) {
+	var ran atomic.Bool
+	worker := runFunc(func(context.Context) error {
+		ran.Store(true)
+		return nil
+	})
+	root := mod("app", nil, nil, []module.ControllerDef{{
+		Name:  "Worker",
+		Build: func(module.Resolver) (any, error) { return worker, nil },
+	}}, nil)
+
+	app, err := kernel.Bootstrap(root)
+	if err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !ran.Load() {
+		t.Fatal("expected worker to run")
+	}
+}
+
+func TestAppCommands_SortedAndDispatched(t *testing.T) {
+	var calls []string
+	app := appWithControllers(map[string]any{
+		"db:cli":   &commandController{names: []string{"migrate", "seed"}, calls: &calls},
+		"jobs:cli": &commandController{names: []string{"drain"}, calls: &calls},
+	})
+
+	commands, err := app.Commands()
+	if err != nil {
+		t.Fatalf("Commands failed: %v", err)
+	}
+	got := make([]string, 0, len(commands))
+	for _, cmd := range commands {
+		got = append(got, cmd.Controller+"/"+cmd.Name)
+	}
+	want := []string{"jobs:cli/drain", "db:cli/migrate", "db:cli/seed"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+
+	if err := app.RunCommand(context.Background(), []string{"migrate", "up"}); err != nil {
+		t.Fatalf("RunCommand failed: %v", err)
+	}
+	if len(calls) != 2 || calls[0] != "migrate" || calls[1] != "up" {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestAppCommands_Duplicate(t *testing.T) {
+	var calls []string
+	app := appWithControllers(map[string]any{
+		"a:cli": &commandController{names: []string{"migrate"}, calls: &calls},
+		"b:cli": &commandController{names: []string{"migrate"}, calls: &calls},
+	})
+
+	_, err := app.Commands()
+
+	var dupErr *kernel.DuplicateCommandError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected DuplicateCommandError, got %T", err)
+	}
+	if dupErr.Controllers[0] != "a:cli" || dupErr.Controllers[1] != "b:cli" {
+		t.Fatalf("unexpected controllers: %v", dupErr.Controllers)
+	}
+}
+
+func TestAppCommands_Invalid(t *testing.T) {
+	var calls []string
+	app := appWithControllers(map[string]any{
+		"a:cli": &commandController{names: []string{""}, calls: &calls},
+	})
+
+	_, err := app.Commands()
+
+	var invalidErr *kernel.InvalidCommandError
+	if !errors.As(err, &invalidErr) {
+		t.Fatalf("expected InvalidCommandError, got %T", err)
+	}
+}
+
+func TestAppRunCommand_NotFound(t *testing.T) {
+	var calls []string
+	app := appWithControllers(map[string]any{
+		"a:cli": &commandController{names: []string{"migrate"}, calls: &calls},
+	})
+
+	err := app.RunCommand(context.Background(), []string{"nope"})
+
+	var notFound *kernel.CommandNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("expected CommandNotFoundError, got %T", err)
+	}
+	if len(notFound.Available) != 1 || notFound.Available[0] != "migrate" {
+		t.Fatalf("unexpected available commands: %v", notFound.Available)
+	}
+}
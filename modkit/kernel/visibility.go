@@ -1,6 +1,10 @@
 package kernel
 
-import "github.com/go-modkit/modkit/modkit/module"
+import (
+	"math/bits"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
 
 // Visibility represents which provider tokens are accessible from each module.
 // The outer map key is the module name, the inner map contains visible tokens.
@@ -12,46 +16,174 @@ func BuildVisibility(graph *Graph) (Visibility, error) {
 	if graph == nil {
 		return nil, ErrNilGraph
 	}
-	return buildVisibility(graph)
+	index, err := buildVisibility(graph)
+	if err != nil {
+		return nil, err
+	}
+	return index.toMap(), nil
+}
+
+// tokenSet is a bitset over interned token IDs.
+type tokenSet []uint64
+
+func newTokenSet(size int) tokenSet {
+	return make(tokenSet, (size+63)/64)
+}
+
+func (s tokenSet) has(id int) bool {
+	word := id / 64
+	if word >= len(s) {
+		return false
+	}
+	return s[word]&(1<<(uint(id)%64)) != 0
+}
+
+func (s tokenSet) add(id int) {
+	s[id/64] |= 1 << (uint(id) % 64)
 }
 
-func buildVisibility(graph *Graph) (Visibility, error) {
-	visibility := make(Visibility)
-	effectiveExports := make(map[string]map[module.Token]bool)
+func (s tokenSet) union(other tokenSet) {
+	for i, word := range other {
+		s[i] |= word
+	}
+}
+
+func (s tokenSet) each(fn func(id int)) {
+	for i, word := range s {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			fn(i*64 + bit)
+			word &^= 1 << uint(bit)
+		}
+	}
+}
+
+// visibilityIndex is the compact visibility representation used by the container.
+// Provider tokens are interned to dense IDs and each module holds a bitset of the
+// IDs it can resolve. Modules without exports share a nil export set.
+type visibilityIndex struct {
+	tokens  []module.Token
+	ids     map[module.Token]int
+	modules map[string]int
+	sets    []tokenSet
+}
+
+func (v *visibilityIndex) visible(moduleName string, token module.Token) bool {
+	if v == nil {
+		return false
+	}
+	modIdx, ok := v.modules[moduleName]
+	if !ok {
+		return false
+	}
+	id, ok := v.ids[token]
+	if !ok {
+		return false
+	}
+	return v.sets[modIdx].has(id)
+}
+
+func (v *visibilityIndex) toMap() Visibility {
+	visibility := make(Visibility, len(v.modules))
+	for name, modIdx := range v.modules {
+		visible := make(map[module.Token]bool)
+		v.sets[modIdx].each(func(id int) {
+			visible[v.tokens[id]] = true
+		})
+		visibility[name] = visible
+	}
+	return visibility
+}
+
+func buildVisibility(graph *Graph) (*visibilityIndex, error) {
+	index, issues := computeVisibility(graph, false, nil)
+	if len(issues) > 0 {
+		return nil, issues[0]
+	}
+	return index, nil
+}
+
+// computeVisibility builds the visibility index and validates exports. When collect
+// is false it stops at the first problem; otherwise it records every problem and keeps
+// going. Exports of modules listed in skip are not validated or propagated.
+func computeVisibility(graph *Graph, collect bool, skip map[string]bool) (*visibilityIndex, []error) {
+	index := &visibilityIndex{
+		ids:     make(map[module.Token]int),
+		modules: make(map[string]int, len(graph.Modules)),
+		sets:    make([]tokenSet, len(graph.Modules)),
+	}
+	for i := range graph.Modules {
+		for _, provider := range graph.Modules[i].Def.Providers {
+			if _, ok := index.ids[provider.Token]; ok {
+				continue
+			}
+			index.ids[provider.Token] = len(index.tokens)
+			index.tokens = append(index.tokens, provider.Token)
+		}
+	}
+
+	size := len(index.tokens)
+	effectiveExports := make([]tokenSet, len(graph.Modules))
+	var issues []error
 
 	for i := range graph.Modules {
 		node := &graph.Modules[i]
-		visible := make(map[module.Token]bool)
-		importExporters := make(map[module.Token][]string)
+		index.modules[node.Name] = i
+
+		visible := newTokenSet(size)
 		for _, provider := range node.Def.Providers {
-			visible[provider.Token] = true
+			visible.add(index.ids[provider.Token])
 		}
 
+		importIdx := make([]int, 0, len(node.Imports))
 		for _, impName := range node.Imports {
-			for token := range effectiveExports[impName] {
-				visible[token] = true
-				importExporters[token] = append(importExporters[token], impName)
+			impIdx, ok := index.modules[impName]
+			if !ok {
+				continue
 			}
+			importIdx = append(importIdx, impIdx)
+			visible.union(effectiveExports[impIdx])
 		}
+		index.sets[i] = visible
 
-		exports := make(map[module.Token]bool)
+		if skip[node.Name] {
+			continue
+		}
+
+		var exports tokenSet
 		for _, token := range node.Def.Exports {
-			if !visible[token] {
-				return nil, &ExportNotVisibleError{Module: node.Name, Token: token}
+			id, ok := index.ids[token]
+			if !ok || !visible.has(id) {
+				issues = append(issues, &ExportNotVisibleError{Module: node.Name, Token: token})
+				if !collect {
+					return nil, issues
+				}
+				continue
 			}
-			if len(importExporters[token]) > 1 {
-				return nil, &ExportAmbiguousError{
+			var exporters []string
+			for _, impIdx := range importIdx {
+				if effectiveExports[impIdx].has(id) {
+					exporters = append(exporters, graph.Modules[impIdx].Name)
+				}
+			}
+			if len(exporters) > 1 {
+				issues = append(issues, &ExportAmbiguousError{
 					Module:  node.Name,
 					Token:   token,
-					Imports: importExporters[token],
+					Imports: exporters,
+				})
+				if !collect {
+					return nil, issues
 				}
 			}
-			exports[token] = true
+			if exports == nil {
+				exports = newTokenSet(size)
+			}
+			exports.add(id)
 		}
 
-		visibility[node.Name] = visible
-		effectiveExports[node.Name] = exports
+		effectiveExports[i] = exports
 	}
 
-	return visibility, nil
+	return index, issues
 }
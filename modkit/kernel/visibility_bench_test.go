@@ -0,0 +1,82 @@
+package kernel_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+const (
+	benchModules            = 1000
+	benchProvidersPerModule = 5
+)
+
+// syntheticGraphRoot builds a layered graph where every module imports up to
+// three earlier modules and exports all of its own providers. The root imports
+// every leaf so the whole graph is reachable.
+func syntheticGraphRoot(modules, providersPerModule int) module.Module {
+	nodes := make([]module.Module, modules)
+	for i := 0; i < modules; i++ {
+		name := "mod" + strconv.Itoa(i)
+		providers := make([]module.ProviderDef, 0, providersPerModule)
+		exports := make([]module.Token, 0, providersPerModule)
+		for p := 0; p < providersPerModule; p++ {
+			token := module.Token(name + ".provider" + strconv.Itoa(p))
+			providers = append(providers, module.ProviderDef{Token: token, Build: buildNoop})
+			exports = append(exports, token)
+		}
+
+		var imports []module.Module
+		for _, j := range []int{i - 1, i / 2, i / 3} {
+			if j < 0 || j >= i {
+				continue
+			}
+			duplicate := false
+			for _, imp := range imports {
+				if imp == nodes[j] {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				imports = append(imports, nodes[j])
+			}
+		}
+
+		nodes[i] = mod(name, imports, providers, nil, exports)
+	}
+
+	return mod("root", nodes, nil, nil, nil)
+}
+
+func BenchmarkBootstrap_1kModules(b *testing.B) {
+	root := syntheticGraphRoot(benchModules, benchProvidersPerModule)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := kernel.Bootstrap(root); err != nil {
+			b.Fatalf("Bootstrap failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkBuildVisibility_1kModules measures the bitset index used by the
+// container; the Visibility map returned by BuildVisibility is not built.
+func BenchmarkBuildVisibility_1kModules(b *testing.B) {
+	root := syntheticGraphRoot(benchModules, benchProvidersPerModule)
+	g, err := kernel.BuildGraph(root)
+	if err != nil {
+		b.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := kernel.BuildVisibilityIndex(g); err != nil {
+			b.Fatalf("BuildVisibilityIndex failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package kernel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// VisibilityExplanation describes why a token is or is not visible from a module.
+type VisibilityExplanation struct {
+	// Module is the module the token was requested from.
+	Module string `json:"module"`
+	// Token is the requested token.
+	Token module.Token `json:"token"`
+	// Visible reports whether Module can resolve Token.
+	Visible bool `json:"visible"`
+	// Owner is the module that provides Token, or empty if no module does.
+	Owner string `json:"owner,omitempty"`
+	// Path is the import chain from Module to Owner. When Visible is true every
+	// module after the first exports Token; otherwise the chain breaks at BrokenAt.
+	// Path is empty when Owner is not reachable through Module's imports.
+	Path []string `json:"path,omitempty"`
+	// BrokenAt is the module nearest to Module on Path that does not export Token.
+	BrokenAt string `json:"broken_at,omitempty"`
+	// Candidates lists modules, other than Module, that export Token and could be imported.
+	Candidates []string `json:"candidates,omitempty"`
+}
+
+// ExplainVisibility reports the import/export path that makes token visible from
+// moduleName, or where that chain breaks: the owning module, the nearest importer
+// that does not re-export the token, and modules that export it and could be imported.
+func ExplainVisibility(graph *Graph, moduleName string, token module.Token) (*VisibilityExplanation, error) {
+	if graph == nil {
+		return nil, ErrNilGraph
+	}
+	if _, err := graphNodeByName(graph, moduleName); err != nil {
+		return nil, err
+	}
+	return explainVisibility(graph, nil, moduleName, token), nil
+}
+
+// explainVisibility explains token for moduleName, using index when the caller
+// already has one and computing it otherwise.
+func explainVisibility(graph *Graph, index *visibilityIndex, moduleName string, token module.Token) *VisibilityExplanation {
+	exp := &VisibilityExplanation{Module: moduleName, Token: token}
+
+	owns := func(node *ModuleNode) bool {
+		for _, provider := range node.Def.Providers {
+			if provider.Token == token {
+				return true
+			}
+		}
+		return false
+	}
+	exports := func(node *ModuleNode) bool {
+		for _, exported := range node.Def.Exports {
+			if exported == token {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range graph.Modules {
+		node := &graph.Modules[i]
+		if exp.Owner == "" && owns(node) {
+			exp.Owner = node.Name
+		}
+		if node.Name != moduleName && exports(node) {
+			exp.Candidates = append(exp.Candidates, node.Name)
+		}
+	}
+	sort.Strings(exp.Candidates)
+
+	if exp.Owner == "" {
+		return exp
+	}
+
+	if index == nil {
+		index, _ = computeVisibility(graph, true, nil)
+	}
+	exp.Visible = index.visible(moduleName, token)
+	if exp.Visible {
+		exp.Candidates = nil
+		// Prefer a chain that exports the token all the way, which exists
+		// whenever the token is visible; a shorter chain may break.
+		exp.Path = shortestImportPath(graph, moduleName, exp.Owner, func(name string) bool {
+			return exports(graph.Nodes[name])
+		})
+		if len(exp.Path) > 0 {
+			return exp
+		}
+	}
+
+	exp.Path = shortestImportPath(graph, moduleName, exp.Owner, nil)
+	if exp.Visible || len(exp.Path) == 0 {
+		return exp
+	}
+	for _, name := range exp.Path[1:] {
+		if !exports(graph.Nodes[name]) {
+			exp.BrokenAt = name
+			break
+		}
+	}
+	return exp
+}
+
+// shortestImportPath returns the shortest import chain from one module to another,
+// preferring earlier imports on ties, or nil if to is not reachable. When through
+// is set, modules after from must satisfy it.
+func shortestImportPath(graph *Graph, from, to string, through func(name string) bool) []string {
+	if from == to {
+		return []string{from}
+	}
+	parent := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		node, ok := graph.Nodes[current]
+		if !ok {
+			continue
+		}
+		for _, imp := range node.Imports {
+			if _, seen := parent[imp]; seen {
+				continue
+			}
+			if through != nil && !through(imp) {
+				continue
+			}
+			parent[imp] = current
+			if imp == to {
+				path := []string{to}
+				for step := current; step != ""; step = parent[step] {
+					path = append(path, step)
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			queue = append(queue, imp)
+		}
+	}
+	return nil
+}
+
+// String returns a one-line, human-readable explanation.
+func (e *VisibilityExplanation) String() string {
+	if e.Visible {
+		return fmt.Sprintf("token %q is visible from %q via %s", e.Token, e.Module, strings.Join(e.Path, " -> "))
+	}
+	return fmt.Sprintf("token %q is not visible from %q: %s", e.Token, e.Module, e.reason())
+}
+
+func (e *VisibilityExplanation) reason() string {
+	switch {
+	case e.Owner == "":
+		return "no module provides it"
+	case e.BrokenAt != "":
+		return fmt.Sprintf("provided by %q; import chain %s breaks at %q, which does not export it",
+			e.Owner, strings.Join(e.Path, " -> "), e.BrokenAt)
+	case len(e.Candidates) > 0:
+		return fmt.Sprintf("provided by %q, which %q does not import; exported by %s",
+			e.Owner, e.Module, quoteList(e.Candidates))
+	default:
+		return fmt.Sprintf("provided by %q, which neither exports it nor is imported by %q", e.Owner, e.Module)
+	}
+}
+
+// remediation returns a concrete fix for a token that is not visible.
+func (e *VisibilityExplanation) remediation() string {
+	switch {
+	case e.Owner == "":
+		return fmt.Sprintf("register a ProviderDef with Token %q in a module imported by %q", e.Token, e.Module)
+	case e.BrokenAt != "":
+		return fmt.Sprintf("add %q to Exports of module %q", e.Token, e.BrokenAt)
+	case len(e.Candidates) > 0:
+		return fmt.Sprintf("import one of %s into module %q", quoteList(e.Candidates), e.Module)
+	default:
+		return fmt.Sprintf("add %q to Exports of module %q and import %q into %q", e.Token, e.Owner, e.Owner, e.Module)
+	}
+}
@@ -0,0 +1,202 @@
+package kernel_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func TestExplainVisibility_VisibleThroughReExports(t *testing.T) {
+	token := module.Token("shared.token")
+	base := mod("Base", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, []module.Token{token})
+	middle := mod("Middle", []module.Module{base}, nil, nil, []module.Token{token})
+	root := mod("Root", []module.Module{middle}, nil, nil, nil)
+
+	g, err := kernel.BuildGraph(root)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "Root", token)
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if !exp.Visible || exp.Owner != "Base" {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+	if strings.Join(exp.Path, ",") != "Root,Middle,Base" {
+		t.Fatalf("unexpected path: %v", exp.Path)
+	}
+	if !strings.Contains(exp.String(), "Root -> Middle -> Base") {
+		t.Fatalf("unexpected string: %q", exp.String())
+	}
+}
+
+func TestExplainVisibility_OwnProvider(t *testing.T) {
+	token := module.Token("own.token")
+	root := mod("Root", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, nil)
+
+	g, err := kernel.BuildGraph(root)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "Root", token)
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if !exp.Visible || len(exp.Path) != 1 {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+}
+
+func TestExplainVisibility_ReportsBreakInChain(t *testing.T) {
+	token := module.Token("shared.token")
+	base := mod("Base", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, []module.Token{token})
+	middle := mod("Middle", []module.Module{base}, nil, nil, nil)
+	root := mod("Root", []module.Module{middle}, nil, nil, nil)
+
+	g, err := kernel.BuildGraph(root)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "Root", token)
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if exp.Visible {
+		t.Fatalf("expected token not to be visible")
+	}
+	if exp.Owner != "Base" || exp.BrokenAt != "Middle" {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+	if strings.Join(exp.Candidates, ",") != "Base" {
+		t.Fatalf("unexpected candidates: %v", exp.Candidates)
+	}
+}
+
+func TestExplainVisibility_OwnerNotImported(t *testing.T) {
+	token := module.Token("shared.token")
+	owner := mod("Owner", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, []module.Token{token})
+	other := mod("Other", nil, nil, nil, nil)
+	root := mod("Root", []module.Module{other, owner}, nil, nil, nil)
+
+	g, err := kernel.BuildGraph(root)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "Other", token)
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if exp.Visible || len(exp.Path) != 0 || exp.BrokenAt != "" {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+	if strings.Join(exp.Candidates, ",") != "Owner" {
+		t.Fatalf("unexpected candidates: %v", exp.Candidates)
+	}
+	if !strings.Contains(exp.String(), `"Other" does not import`) {
+		t.Fatalf("unexpected string: %q", exp.String())
+	}
+}
+
+func TestExplainVisibility_NoProvider(t *testing.T) {
+	g, err := kernel.BuildGraph(mod("Root", nil, nil, nil, nil))
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "Root", "missing")
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if exp.Visible || exp.Owner != "" {
+		t.Fatalf("unexpected explanation: %+v", exp)
+	}
+	if !strings.Contains(exp.String(), "no module provides it") {
+		t.Fatalf("unexpected string: %q", exp.String())
+	}
+}
+
+func TestExplainVisibility_Errors(t *testing.T) {
+	if _, err := kernel.ExplainVisibility(nil, "Root", "t"); !errors.Is(err, kernel.ErrNilGraph) {
+		t.Fatalf("expected ErrNilGraph, got %v", err)
+	}
+
+	g, err := kernel.BuildGraph(mod("Root", nil, nil, nil, nil))
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	if _, err := kernel.ExplainVisibility(g, "Missing", "t"); !errors.Is(err, kernel.ErrGraphNodeNotFound) {
+		t.Fatalf("expected ErrGraphNodeNotFound, got %v", err)
+	}
+}
+
+func TestTokenNotVisibleError_IncludesExplanation(t *testing.T) {
+	token := module.Token("hidden.token")
+	base := mod("Base", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, nil)
+	root := mod("Root", []module.Module{base}, nil, []module.ControllerDef{{
+		Name:  "Ctrl",
+		Build: func(r module.Resolver) (any, error) { return r.Get(token) },
+	}}, nil)
+
+	_, err := kernel.Bootstrap(root)
+
+	var visErr *kernel.TokenNotVisibleError
+	if !errors.As(err, &visErr) {
+		t.Fatalf("expected TokenNotVisibleError, got %T", err)
+	}
+	if visErr.Explanation == nil {
+		t.Fatalf("expected explanation")
+	}
+	if visErr.Explanation.Owner != "Base" || visErr.Explanation.BrokenAt != "Base" {
+		t.Fatalf("unexpected explanation: %+v", visErr.Explanation)
+	}
+	if !strings.Contains(visErr.Error(), `breaks at "Base"`) {
+		t.Fatalf("expected explanation in message: %q", visErr.Error())
+	}
+	if visErr.Hint() != `add "hidden.token" to Exports of module "Base"` {
+		t.Fatalf("unexpected hint: %q", visErr.Hint())
+	}
+	if visErr.Fields()["broken_at"] != "Base" {
+		t.Fatalf("unexpected fields: %v", visErr.Fields())
+	}
+}
+
+func TestExplainVisibility_VisibleThroughLongerExportChain(t *testing.T) {
+	token := module.Token("shared.token")
+	exports := []module.Token{token}
+	owner := mod("Owner", nil, []module.ProviderDef{{Token: token, Build: buildNoop}}, nil, exports)
+	b := mod("B", []module.Module{owner}, nil, nil, nil)
+	d := mod("D", []module.Module{owner}, nil, nil, exports)
+	c := mod("C", []module.Module{d}, nil, nil, exports)
+	a := mod("A", []module.Module{b, c}, nil, nil, nil)
+
+	g, err := kernel.BuildGraph(a)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+	visibility, err := kernel.BuildVisibility(g)
+	if err != nil {
+		t.Fatalf("BuildVisibility failed: %v", err)
+	}
+	if !visibility["A"][token] {
+		t.Fatalf("expected token to be visible from A")
+	}
+
+	exp, err := kernel.ExplainVisibility(g, "A", token)
+	if err != nil {
+		t.Fatalf("ExplainVisibility failed: %v", err)
+	}
+	if !exp.Visible || exp.BrokenAt != "" {
+		t.Fatalf("expected visible explanation, got %+v", exp)
+	}
+	if strings.Join(exp.Path, ",") != "A,C,D,Owner" {
+		t.Fatalf("unexpected path: %v", exp.Path)
+	}
+}
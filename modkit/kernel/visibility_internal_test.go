@@ -0,0 +1,70 @@
+package kernel
+
+import (
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+func TestTokenSet_AddHasEach(t *testing.T) {
+	set := newTokenSet(130)
+	for _, id := range []int{0, 63, 64, 129} {
+		set.add(id)
+	}
+
+	for _, id := range []int{0, 63, 64, 129} {
+		if !set.has(id) {
+			t.Fatalf("expected id %d to be set", id)
+		}
+	}
+	if set.has(1) || set.has(200) {
+		t.Fatalf("unexpected ids set")
+	}
+
+	var got []int
+	set.each(func(id int) { got = append(got, id) })
+	if len(got) != 4 || got[0] != 0 || got[3] != 129 {
+		t.Fatalf("unexpected ids: %v", got)
+	}
+}
+
+func TestVisibilityIndex_MatchesMapForm(t *testing.T) {
+	shared := module.Token("shared.token")
+	private := module.Token("private.token")
+	local := module.Token("local.token")
+
+	base := modInternal("Base", nil, []module.ProviderDef{
+		{Token: shared, Build: func(module.Resolver) (any, error) { return nil, nil }},
+		{Token: private, Build: func(module.Resolver) (any, error) { return nil, nil }},
+	}, nil, []module.Token{shared})
+	root := modInternal("Root", []module.Module{base}, []module.ProviderDef{
+		{Token: local, Build: func(module.Resolver) (any, error) { return nil, nil }},
+	}, nil, nil)
+
+	g, err := BuildGraph(root)
+	if err != nil {
+		t.Fatalf("BuildGraph failed: %v", err)
+	}
+
+	index, err := buildVisibility(g)
+	if err != nil {
+		t.Fatalf("buildVisibility failed: %v", err)
+	}
+	vis := index.toMap()
+
+	for _, moduleName := range []string{"Base", "Root", "Missing"} {
+		for _, token := range []module.Token{shared, private, local, "unknown"} {
+			if index.visible(moduleName, token) != vis[moduleName][token] {
+				t.Fatalf("mismatch for %s/%s", moduleName, token)
+			}
+		}
+	}
+	if !index.visible("Root", shared) || index.visible("Root", private) {
+		t.Fatalf("unexpected root visibility")
+	}
+
+	var nilIndex *visibilityIndex
+	if nilIndex.visible("Root", shared) {
+		t.Fatalf("nil index must not report visibility")
+	}
+}
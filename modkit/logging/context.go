@@ -0,0 +1,20 @@
+package logging
+
+import "context"
+
+type loggerKey struct{}
+
+// NewContext returns a copy of ctx carrying logger.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger stored with NewContext, such as the
+// request-scoped logger added by the HTTP tracing middleware. It returns a
+// logger that discards everything when ctx has none.
+func FromContext(ctx context.Context) Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(Logger); ok && logger != nil {
+		return logger
+	}
+	return NewNopLogger()
+}
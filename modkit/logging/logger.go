@@ -1,6 +1,11 @@
 // Package logging provides a minimal, structured logging interface for modkit applications.
 package logging
 
+import (
+	"context"
+	"log/slog"
+)
+
 // Logger is the core logging interface used throughout modkit.
 // It provides structured logging with key-value pairs and log levels.
 type Logger interface {
@@ -10,3 +15,67 @@ type Logger interface {
 	Error(msg string, args ...any)
 	With(args ...any) Logger
 }
+
+// Level is a log level; it is the slog level, so a *slog.LevelVar adjusts it
+// atomically at runtime.
+type Level = slog.Level
+
+// Log levels.
+const (
+	LevelDebug = slog.LevelDebug
+	LevelInfo  = slog.LevelInfo
+	LevelWarn  = slog.LevelWarn
+	LevelError = slog.LevelError
+)
+
+// ContextLogger extends Logger with context-aware methods, which pass the
+// context to the handler (for example for trace correlation), and a level
+// check for skipping expensive argument construction.
+type ContextLogger interface {
+	Logger
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+	Enabled(level Level) bool
+}
+
+// Extend returns logger as a ContextLogger. Loggers that only implement Logger
+// ignore the context and report every level as enabled.
+func Extend(logger Logger) ContextLogger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	if extended, ok := logger.(ContextLogger); ok {
+		return extended
+	}
+	return contextAdapter{Logger: logger}
+}
+
+type contextAdapter struct {
+	Logger
+}
+
+func (a contextAdapter) DebugContext(_ context.Context, msg string, args ...any) {
+	a.Debug(msg, args...)
+}
+
+func (a contextAdapter) InfoContext(_ context.Context, msg string, args ...any) {
+	a.Info(msg, args...)
+}
+
+func (a contextAdapter) WarnContext(_ context.Context, msg string, args ...any) {
+	a.Warn(msg, args...)
+}
+
+func (a contextAdapter) ErrorContext(_ context.Context, msg string, args ...any) {
+	a.Error(msg, args...)
+}
+
+func (a contextAdapter) Enabled(Level) bool {
+	return true
+}
+
+func (a contextAdapter) With(args ...any) Logger {
+	return contextAdapter{Logger: a.Logger.With(args...)}
+}
@@ -74,3 +74,44 @@ func TestNopLogger(_ *testing.T) {
 	logger.Error("error")
 	logger.With("key", "value").Info("with")
 }
+
+func TestFromContext(t *testing.T) {
+	if _, ok := FromContext(context.Background()).(nopLogger); !ok {
+		t.Fatalf("expected nop logger without a stored logger")
+	}
+
+	ch := &captureHandler{}
+	ctx := NewContext(context.Background(), NewSlogLogger(slog.New(ch)))
+	FromContext(ctx).Info("scoped")
+	if len(ch.records) != 1 || ch.records[0].Message != "scoped" {
+		t.Fatalf("expected record from stored logger, got %+v", ch.records)
+	}
+}
+
+type plainLogger struct {
+	messages []string
+}
+
+func (p *plainLogger) Debug(msg string, _ ...any) { p.messages = append(p.messages, msg) }
+func (p *plainLogger) Info(msg string, _ ...any)  { p.messages = append(p.messages, msg) }
+func (p *plainLogger) Warn(msg string, _ ...any)  { p.messages = append(p.messages, msg) }
+func (p *plainLogger) Error(msg string, _ ...any) { p.messages = append(p.messages, msg) }
+func (p *plainLogger) With(...any) Logger         { return p }
+
+func TestExtend(t *testing.T) {
+	slogLogger := NewSlogLogger(slog.New(&captureHandler{}))
+	if Extend(slogLogger) != slogLogger {
+		t.Fatalf("expected slog logger to be returned as is")
+	}
+	if Extend(nil).Enabled(LevelError) {
+		t.Fatalf("expected nil logger to extend to a nop logger")
+	}
+
+	plain := &plainLogger{}
+	extended := Extend(plain)
+	extended.InfoContext(context.Background(), "info")
+	extended.With("k", "v").(ContextLogger).ErrorContext(context.Background(), "error")
+	if !extended.Enabled(LevelDebug) || len(plain.messages) != 2 {
+		t.Fatalf("unexpected adapter behavior: %v", plain.messages)
+	}
+}
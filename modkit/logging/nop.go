@@ -1,5 +1,7 @@
 package logging
 
+import "context"
+
 type nopLogger struct{}
 
 // NewNopLogger returns a Logger that discards all log messages.
@@ -8,8 +10,13 @@ func NewNopLogger() Logger {
 	return nopLogger{}
 }
 
-func (nopLogger) Debug(string, ...any) {}
-func (nopLogger) Info(string, ...any)  {}
-func (nopLogger) Warn(string, ...any)  {}
-func (nopLogger) Error(string, ...any) {}
-func (nopLogger) With(...any) Logger   { return nopLogger{} }
+func (nopLogger) Debug(string, ...any)                         {}
+func (nopLogger) Info(string, ...any)                          {}
+func (nopLogger) Warn(string, ...any)                          {}
+func (nopLogger) Error(string, ...any)                         {}
+func (nopLogger) With(...any) Logger                           { return nopLogger{} }
+func (nopLogger) DebugContext(context.Context, string, ...any) {}
+func (nopLogger) InfoContext(context.Context, string, ...any)  {}
+func (nopLogger) WarnContext(context.Context, string, ...any)  {}
+func (nopLogger) ErrorContext(context.Context, string, ...any) {}
+func (nopLogger) Enabled(Level) bool                           { return false }
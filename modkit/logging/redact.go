@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces redacted attribute values.
+const Redacted = "[REDACTED]"
+
+// Secret is a string that never appears in logs: slog handlers, fmt, and the
+// redaction of NewSlogLoggerWithOptions all render it as Redacted. Convert a
+// sensitive value before logging it, for example logging.Secret(dsn).
+type Secret string
+
+// LogValue implements slog.LogValuer.
+func (Secret) LogValue() slog.Value { return slog.StringValue(Redacted) }
+
+// String implements fmt.Stringer.
+func (Secret) String() string { return Redacted }
+
+// GoString implements fmt.GoStringer.
+func (Secret) GoString() string { return Redacted }
+
+// filterHandler applies Options to a slog.Handler.
+type filterHandler struct {
+	next   slog.Handler
+	level  *slog.LevelVar
+	redact map[string]bool
+	// values replaces RedactValues with Redacted; nil without any.
+	values *strings.Replacer
+}
+
+func newFilterHandler(next slog.Handler, opts Options) *filterHandler {
+	h := &filterHandler{next: next, level: opts.Level, redact: make(map[string]bool, len(opts.RedactKeys))}
+	for _, key := range opts.RedactKeys {
+		h.redact[strings.ToLower(key)] = true
+	}
+	h.values = valueReplacer(opts.RedactValues)
+	return h
+}
+
+// valueReplacer returns a replacer of values with Redacted, longest first so a
+// value containing another is replaced whole.
+func valueReplacer(values []string) *strings.Replacer {
+	values = slices.DeleteFunc(slices.Clone(values), func(v string) bool { return v == "" })
+	if len(values) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(values, func(a, b string) int { return len(b) - len(a) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		pairs = append(pairs, v, Redacted)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+func (h *filterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// The LevelVar alone decides when set: the wrapped handler's own minimum
+	// (Info for a handler built with nil options) would otherwise block lowering
+	// the level at runtime.
+	if h.level != nil {
+		return level >= h.level.Level()
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+//nolint:gocritic // slog.Record is standard library signature
+func (h *filterHandler) Handle(ctx context.Context, record slog.Record) error {
+	msg := record.Message
+	if h.values != nil {
+		msg = h.values.Replace(msg)
+	}
+	redacted := slog.NewRecord(record.Time, record.Level, msg, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *filterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+	return &filterHandler{next: h.next.WithAttrs(redacted), level: h.level, redact: h.redact, values: h.values}
+}
+
+func (h *filterHandler) WithGroup(name string) slog.Handler {
+	return &filterHandler{next: h.next.WithGroup(name), level: h.level, redact: h.redact, values: h.values}
+}
+
+func (h *filterHandler) redactAttr(attr slog.Attr) slog.Attr {
+	if h.redact[strings.ToLower(attr.Key)] {
+		return slog.String(attr.Key, Redacted)
+	}
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() != slog.KindGroup {
+		return h.redactValue(attr)
+	}
+	group := attr.Value.Group()
+	redacted := make([]slog.Attr, len(group))
+	for i, member := range group {
+		redacted[i] = h.redactAttr(member)
+	}
+	return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+}
+
+// redactValue replaces RedactValues inside string, error, and fmt.Stringer
+// values.
+func (h *filterHandler) redactValue(attr slog.Attr) slog.Attr {
+	if h.values == nil {
+		return attr
+	}
+	var text string
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		text = attr.Value.String()
+	case slog.KindAny:
+		switch v := attr.Value.Any().(type) {
+		case error:
+			text = v.Error()
+		case fmt.Stringer:
+			text = v.String()
+		default:
+			return attr
+		}
+	default:
+		return attr
+	}
+	if replaced := h.values.Replace(text); replaced != text {
+		return slog.String(attr.Key, replaced)
+	}
+	return attr
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLoggerWithOptions_RedactsKeysAndSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLoggerWithOptions(slog.New(slog.NewJSONHandler(&buf, nil)), Options{
+		RedactKeys: []string{"Password", "authorization"},
+	})
+
+	logger.With("authorization", "Bearer abc").Info("login",
+		"user", "ada",
+		"password", "hunter2",
+		"dsn", Secret("postgres://u:p@db"),
+		slog.Group("request", "PASSWORD", "nested-secret"),
+	)
+
+	out := buf.String()
+	for _, leaked := range []string{"hunter2", "Bearer abc", "postgres://", "nested-secret"} {
+		if strings.Contains(out, leaked) {
+			t.Fatalf("log leaked %q: %s", leaked, out)
+		}
+	}
+	if !strings.Contains(out, `"user":"ada"`) || strings.Count(out, Redacted) != 4 {
+		t.Fatalf("unexpected output: %s", out)
+	}
+}
+
+func TestSlogLoggerWithOptions_RedactsValues(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewSlogLoggerWithOptions(slog.New(slog.NewJSONHandler(&buf, nil)), Options{
+		RedactValues: []string{"", "hunter2", "hunter2-admin"},
+	})
+
+	logger.With("token", "hunter2").Info("login with hunter2-admin",
+		"user", "ada",
+		"err", errors.New("bad password hunter2"),
+		slog.Group("request", "query", "pw=hunter2&x=1"),
+	)
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") {
+		t.Fatalf("log leaked secret: %s", out)
+	}
+	for _, want := range []string{`"msg":"login with [REDACTED]"`, `"err":"bad password [REDACTED]"`, `"query":"pw=[REDACTED]&x=1"`, `"user":"ada"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in output: %s", want, out)
+		}
+	}
+}
+
+func TestSlogLoggerWithOptions_LevelOverridesHandlerMinimum(t *testing.T) {
+	var buf bytes.Buffer
+	level := &slog.LevelVar{}
+	logger := NewSlogLoggerWithOptions(slog.New(slog.NewJSONHandler(&buf, nil)), Options{Level: level})
+
+	logger.Debug("dropped")
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug to be dropped at info, got %q", buf.String())
+	}
+
+	level.Set(LevelDebug)
+	if !logger.Enabled(LevelDebug) {
+		t.Fatalf("expected debug to be enabled after level change")
+	}
+	logger.Debug("kept")
+	if !strings.Contains(buf.String(), `"msg":"kept"`) {
+		t.Fatalf("expected debug record from JSON handler, got %q", buf.String())
+	}
+}
+
+func TestSecret_Formatting(t *testing.T) {
+	secret := Secret("hunter2")
+	for _, got := range []string{fmt.Sprint(secret), fmt.Sprintf("%v %s %#v", secret, secret, secret)} {
+		if strings.Contains(got, "hunter2") {
+			t.Fatalf("secret leaked: %q", got)
+		}
+	}
+}
+
+func TestSlogLoggerWithOptions_AtomicLevel(t *testing.T) {
+	ch := &captureHandler{}
+	level := &slog.LevelVar{}
+	level.Set(LevelWarn)
+	logger := NewSlogLoggerWithOptions(slog.New(ch), Options{Level: level})
+
+	logger.Info("dropped")
+	logger.WarnContext(context.Background(), "kept")
+	if len(ch.records) != 1 || ch.records[0].Message != "kept" {
+		t.Fatalf("expected only the warning, got %+v", ch.records)
+	}
+	if logger.Enabled(LevelDebug) {
+		t.Fatalf("expected debug to be disabled")
+	}
+
+	level.Set(LevelDebug)
+	if !logger.Enabled(LevelDebug) {
+		t.Fatalf("expected debug to be enabled after level change")
+	}
+	logger.DebugContext(context.Background(), "now kept")
+	if len(ch.records) != 2 {
+		t.Fatalf("expected debug record, got %d records", len(ch.records))
+	}
+}
@@ -1,13 +1,16 @@
 package logging
 
-import "log/slog"
+import (
+	"context"
+	"log/slog"
+)
 
 type slogAdapter struct {
 	logger *slog.Logger
 }
 
 // NewSlogLogger wraps a standard library slog.Logger to implement the modkit Logger interface.
-// Returns a NopLogger if the provided logger is nil.
+// Returns a NopLogger if the provided logger is nil. The result also implements ContextLogger.
 func NewSlogLogger(logger *slog.Logger) Logger {
 	if logger == nil {
 		return NewNopLogger()
@@ -15,6 +18,31 @@ func NewSlogLogger(logger *slog.Logger) Logger {
 	return slogAdapter{logger: logger}
 }
 
+// Options configures NewSlogLoggerWithOptions.
+type Options struct {
+	// Level drops records below it and, when set, replaces the handler's own
+	// minimum level. Keep the *slog.LevelVar to change the level at runtime, for
+	// example with the HTTP LogLevelHandler. Nil leaves filtering to the handler.
+	Level *slog.LevelVar
+	// RedactKeys lists attribute names, compared case-insensitively and also
+	// inside groups, whose values are replaced with Redacted.
+	RedactKeys []string
+	// RedactValues lists values, such as resolved secrets, replaced with
+	// Redacted wherever they appear in the message or in string, error, and
+	// fmt.Stringer attribute values.
+	RedactValues []string
+}
+
+// NewSlogLoggerWithOptions wraps logger with level filtering and redaction by
+// attribute name and by value.
+// Secret values are redacted whatever the options.
+func NewSlogLoggerWithOptions(logger *slog.Logger, opts Options) ContextLogger {
+	if logger == nil {
+		return nopLogger{}
+	}
+	return slogAdapter{logger: slog.New(newFilterHandler(logger.Handler(), opts))}
+}
+
 func (s slogAdapter) Debug(msg string, args ...any) {
 	s.logger.Debug(msg, args...)
 }
@@ -34,3 +62,23 @@ func (s slogAdapter) Error(msg string, args ...any) {
 func (s slogAdapter) With(args ...any) Logger {
 	return slogAdapter{logger: s.logger.With(args...)}
 }
+
+func (s slogAdapter) DebugContext(ctx context.Context, msg string, args ...any) {
+	s.logger.DebugContext(ctx, msg, args...)
+}
+
+func (s slogAdapter) InfoContext(ctx context.Context, msg string, args ...any) {
+	s.logger.InfoContext(ctx, msg, args...)
+}
+
+func (s slogAdapter) WarnContext(ctx context.Context, msg string, args ...any) {
+	s.logger.WarnContext(ctx, msg, args...)
+}
+
+func (s slogAdapter) ErrorContext(ctx context.Context, msg string, args ...any) {
+	s.logger.ErrorContext(ctx, msg, args...)
+}
+
+func (s slogAdapter) Enabled(level Level) bool {
+	return s.logger.Enabled(context.Background(), level)
+}
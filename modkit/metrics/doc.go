@@ -0,0 +1,4 @@
+// Package metrics provides a dependency-free metrics registry with Prometheus
+// text exposition, HTTP middleware labelled by route pattern, a kernel observer
+// for provider build durations, and database connection pool gauges.
+package metrics
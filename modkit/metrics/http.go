@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+)
+
+// ContentType is the media type of the text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// UnmatchedRoute is the route label of requests that matched no route.
+const UnmatchedRoute = "unmatched"
+
+// Handler returns a handler serving the registry in the text exposition format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		_ = r.WriteText(w)
+	})
+}
+
+// HTTPMiddleware returns middleware recording http_requests_total,
+// http_request_duration_seconds, and http_requests_in_flight. Requests are
+// labelled by method and route pattern, so "/users/{id}" is one series however
+// many users are requested; requests matching no route use UnmatchedRoute.
+// Apply it to the root router so it sees every request.
+func HTTPMiddleware(reg *Registry) func(http.Handler) http.Handler {
+	requests := reg.Counter("http_requests_total", "HTTP requests by method, route, and status.", "method", "route", "status")
+	duration := reg.Histogram("http_request_duration_seconds", "HTTP request latency by method and route.", nil, "method", "route")
+	inFlight := reg.Gauge("http_requests_in_flight", "HTTP requests being served.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			r, pattern := mkhttp.CaptureRoute(r)
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			start := time.Now()
+			next.ServeHTTP(ww, r)
+			elapsed := time.Since(start).Seconds()
+
+			route := pattern()
+			if route == "" {
+				route = UnmatchedRoute
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			requests.Inc(r.Method, route, strconv.Itoa(status))
+			duration.Observe(elapsed, r.Method, route)
+		})
+	}
+}
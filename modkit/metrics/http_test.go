@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/tracing"
+)
+
+func TestHTTPMiddleware_LabelsByRoutePattern(t *testing.T) {
+	reg := NewRegistry()
+	mux := mkhttp.NewServeMux()
+	mux.Use(HTTPMiddleware(reg))
+	mux.Handle(http.MethodGet, "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	mux.Handle(http.MethodGet, "/health", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	for _, path := range []string{"/users/1", "/users/2", "/health", "/missing"} {
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	requests := reg.Counter("http_requests_total", "", "method", "route", "status")
+	if got := requests.Value(http.MethodGet, "/users/{id}", "202"); got != 2 {
+		t.Fatalf("expected 2 requests for /users/{id}, got %v", got)
+	}
+	if got := requests.Value(http.MethodGet, "/health", "200"); got != 1 {
+		t.Fatalf("expected 1 request for /health, got %v", got)
+	}
+	if got := requests.Value(http.MethodGet, UnmatchedRoute, "404"); got != 1 {
+		t.Fatalf("expected 1 unmatched request, got %v", got)
+	}
+	duration := reg.Histogram("http_request_duration_seconds", "", nil, "method", "route")
+	if got := duration.Count(http.MethodGet, "/users/{id}"); got != 2 {
+		t.Fatalf("expected 2 observations, got %d", got)
+	}
+	if got := reg.Gauge("http_requests_in_flight", "").Value(); got != 0 {
+		t.Fatalf("expected no requests in flight, got %v", got)
+	}
+}
+
+func TestHTTPMiddleware_WithTracingLabelsByRoutePattern(t *testing.T) {
+	backends := map[string]func() (http.Handler, mkhttp.Router){
+		"chi": func() (http.Handler, mkhttp.Router) {
+			mux := chi.NewRouter()
+			return mux, mkhttp.AsRouter(mux)
+		},
+		"servemux": func() (http.Handler, mkhttp.Router) {
+			mux := mkhttp.NewServeMux()
+			return mux, mux
+		},
+	}
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			reg := NewRegistry()
+			exporter := tracing.NewInMemoryExporter()
+			mux, router := newBackend()
+			router.Use(HTTPMiddleware(reg))
+			router.Use(mkhttp.Tracing(mkhttp.TracingConfig{Tracer: tracing.NewTracer(exporter)}))
+			router.Handle(http.MethodGet, "/users/{id}", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+
+			requests := reg.Counter("http_requests_total", "", "method", "route", "status")
+			if got := requests.Value(http.MethodGet, "/users/{id}", "200"); got != 1 {
+				t.Fatalf("expected 1 request for /users/{id}, got %v", got)
+			}
+			spans := exporter.Spans()
+			if len(spans) != 1 || spans[0].Attributes["http.route"] != "/users/{id}" {
+				t.Fatalf("expected span for /users/{id}, got %+v", spans)
+			}
+		})
+	}
+}
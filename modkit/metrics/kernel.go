@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"github.com/go-modkit/modkit/modkit/kernel"
+)
+
+// KernelObserver returns a kernel.Observer recording
+// modkit_provider_build_duration_seconds and modkit_provider_build_errors_total
+// by module and token. Pass it to kernel.BootstrapWithOptions with
+// kernel.WithObservers.
+func KernelObserver(reg *Registry) kernel.Observer {
+	return &kernelObserver{
+		duration: reg.Histogram("modkit_provider_build_duration_seconds", "Provider build duration, including dependencies built on first use.", nil, "module", "token"),
+		errors:   reg.Counter("modkit_provider_build_errors_total", "Failed provider builds.", "module", "token"),
+	}
+}
+
+type kernelObserver struct {
+	duration *Histogram
+	errors   *Counter
+}
+
+func (o *kernelObserver) ProviderBuilt(event kernel.ProviderBuildEvent) {
+	token := string(event.Token)
+	o.duration.Observe(event.Duration.Seconds(), event.Module, token)
+	if event.Err != nil {
+		o.errors.Inc(event.Module, token)
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+type providersModule struct{}
+
+func (*providersModule) Definition() module.ModuleDef {
+	return module.ModuleDef{
+		Name: "app",
+		Providers: []module.ProviderDef{
+			{Token: "app.ok", Build: func(module.Resolver) (any, error) { return 1, nil }},
+			{Token: "app.fail", Build: func(module.Resolver) (any, error) { return nil, errors.New("boom") }},
+		},
+		Exports: []module.Token{"app.ok", "app.fail"},
+	}
+}
+
+func TestKernelObserver_RecordsProviderBuilds(t *testing.T) {
+	reg := NewRegistry()
+	app, err := kernel.BootstrapWithOptions(&providersModule{}, kernel.WithObservers(KernelObserver(reg)))
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if _, err := app.Get("app.ok"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if _, err := app.Get("app.fail"); err == nil {
+		t.Fatalf("expected build error")
+	}
+
+	duration := reg.Histogram("modkit_provider_build_duration_seconds", "", nil, "module", "token")
+	if got := duration.Count("app", "app.ok"); got != 1 {
+		t.Fatalf("expected 1 build observation, got %d", got)
+	}
+	buildErrors := reg.Counter("modkit_provider_build_errors_total", "", "module", "token")
+	if got := buildErrors.Value("app", "app.fail"); got != 1 {
+		t.Fatalf("expected 1 build error, got %v", got)
+	}
+	if got := buildErrors.Value("app", "app.ok"); got != 0 {
+		t.Fatalf("expected no error for app.ok, got %v", got)
+	}
+}
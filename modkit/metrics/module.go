@@ -0,0 +1,143 @@
+package metrics
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlmodule"
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+const (
+	// TokenRegistry resolves the module's *Registry.
+	TokenRegistry module.Token = "metrics.registry"
+	// TokenHTTPMiddleware resolves HTTPMiddleware for the registry, for use in
+	// ModuleDef.HTTP.Middleware or with Router.Use.
+	TokenHTTPMiddleware module.Token = "metrics.http_middleware"
+)
+
+// DefaultPath is where the metrics controller serves the registry when
+// Options.Path is empty.
+const DefaultPath = "/metrics"
+
+const moduleName = "metrics"
+
+// Database selects a SQL data module whose connection pool is exported.
+type Database struct {
+	// Name is the data module's instance name (see sqlmodule.NamedTokens);
+	// empty for the default database.db token. It is also the db label, or
+	// DefaultDBName when empty.
+	Name string
+	// Module is the data module exporting the handle, for example
+	// postgres.NewModule(postgres.Options{}). It is imported by the metrics
+	// module.
+	Module module.Module
+}
+
+// Options configures the metrics module.
+type Options struct {
+	// Registry holds the metrics; a new one is created when nil. Share it with
+	// KernelObserver to include provider build metrics.
+	Registry *Registry
+	// Path is where the registry is served (default DefaultPath).
+	Path string
+	// Databases lists the SQL data modules whose pool statistics are exported.
+	Databases []Database
+}
+
+// Module provides a metrics registry, HTTP middleware, and a controller serving
+// the text exposition.
+type Module struct {
+	opts Options
+}
+
+// NewModule constructs a metrics module.
+func NewModule(opts Options) module.Module {
+	if opts.Registry == nil {
+		opts.Registry = NewRegistry()
+	}
+	if opts.Path == "" {
+		opts.Path = DefaultPath
+	}
+	return &Module{opts: opts}
+}
+
+// Definition returns the module definition for graph construction.
+func (m *Module) Definition() module.ModuleDef {
+	reg := m.opts.Registry
+	imports := make([]module.Module, 0, len(m.opts.Databases))
+	for _, db := range m.opts.Databases {
+		if db.Module != nil {
+			imports = append(imports, db.Module)
+		}
+	}
+
+	return module.ModuleDef{
+		Name:    moduleName,
+		Imports: imports,
+		Providers: []module.ProviderDef{
+			{
+				Token: TokenRegistry,
+				Build: func(r module.Resolver) (any, error) {
+					for _, db := range m.opts.Databases {
+						if err := registerDB(r, reg, db.Name); err != nil {
+							return nil, err
+						}
+					}
+					return reg, nil
+				},
+			},
+			{
+				Token: TokenHTTPMiddleware,
+				Build: func(r module.Resolver) (any, error) {
+					reg, err := module.Get[*Registry](r, TokenRegistry)
+					if err != nil {
+						return nil, err
+					}
+					return HTTPMiddleware(reg), nil
+				},
+			},
+		},
+		Controllers: []module.ControllerDef{{
+			Name: "MetricsController",
+			Build: func(r module.Resolver) (any, error) {
+				reg, err := module.Get[*Registry](r, TokenRegistry)
+				if err != nil {
+					return nil, err
+				}
+				return &Controller{registry: reg, path: m.opts.Path}, nil
+			},
+		}},
+		Exports: []module.Token{TokenRegistry, TokenHTTPMiddleware},
+	}
+}
+
+func registerDB(r module.Resolver, reg *Registry, name string) error {
+	toks, err := sqlmodule.NamedTokens(name)
+	if err != nil {
+		return err
+	}
+	db, err := module.Get[*sql.DB](r, toks.DB)
+	if err != nil {
+		return fmt.Errorf("metrics: database %q: %w", name, err)
+	}
+	if name == "" {
+		name = DefaultDBName
+	}
+	DBStats(reg, name, db)
+	return nil
+}
+
+// Controller serves the registry. The route is hidden from generated API
+// documents; add guards to the router group if it must not be public.
+type Controller struct {
+	registry *Registry
+	path     string
+}
+
+// RegisterRoutes registers GET on the configured path.
+func (c *Controller) RegisterRoutes(router mkhttp.Router) {
+	router.Handle(http.MethodGet, c.path, mkhttp.Route(c.registry.Handler(), mkhttp.Hidden()))
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-modkit/modkit/modkit/data/sqlmodule"
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/kernel"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+type stubConnector struct{}
+
+func (stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn{}, nil }
+func (stubConnector) Driver() driver.Driver                        { return stubDriver{} }
+
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+type stubConn struct{}
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not implemented") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("not implemented") }
+
+type dbModule struct {
+	token module.Token
+	db    *sql.DB
+}
+
+func (m *dbModule) Definition() module.ModuleDef {
+	return module.ModuleDef{
+		Name: "data." + string(m.token),
+		Providers: []module.ProviderDef{{
+			Token: m.token,
+			Build: func(module.Resolver) (any, error) { return m.db, nil },
+		}},
+		Exports: []module.Token{m.token},
+	}
+}
+
+type rootModule struct {
+	imports []module.Module
+}
+
+func (m *rootModule) Definition() module.ModuleDef {
+	return module.ModuleDef{Name: "root", Imports: m.imports}
+}
+
+func TestModule_ServesMetricsWithDBStats(t *testing.T) {
+	db := sql.OpenDB(stubConnector{})
+	defer db.Close()
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Conn failed: %v", err)
+	}
+	defer conn.Close()
+
+	analytics, err := sqlmodule.NamedTokens("analytics")
+	if err != nil {
+		t.Fatalf("NamedTokens failed: %v", err)
+	}
+	reg := NewRegistry()
+	metricsModule := NewModule(Options{
+		Registry: reg,
+		Databases: []Database{
+			{Module: &dbModule{token: sqlmodule.TokenDB, db: db}},
+			{Name: "analytics", Module: &dbModule{token: analytics.DB, db: db}},
+		},
+	})
+
+	app, err := kernel.BootstrapWithOptions(&rootModule{imports: []module.Module{metricsModule}}, kernel.WithObservers(KernelObserver(reg)))
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	router := mkhttp.NewServeMux()
+	middleware, err := module.Get[func(http.Handler) http.Handler](app, TokenHTTPMiddleware)
+	if err != nil {
+		t.Fatalf("resolve middleware: %v", err)
+	}
+	router.Use(middleware)
+	if err := mkhttp.RegisterRoutes(router, app.Controllers); err != nil {
+		t.Fatalf("RegisterRoutes failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DefaultPath, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`db_pool_in_use_connections{db="analytics"} 1`,
+		`db_pool_in_use_connections{db="default"} 1`,
+		`db_pool_open_connections{db="default"} 1`,
+		`modkit_provider_build_duration_seconds_count{module="metrics",token="metrics.registry"} 1`,
+		`http_requests_in_flight 1`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Fatalf("expected %q in exposition:\n%s", want, body)
+		}
+	}
+	for _, route := range mkhttp.Routes(router) {
+		if route.Pattern == DefaultPath && !route.Meta.Hidden {
+			t.Fatalf("expected hidden metrics route")
+		}
+	}
+}
+
+func TestModule_MissingDatabaseFailsBuild(t *testing.T) {
+	app, err := kernel.Bootstrap(&rootModule{imports: []module.Module{
+		NewModule(Options{Databases: []Database{{Name: "missing"}}}),
+	}})
+	if err == nil {
+		_, err = app.Get(TokenRegistry)
+	}
+	var notVisible *kernel.TokenNotVisibleError
+	if !errors.As(err, &notVisible) || notVisible.Token != "database.missing.db" {
+		t.Fatalf("expected TokenNotVisibleError for the missing database, got %v", err)
+	}
+}
@@ -0,0 +1,467 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram upper bounds, in seconds, used when none are
+// given. They suit request and build latencies.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Labels are constant label pairs of a function-backed metric.
+type Labels map[string]string
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Registry holds metrics and writes them in the Prometheus text exposition
+// format. Declaring a metric again with the same name, type, and label names
+// returns the existing one; any other redeclaration panics, as do invalid names
+// and label value counts that do not match the declaration.
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+// family is a named metric with its samples.
+type family interface {
+	kind() metricType
+	help() string
+	labelNames() []string
+	write(w *exposition)
+}
+
+type desc struct {
+	name     string
+	typ      metricType
+	helpText string
+	labels   []string
+}
+
+func (d *desc) kind() metricType     { return d.typ }
+func (d *desc) help() string         { return d.helpText }
+func (d *desc) labelNames() []string { return d.labels }
+
+// register returns the family registered under name, creating it with create.
+func (r *Registry) register(name string, typ metricType, labels []string, create func(desc) family) family {
+	if !metricNameRE.MatchString(name) {
+		panic(fmt.Sprintf("metrics: invalid metric name %q", name))
+	}
+	for _, label := range labels {
+		if !labelNameRE.MatchString(label) || strings.HasPrefix(label, "__") {
+			panic(fmt.Sprintf("metrics: invalid label name %q for %s", label, name))
+		}
+		if typ == typeHistogram && label == "le" {
+			panic(fmt.Sprintf("metrics: label \"le\" is reserved for histogram %s", name))
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.families[name]; ok {
+		if existing.kind() != typ || !slices.Equal(existing.labelNames(), labels) {
+			panic(fmt.Sprintf("metrics: %s already registered as %s with labels %v", name, existing.kind(), existing.labelNames()))
+		}
+		return existing
+	}
+	created := create(desc{name: name, typ: typ, labels: slices.Clone(labels)})
+	r.families[name] = created
+	return created
+}
+
+// Counter declares a counter with the given label names.
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	f := r.register(name, typeCounter, labelNames, func(d desc) family {
+		d.helpText = help
+		return &Counter{vec: newVec(d)}
+	})
+	counter, ok := f.(*Counter)
+	if !ok {
+		panic(fmt.Sprintf("metrics: %s already registered as a function", name))
+	}
+	return counter
+}
+
+// Gauge declares a gauge with the given label names.
+func (r *Registry) Gauge(name, help string, labelNames ...string) *Gauge {
+	f := r.register(name, typeGauge, labelNames, func(d desc) family {
+		d.helpText = help
+		return &Gauge{vec: newVec(d)}
+	})
+	gauge, ok := f.(*Gauge)
+	if !ok {
+		panic(fmt.Sprintf("metrics: %s already registered as a function", name))
+	}
+	return gauge
+}
+
+// Histogram declares a histogram with the given upper bounds (DefaultBuckets
+// when nil) and label names.
+func (r *Registry) Histogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = slices.Clone(buckets)
+	sort.Float64s(buckets)
+	if n := len(buckets); n > 0 && math.IsInf(buckets[n-1], 1) {
+		buckets = buckets[:n-1]
+	}
+	f := r.register(name, typeHistogram, labelNames, func(d desc) family {
+		d.helpText = help
+		return &Histogram{vec: newVec(d), buckets: buckets}
+	})
+	return f.(*Histogram)
+}
+
+// GaugeFunc registers fn as a gauge sample with constant labels, read on every
+// scrape. Registering the same name and labels again replaces fn.
+func (r *Registry) GaugeFunc(name, help string, labels Labels, fn func() float64) {
+	r.registerFunc(name, help, typeGauge, labels, fn)
+}
+
+// CounterFunc registers fn as a counter sample with constant labels, read on
+// every scrape. fn must not decrease.
+func (r *Registry) CounterFunc(name, help string, labels Labels, fn func() float64) {
+	r.registerFunc(name, help, typeCounter, labels, fn)
+}
+
+func (r *Registry) registerFunc(name, help string, typ metricType, labels Labels, fn func() float64) {
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+	f := r.register(name, typ, names, func(d desc) family {
+		d.helpText = help
+		return &funcFamily{desc: d, samples: make(map[string]funcSample)}
+	})
+	funcs, ok := f.(*funcFamily)
+	if !ok {
+		panic(fmt.Sprintf("metrics: %s already registered with a different kind", name))
+	}
+	values := make([]string, len(names))
+	for i, label := range names {
+		values[i] = labels[label]
+	}
+	funcs.set(values, fn)
+}
+
+// WriteText writes every metric in the Prometheus text exposition format,
+// sorted by name and labels.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.Unlock()
+
+	out := &exposition{}
+	for i, f := range families {
+		out.family(names[i], f)
+		f.write(out)
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// vec stores the series of a metric by label values.
+type vec struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labels []string
+	value  float64
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func newVec(d desc) *vec {
+	return &vec{desc: d, series: make(map[string]*series)}
+}
+
+// with returns the series of labelValues, creating it; the caller holds v.mu.
+func (v *vec) with(labelValues []string) *series {
+	s, ok := v.lookup(labelValues)
+	if !ok {
+		key := strings.Join(labelValues, "\xff")
+		s = &series{labels: slices.Clone(labelValues)}
+		v.series[key] = s
+	}
+	return s
+}
+
+// lookup returns the series of labelValues if it exists; the caller holds v.mu.
+func (v *vec) lookup(labelValues []string) (*series, bool) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	s, ok := v.series[strings.Join(labelValues, "\xff")]
+	return s, ok
+}
+
+// sorted returns the series ordered by label values; the caller holds v.mu.
+func (v *vec) sorted() []*series {
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	out := make([]*series, len(keys))
+	for i, key := range keys {
+		out[i] = v.series[key]
+	}
+	return out
+}
+
+// Counter is a monotonically increasing value per label set.
+type Counter struct {
+	*vec
+}
+
+// Inc adds one to the series of labelValues.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the series of labelValues.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.name))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.with(labelValues).value += delta
+}
+
+// Value returns the current value of the series of labelValues.
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.lookup(labelValues); ok {
+		return s.value
+	}
+	return 0
+}
+
+func (c *Counter) write(w *exposition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range c.sorted() {
+		w.sample(c.name, c.labels, s.labels, "", "", s.value)
+	}
+}
+
+// Gauge is a value per label set that can go up and down.
+type Gauge struct {
+	*vec
+}
+
+// Set sets the series of labelValues to value.
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.with(labelValues).value = value
+}
+
+// Add adds delta to the series of labelValues.
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.with(labelValues).value += delta
+}
+
+// Inc adds one to the series of labelValues.
+func (g *Gauge) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts one from the series of labelValues.
+func (g *Gauge) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Value returns the current value of the series of labelValues.
+func (g *Gauge) Value(labelValues ...string) float64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if s, ok := g.lookup(labelValues); ok {
+		return s.value
+	}
+	return 0
+}
+
+func (g *Gauge) write(w *exposition) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, s := range g.sorted() {
+		w.sample(g.name, g.labels, s.labels, "", "", s.value)
+	}
+}
+
+// Histogram counts observations in cumulative buckets per label set.
+type Histogram struct {
+	*vec
+	buckets []float64
+}
+
+// Observe records value in the series of labelValues.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.with(labelValues)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(h.buckets))
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += value
+}
+
+// Count returns the number of observations in the series of labelValues.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.lookup(labelValues); ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) write(w *exposition) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			if s.counts != nil {
+				cumulative += s.counts[i]
+			}
+			w.sample(h.name+"_bucket", h.labels, s.labels, "le", formatFloat(bound), float64(cumulative))
+		}
+		w.sample(h.name+"_bucket", h.labels, s.labels, "le", "+Inf", float64(s.count))
+		w.sample(h.name+"_sum", h.labels, s.labels, "", "", s.sum)
+		w.sample(h.name+"_count", h.labels, s.labels, "", "", float64(s.count))
+	}
+}
+
+// funcFamily holds function-backed samples with constant labels.
+type funcFamily struct {
+	desc
+	mu      sync.Mutex
+	samples map[string]funcSample
+}
+
+type funcSample struct {
+	labels []string
+	fn     func() float64
+}
+
+func (f *funcFamily) set(labelValues []string, fn func() float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.samples[strings.Join(labelValues, "\xff")] = funcSample{labels: labelValues, fn: fn}
+}
+
+func (f *funcFamily) write(w *exposition) {
+	f.mu.Lock()
+	keys := make([]string, 0, len(f.samples))
+	for key := range f.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := make([]funcSample, len(keys))
+	for i, key := range keys {
+		samples[i] = f.samples[key]
+	}
+	f.mu.Unlock()
+
+	for _, s := range samples {
+		w.sample(f.name, f.labels, s.labels, "", "", s.fn())
+	}
+}
+
+// exposition builds the text format.
+type exposition struct {
+	strings.Builder
+}
+
+func (w *exposition) family(name string, f family) {
+	w.WriteString("# HELP " + name + " " + escapeHelp(f.help()) + "\n")
+	w.WriteString("# TYPE " + name + " " + string(f.kind()) + "\n")
+}
+
+func (w *exposition) sample(name string, labelNames, labelValues []string, extraName, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, label := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(label + `="` + escapeLabel(labelValues[i]) + `"`)
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.Counter("app_requests_total", "Requests.\nSecond line.", "path")
+	requests.Inc("/b")
+	requests.Add(2, `/a"quoted"`)
+	reg.Gauge("app_temperature", "Temperature.").Set(-1.5)
+	latency := reg.Histogram("app_latency_seconds", "Latency.", []float64{0.5, 0.1}, "op")
+	latency.Observe(0.05, "read")
+	latency.Observe(0.3, "read")
+	latency.Observe(2, "read")
+	reg.GaugeFunc("app_up", "Up.", Labels{"zone": "b"}, func() float64 { return 1 })
+	reg.GaugeFunc("app_up", "Up.", Labels{"zone": "a"}, func() float64 { return 0 })
+
+	var out strings.Builder
+	if err := reg.WriteText(&out); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+
+	want := `# HELP app_latency_seconds Latency.
+# TYPE app_latency_seconds histogram
+app_latency_seconds_bucket{op="read",le="0.1"} 1
+app_latency_seconds_bucket{op="read",le="0.5"} 2
+app_latency_seconds_bucket{op="read",le="+Inf"} 3
+app_latency_seconds_sum{op="read"} 2.35
+app_latency_seconds_count{op="read"} 3
+# HELP app_requests_total Requests.\nSecond line.
+# TYPE app_requests_total counter
+app_requests_total{path="/a\"quoted\""} 2
+app_requests_total{path="/b"} 1
+# HELP app_temperature Temperature.
+# TYPE app_temperature gauge
+app_temperature -1.5
+# HELP app_up Up.
+# TYPE app_up gauge
+app_up{zone="a"} 0
+app_up{zone="b"} 1
+`
+	if out.String() != want {
+		t.Fatalf("unexpected exposition:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_RedeclareReturnsExisting(t *testing.T) {
+	reg := NewRegistry()
+	first := reg.Counter("jobs_total", "Jobs.", "kind")
+	first.Inc("a")
+	second := reg.Counter("jobs_total", "Jobs.", "kind")
+	if second != first || second.Value("a") != 1 {
+		t.Fatalf("expected the existing counter")
+	}
+	if second.Value("missing") != 0 {
+		t.Fatalf("expected zero for a missing series")
+	}
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	tests := map[string]func(reg *Registry){
+		"invalid name":        func(reg *Registry) { reg.Counter("bad-name", "") },
+		"invalid label":       func(reg *Registry) { reg.Counter("ok_total", "", "bad-label") },
+		"reserved le":         func(reg *Registry) { reg.Histogram("h", "", nil, "le") },
+		"conflicting type":    func(reg *Registry) { reg.Counter("x", ""); reg.Gauge("x", "") },
+		"conflicting labels":  func(reg *Registry) { reg.Counter("y", "", "a"); reg.Counter("y", "", "b") },
+		"label count":         func(reg *Registry) { reg.Counter("z", "", "a").Inc() },
+		"negative counter":    func(reg *Registry) { reg.Counter("n", "").Add(-1) },
+		"function vs counter": func(reg *Registry) { reg.CounterFunc("f", "", nil, nil); reg.Counter("f", "") },
+	}
+	for name, fn := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("expected panic")
+				}
+			}()
+			fn(NewRegistry())
+		})
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("hits_total", "Hits.").Inc()
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != ContentType {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	if !strings.Contains(rec.Body.String(), "hits_total 1\n") {
+		t.Fatalf("unexpected body %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"database/sql"
+)
+
+// DefaultDBName is the db label of the unnamed SQL data module.
+const DefaultDBName = "default"
+
+// DBStats registers gauges and counters read from db.Stats() on every scrape,
+// labelled db=name. Registering the same name again replaces the previous
+// handle.
+func DBStats(reg *Registry, name string, db *sql.DB) {
+	labels := Labels{"db": name}
+	gauge := func(metric, help string, value func(sql.DBStats) float64) {
+		reg.GaugeFunc(metric, help, labels, func() float64 { return value(db.Stats()) })
+	}
+	counter := func(metric, help string, value func(sql.DBStats) float64) {
+		reg.CounterFunc(metric, help, labels, func() float64 { return value(db.Stats()) })
+	}
+
+	gauge("db_pool_max_open_connections", "Maximum number of open connections.",
+		func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) })
+	gauge("db_pool_open_connections", "Open connections, in use and idle.",
+		func(s sql.DBStats) float64 { return float64(s.OpenConnections) })
+	gauge("db_pool_in_use_connections", "Connections in use.",
+		func(s sql.DBStats) float64 { return float64(s.InUse) })
+	gauge("db_pool_idle_connections", "Idle connections.",
+		func(s sql.DBStats) float64 { return float64(s.Idle) })
+	counter("db_pool_wait_count_total", "Connections waited for.",
+		func(s sql.DBStats) float64 { return float64(s.WaitCount) })
+	counter("db_pool_wait_duration_seconds_total", "Time blocked waiting for connections.",
+		func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() })
+	counter("db_pool_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) })
+	counter("db_pool_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) })
+	counter("db_pool_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.",
+		func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) })
+}
@@ -0,0 +1,10 @@
+package module
+
+import "context"
+
+// HealthChecker is implemented by controllers that report whether the app can
+// serve traffic, for example by pinging a database. CheckHealth should return
+// quickly and honor ctx; a non-nil error marks the app unhealthy.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
@@ -11,6 +11,20 @@ type ModuleDef struct {
 	Providers   []ProviderDef
 	Controllers []ControllerDef
 	Exports     []Token
+	// HTTP configures how the HTTP adapter mounts the module's controllers.
+	HTTP HTTPDef
+}
+
+// HTTPDef declares a route prefix and middleware for a module. Both apply to
+// the module's controllers and, nested, to the controllers of the modules it
+// imports.
+type HTTPDef struct {
+	// Prefix is prepended to every route, for example "/users".
+	Prefix string
+	// Middleware lists tokens of providers returning
+	// func(http.Handler) http.Handler. They are resolved from this module and
+	// applied in order, outside the middleware of imported modules.
+	Middleware []Token
 }
 
 // Module provides its definition for graph construction.
@@ -0,0 +1,23 @@
+package module
+
+import "context"
+
+// Runnable is implemented by controllers that run until their context is canceled,
+// such as queue consumers, schedulers, or servers. Run should return nil (or the
+// context's error) once ctx is canceled.
+type Runnable interface {
+	Run(ctx context.Context) error
+}
+
+// CommandFunc runs a CLI command with the arguments that follow its name.
+type CommandFunc func(ctx context.Context, args []string) error
+
+// CommandRegistry collects CLI commands contributed by controllers.
+type CommandRegistry interface {
+	Command(name, summary string, run CommandFunc)
+}
+
+// CommandRegistrar is implemented by controllers that contribute CLI commands.
+type CommandRegistrar interface {
+	RegisterCommands(registry CommandRegistry)
+}
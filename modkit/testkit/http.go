@@ -0,0 +1,286 @@
+package testkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/module"
+)
+
+// HTTPHarness serves the controllers of a bootstrapped app for HTTP tests.
+type HTTPHarness struct {
+	*Harness
+	tb      TB
+	handler http.Handler
+	routes  mkhttp.Router
+	server  *httptest.Server
+}
+
+// NewHTTP bootstraps root like New, registers every controller with
+// mkhttp.RegisterApp on an mkhttp.NewRouter, and applies WithMiddleware
+// middleware to all routes. Requests go straight to the handler unless
+// WithListener is set, in which case they go through a real listener on
+// 127.0.0.1. The listener and app are closed when the test ends.
+func NewHTTP(tb TB, root module.Module, opts ...Option) *HTTPHarness {
+	tb.Helper()
+
+	h, err := NewE(tb, root, opts...)
+	if err != nil {
+		tb.Fatalf("testkit.NewHTTP bootstrap failed: %v", err)
+		return nil
+	}
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt.apply(&cfg)
+	}
+
+	router := mkhttp.NewRouter()
+	for _, mw := range cfg.middleware {
+		router.Use(mw)
+	}
+	routes := mkhttp.AsRouter(router)
+	if err := mkhttp.RegisterApp(routes, h.App()); err != nil {
+		tb.Fatalf("testkit.NewHTTP route registration failed: %v", err)
+		return nil
+	}
+
+	hh := &HTTPHarness{Harness: h, tb: tb, handler: router, routes: routes}
+	if cfg.listen {
+		hh.server = httptest.NewServer(router)
+		tb.Cleanup(hh.server.Close)
+	}
+	return hh
+}
+
+// Handler returns the router serving the controllers.
+func (h *HTTPHarness) Handler() http.Handler {
+	return h.handler
+}
+
+// Routes returns the routes registered by the controllers.
+func (h *HTTPHarness) Routes() []mkhttp.RouteInfo {
+	return mkhttp.Routes(h.routes)
+}
+
+// URL returns the base URL of the listener, or "" without WithListener.
+func (h *HTTPHarness) URL() string {
+	if h.server == nil {
+		return ""
+	}
+	return h.server.URL
+}
+
+// Request starts building a request to path, which may include a query.
+func (h *HTTPHarness) Request(method, path string) *Request {
+	return &Request{h: h, method: method, path: path, header: http.Header{}, query: url.Values{}}
+}
+
+// Get starts building a GET request.
+func (h *HTTPHarness) Get(path string) *Request { return h.Request(http.MethodGet, path) }
+
+// Post starts building a POST request.
+func (h *HTTPHarness) Post(path string) *Request { return h.Request(http.MethodPost, path) }
+
+// Put starts building a PUT request.
+func (h *HTTPHarness) Put(path string) *Request { return h.Request(http.MethodPut, path) }
+
+// Patch starts building a PATCH request.
+func (h *HTTPHarness) Patch(path string) *Request { return h.Request(http.MethodPatch, path) }
+
+// Delete starts building a DELETE request.
+func (h *HTTPHarness) Delete(path string) *Request { return h.Request(http.MethodDelete, path) }
+
+// Request is an HTTP request under construction. Send it with Do.
+type Request struct {
+	h      *HTTPHarness
+	ctx    context.Context
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   []byte
+	err    error
+}
+
+// WithContext sets the context of the request.
+func (r *Request) WithContext(ctx context.Context) *Request {
+	r.ctx = ctx
+	return r
+}
+
+// Header adds a request header.
+func (r *Request) Header(name, value string) *Request {
+	r.header.Add(name, value)
+	return r
+}
+
+// Query adds a query parameter.
+func (r *Request) Query(name, value string) *Request {
+	r.query.Add(name, value)
+	return r
+}
+
+// Body sets the request body and its Content-Type.
+func (r *Request) Body(contentType string, body []byte) *Request {
+	r.header.Set("Content-Type", contentType)
+	r.body = body
+	return r
+}
+
+// JSON sets the request body to v encoded as JSON.
+func (r *Request) JSON(v any) *Request {
+	body, err := json.Marshal(v)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	return r.Body("application/json", body)
+}
+
+// Do sends the request and returns the buffered response. Failures to build or
+// send the request fail the test.
+func (r *Request) Do() *Response {
+	tb := r.h.tb
+	tb.Helper()
+
+	if r.err != nil {
+		tb.Fatalf("testkit.HTTP %s %s: encode body: %v", r.method, r.path, r.err)
+		return nil
+	}
+	target, err := url.Parse(r.path)
+	if err != nil {
+		tb.Fatalf("testkit.HTTP %s %s: parse path: %v", r.method, r.path, err)
+		return nil
+	}
+	if len(r.query) > 0 {
+		query := target.Query()
+		for name, values := range r.query {
+			query[name] = append(query[name], values...)
+		}
+		target.RawQuery = query.Encode()
+	}
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if r.h.server == nil {
+		req := httptest.NewRequest(r.method, target.String(), bytes.NewReader(r.body)).WithContext(ctx)
+		req.Header = r.header.Clone()
+		rec := httptest.NewRecorder()
+		r.h.handler.ServeHTTP(rec, req)
+		return newResponse(tb, r, rec.Result())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, r.h.server.URL+target.String(), bytes.NewReader(r.body))
+	if err != nil {
+		tb.Fatalf("testkit.HTTP %s %s: build request: %v", r.method, r.path, err)
+		return nil
+	}
+	req.Header = r.header.Clone()
+	resp, err := r.h.server.Client().Do(req)
+	if err != nil {
+		tb.Fatalf("testkit.HTTP %s %s: %v", r.method, r.path, err)
+		return nil
+	}
+	return newResponse(tb, r, resp)
+}
+
+// Response is a buffered HTTP response with assertion helpers. Failed
+// assertions fail the test.
+type Response struct {
+	tb         TB
+	request    string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+func newResponse(tb TB, r *Request, resp *http.Response) *Response {
+	tb.Helper()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("testkit.HTTP %s %s: read body: %v", r.method, r.path, err)
+	}
+	return &Response{
+		tb:         tb,
+		request:    r.method + " " + r.path,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	}
+}
+
+// ExpectStatus asserts the response status.
+func (r *Response) ExpectStatus(status int) *Response {
+	r.tb.Helper()
+	if r.StatusCode != status {
+		r.tb.Fatalf("testkit.HTTP %s: status = %d, want %d; body: %s", r.request, r.StatusCode, status, r.Body)
+	}
+	return r
+}
+
+// ExpectHeader asserts the first value of a response header.
+func (r *Response) ExpectHeader(name, value string) *Response {
+	r.tb.Helper()
+	if got := r.Header.Get(name); got != value {
+		r.tb.Fatalf("testkit.HTTP %s: header %s = %q, want %q", r.request, name, got, value)
+	}
+	return r
+}
+
+// ExpectJSON asserts that the body is JSON equal to want once both are
+// decoded, so key order and whitespace do not matter. want may be a JSON
+// string, []byte, or json.RawMessage, or any value encoded with encoding/json.
+func (r *Response) ExpectJSON(want any) *Response {
+	r.tb.Helper()
+
+	var wantJSON []byte
+	switch w := want.(type) {
+	case string:
+		wantJSON = []byte(w)
+	case []byte:
+		wantJSON = w
+	case json.RawMessage:
+		wantJSON = w
+	default:
+		encoded, err := json.Marshal(want)
+		if err != nil {
+			r.tb.Fatalf("testkit.HTTP %s: encode expected JSON: %v", r.request, err)
+			return r
+		}
+		wantJSON = encoded
+	}
+
+	var gotValue, wantValue any
+	if err := json.Unmarshal(wantJSON, &wantValue); err != nil {
+		r.tb.Fatalf("testkit.HTTP %s: decode expected JSON: %v", r.request, err)
+		return r
+	}
+	if err := json.Unmarshal(r.Body, &gotValue); err != nil {
+		r.tb.Fatalf("testkit.HTTP %s: decode body: %v; body: %s", r.request, err, r.Body)
+		return r
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		r.tb.Fatalf("testkit.HTTP %s: body = %s, want %s", r.request, bytes.TrimSpace(r.Body), wantJSON)
+	}
+	return r
+}
+
+// DecodeJSON decodes the body into v.
+func (r *Response) DecodeJSON(v any) *Response {
+	r.tb.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.tb.Fatalf("testkit.HTTP %s: decode body: %v; body: %s", r.request, err, r.Body)
+	}
+	return r
+}
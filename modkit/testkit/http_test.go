@@ -0,0 +1,150 @@
+package testkit_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	mkhttp "github.com/go-modkit/modkit/modkit/http"
+	"github.com/go-modkit/modkit/modkit/module"
+	"github.com/go-modkit/modkit/modkit/testkit"
+)
+
+type echoRequest struct {
+	ID    string `path:"id" json:"-"`
+	Name  string `json:"name"`
+	Tag   string `query:"tag" json:"-"`
+	Trace string `header:"X-Trace" json:"-"`
+}
+
+type echoResponse struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Tag   string `json:"tag,omitempty"`
+	Trace string `json:"trace,omitempty"`
+}
+
+type echoController struct {
+	prefix string
+}
+
+func (c *echoController) RegisterRoutes(r mkhttp.Router) {
+	r.Handle(http.MethodPut, "/items/{id}", mkhttp.Handle(func(_ context.Context, req echoRequest) (echoResponse, error) {
+		return echoResponse{ID: req.ID, Name: c.prefix + req.Name, Tag: req.Tag, Trace: req.Trace}, nil
+	}))
+}
+
+const tokenPrefix module.Token = "echo.prefix"
+
+func echoModule() module.Module {
+	return &testModule{def: module.ModuleDef{
+		Name: "root",
+		Providers: []module.ProviderDef{{
+			Token: tokenPrefix,
+			Build: func(module.Resolver) (any, error) { return "real:", nil },
+		}},
+		Controllers: []module.ControllerDef{{
+			Name: "EchoController",
+			Build: func(r module.Resolver) (any, error) {
+				prefix, err := module.Get[string](r, tokenPrefix)
+				if err != nil {
+					return nil, err
+				}
+				return &echoController{prefix: prefix}, nil
+			},
+		}},
+	}}
+}
+
+func serverHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "testkit")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestNewHTTP_ServesControllers(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []testkit.Option
+	}{
+		{name: "in-process"},
+		{name: "listener", opts: []testkit.Option{testkit.WithListener()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := append([]testkit.Option{
+				testkit.WithOverrides(testkit.OverrideValue(tokenPrefix, "fake:")),
+				testkit.WithMiddleware(serverHeader),
+			}, tc.opts...)
+			h := testkit.NewHTTP(t, echoModule(), opts...)
+
+			if (h.URL() != "") != (tc.name == "listener") {
+				t.Fatalf("unexpected URL %q", h.URL())
+			}
+			if routes := h.Routes(); len(routes) != 1 || routes[0].Pattern != "/items/{id}" {
+				t.Fatalf("unexpected routes: %+v", routes)
+			}
+
+			var got echoResponse
+			h.Put("/items/7").
+				Query("tag", "new").
+				Header("X-Trace", "abc").
+				JSON(map[string]string{"name": "widget"}).
+				Do().
+				ExpectStatus(http.StatusOK).
+				ExpectHeader("X-Served-By", "testkit").
+				ExpectJSON(`{"trace": "abc", "tag": "new", "name": "fake:widget", "id": "7"}`).
+				DecodeJSON(&got)
+			if got.Name != "fake:widget" {
+				t.Fatalf("unexpected decoded response: %+v", got)
+			}
+
+			h.Get("/missing").Do().ExpectStatus(http.StatusNotFound)
+		})
+	}
+}
+
+func TestNewHTTP_ListenerClosedOnCleanup(t *testing.T) {
+	tb := &tbStub{}
+	h := testkit.NewHTTP(tb, echoModule(), testkit.WithListener())
+	url := h.URL()
+
+	for i := len(tb.cleanup) - 1; i >= 0; i-- {
+		tb.cleanup[i]()
+	}
+	if _, err := http.Get(url + "/items/1"); err == nil {
+		t.Fatalf("expected listener to be closed")
+	}
+}
+
+func TestResponse_FailedAssertionsFailTest(t *testing.T) {
+	tb := &tbStub{}
+	h := testkit.NewHTTP(tb, echoModule())
+	resp := h.Put("/items/1").JSON(map[string]string{"name": "a"}).Do()
+
+	resp.ExpectStatus(http.StatusCreated)
+	if !tb.failed || !strings.Contains(tb.msg, "status") {
+		t.Fatalf("expected status assertion failure, got %q", tb.msg)
+	}
+
+	tb.failed = false
+	resp.ExpectJSON(echoResponse{ID: "1", Name: "other"})
+	if !tb.failed || !strings.Contains(tb.msg, "want") {
+		t.Fatalf("expected JSON assertion failure, got %q", tb.msg)
+	}
+}
+
+func TestNewHTTP_BootstrapFailureFailsTest(t *testing.T) {
+	tb := &tbStub{}
+	root := &testModule{def: module.ModuleDef{
+		Name: "root",
+		Controllers: []module.ControllerDef{{
+			Name:  "Broken",
+			Build: func(r module.Resolver) (any, error) { return r.Get("missing") },
+		}},
+	}}
+	if h := testkit.NewHTTP(tb, root); h != nil || !tb.failed {
+		t.Fatalf("expected bootstrap failure to fail the test")
+	}
+}
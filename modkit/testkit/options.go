@@ -2,13 +2,16 @@ package testkit
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/go-modkit/modkit/modkit/module"
 )
 
 type config struct {
-	overrides []Override
-	autoClose bool
+	overrides  []Override
+	autoClose  bool
+	middleware []func(http.Handler) http.Handler
+	listen     bool
 }
 
 func defaultConfig() config {
@@ -67,3 +70,22 @@ func WithoutAutoClose() Option {
 		cfg.autoClose = false
 	})
 }
+
+// WithMiddleware applies middleware to every route served by NewHTTP, in
+// order, outermost first. New ignores it.
+func WithMiddleware(middleware ...func(http.Handler) http.Handler) Option {
+	cloned := make([]func(http.Handler) http.Handler, len(middleware))
+	copy(cloned, middleware)
+
+	return optionFunc(func(cfg *config) {
+		cfg.middleware = append(cfg.middleware, cloned...)
+	})
+}
+
+// WithListener makes NewHTTP serve requests through a real listener on
+// 127.0.0.1 instead of calling the handler in process. New ignores it.
+func WithListener() Option {
+	return optionFunc(func(cfg *config) {
+		cfg.listen = true
+	})
+}
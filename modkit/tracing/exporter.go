@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// Exporter receives sampled spans when they end. Export is called on the
+// goroutine ending the span, so implementations that do I/O should buffer.
+type Exporter interface {
+	Export(ctx context.Context, span SpanData) error
+}
+
+// ExporterFunc adapts a function to Exporter.
+type ExporterFunc func(ctx context.Context, span SpanData) error
+
+// Export calls f(ctx, span).
+func (f ExporterFunc) Export(ctx context.Context, span SpanData) error {
+	return f(ctx, span)
+}
+
+// InMemoryExporter records exported spans, for tests.
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter returns an empty InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// Export records span.
+func (e *InMemoryExporter) Export(_ context.Context, span SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+// Spans returns the recorded spans in the order they ended.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset discards the recorded spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
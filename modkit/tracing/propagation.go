@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	// TraceparentHeader carries the trace ID, parent span ID, and flags.
+	TraceparentHeader = "Traceparent"
+	// TracestateHeader carries vendor-specific trace state.
+	TracestateHeader = "Tracestate"
+)
+
+// maxTraceStateMembers is the tracestate list limit of the specification.
+const maxTraceStateMembers = 32
+
+// Extract reads the remote span context from traceparent and tracestate
+// headers. It reports false when traceparent is missing or invalid, in which
+// case tracestate is ignored too.
+func Extract(header http.Header) (SpanContext, bool) {
+	sc, err := ParseTraceparent(header.Get(TraceparentHeader))
+	if err != nil {
+		return SpanContext{}, false
+	}
+	sc.Remote = true
+	sc.TraceState = joinTraceState(header.Values(TracestateHeader))
+	return sc, true
+}
+
+// Inject writes the span context of ctx to header, for outgoing requests. It
+// does nothing when ctx has no span context.
+func Inject(ctx context.Context, header http.Header) {
+	sc, ok := SpanContextFromContext(ctx)
+	if !ok {
+		return
+	}
+	header.Set(TraceparentHeader, sc.Traceparent())
+	if sc.TraceState != "" {
+		header.Set(TracestateHeader, sc.TraceState)
+	} else {
+		header.Del(TracestateHeader)
+	}
+}
+
+// joinTraceState combines tracestate header lines, dropping empty members. A
+// list over the member limit is discarded.
+func joinTraceState(values []string) string {
+	var members []string
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			if member = strings.TrimSpace(member); member != "" {
+				if !strings.Contains(member, "=") {
+					return ""
+				}
+				members = append(members, member)
+			}
+		}
+	}
+	if len(members) > maxTraceStateMembers {
+		return ""
+	}
+	return strings.Join(members, ",")
+}
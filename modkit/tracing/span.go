@@ -0,0 +1,132 @@
+// Package tracing implements W3C Trace Context propagation and minimal spans
+// for modkit applications. Spans are handed to a pluggable Exporter when they
+// end; InMemoryExporter records them for tests.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+// String returns the ID as 32 lowercase hex digits.
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within a trace.
+type SpanID [8]byte
+
+// IsValid reports whether the ID is not all zeros.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+// String returns the ID as 16 lowercase hex digits.
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// FlagSampled is the trace flag set when the caller records the trace.
+const FlagSampled byte = 0x01
+
+// SpanContext is the propagated identity of a span.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Flags   byte
+	// TraceState is the vendor-specific tracestate header value, passed on
+	// unchanged.
+	TraceState string
+	// Remote is set for span contexts extracted from an incoming request.
+	Remote bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// IsSampled reports whether FlagSampled is set.
+func (sc SpanContext) IsSampled() bool { return sc.Flags&FlagSampled != 0 }
+
+// Traceparent formats the span context as a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + hex.EncodeToString([]byte{sc.Flags})
+}
+
+// ErrInvalidTraceparent is returned by ParseTraceparent for malformed values.
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent parses a traceparent header. Values of future versions are
+// accepted when their known prefix is valid, as the specification requires.
+func ParseTraceparent(value string) (SpanContext, error) {
+	value = strings.TrimSpace(value)
+	if len(value) < 55 || value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	version, ok := decodeHex(value[0:2])
+	if !ok || version[0] == 0xff || (version[0] == 0 && len(value) != 55) || (len(value) > 55 && value[55] != '-') {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	var sc SpanContext
+	traceID, ok := decodeHex(value[3:35])
+	if !ok {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	spanID, ok := decodeHex(value[36:52])
+	if !ok {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	flags, ok := decodeHex(value[53:55])
+	if !ok {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceparent
+	}
+	return sc, nil
+}
+
+// decodeHex decodes lowercase hex only, as traceparent requires.
+func decodeHex(s string) ([]byte, bool) {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return nil, false
+		}
+	}
+	b, err := hex.DecodeString(s)
+	return b, err == nil
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context of the current span.
+func SpanContextFromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const validTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent(validTraceparent)
+	if err != nil {
+		t.Fatalf("ParseTraceparent failed: %v", err)
+	}
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" || !sc.IsSampled() {
+		t.Fatalf("unexpected span context: %+v", sc)
+	}
+	if sc.Traceparent() != validTraceparent {
+		t.Fatalf("expected round trip, got %q", sc.Traceparent())
+	}
+
+	future, err := ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra")
+	if err != nil || future.IsSampled() {
+		t.Fatalf("expected future version to parse unsampled, got %+v %v", future, err)
+	}
+}
+
+func TestParseTraceparent_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		"00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473g-00f067aa0ba902b7-01",
+	} {
+		if _, err := ParseTraceparent(value); !errors.Is(err, ErrInvalidTraceparent) {
+			t.Fatalf("%q: expected ErrInvalidTraceparent, got %v", value, err)
+		}
+	}
+}
+
+func TestExtractInject(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, validTraceparent)
+	header.Add(TracestateHeader, "congo=t61rcWkgMzE")
+	header.Add(TracestateHeader, " rojo=00f067aa0ba902b7 ,")
+
+	sc, ok := Extract(header)
+	if !ok || !sc.Remote {
+		t.Fatalf("expected remote span context, got %+v", sc)
+	}
+	if sc.TraceState != "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7" {
+		t.Fatalf("unexpected tracestate %q", sc.TraceState)
+	}
+
+	out := http.Header{}
+	Inject(ContextWithSpanContext(context.Background(), sc), out)
+	if out.Get(TraceparentHeader) != validTraceparent || out.Get(TracestateHeader) != sc.TraceState {
+		t.Fatalf("unexpected injected headers: %v", out)
+	}
+
+	empty := http.Header{}
+	Inject(context.Background(), empty)
+	if len(empty) != 0 {
+		t.Fatalf("expected no headers without span context, got %v", empty)
+	}
+}
+
+func TestExtract_IgnoresTracestateWithoutTraceparent(t *testing.T) {
+	header := http.Header{}
+	header.Set(TraceparentHeader, "garbage")
+	header.Set(TracestateHeader, "congo=t61rcWkgMzE")
+	if _, ok := Extract(header); ok {
+		t.Fatalf("expected no span context")
+	}
+
+	header.Set(TraceparentHeader, validTraceparent)
+	header.Set(TracestateHeader, "not-a-member")
+	sc, ok := Extract(header)
+	if !ok || sc.TraceState != "" {
+		t.Fatalf("expected invalid tracestate to be dropped, got %+v", sc)
+	}
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans and exports them when they end.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer returns a Tracer exporting sampled spans to exporter. A nil exporter
+// propagates trace context without recording spans.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{exporter: exporter}
+}
+
+type tracerKey struct{}
+
+// ContextWithTracer returns a copy of ctx carrying t, used by Start.
+func ContextWithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// TracerFromContext returns the tracer of ctx, or a tracer that exports nothing.
+func TracerFromContext(ctx context.Context) *Tracer {
+	if t, ok := ctx.Value(tracerKey{}).(*Tracer); ok && t != nil {
+		return t
+	}
+	return &Tracer{}
+}
+
+// Start starts a span with the tracer of ctx, for example in a handler behind
+// the tracing middleware.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return TracerFromContext(ctx).Start(ctx, name)
+}
+
+// Start starts a span as a child of the span context of ctx, or as the root of
+// a new sampled trace. The returned context carries the span's context and t.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent, hasParent := SpanContextFromContext(ctx)
+	sc := SpanContext{SpanID: newSpanID()}
+	if hasParent {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.TraceState = parent.TraceState
+	} else {
+		sc.TraceID = newTraceID()
+		sc.Flags = FlagSampled
+	}
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Start:       time.Now(),
+		},
+	}
+	if hasParent {
+		span.data.Parent = parent
+	}
+	ctx = ContextWithSpanContext(ctx, sc)
+	return ContextWithTracer(ctx, t), span
+}
+
+// SpanData is a finished span, as handed to an Exporter.
+type SpanData struct {
+	Name        string
+	SpanContext SpanContext
+	// Parent is the span context of the parent span; invalid for roots.
+	Parent     SpanContext
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]any
+	// Err is the error recorded with RecordError.
+	Err error
+}
+
+// Duration returns End minus Start.
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Span is an operation being traced. Its methods are safe for concurrent use
+// and do nothing after End.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SpanContext returns the span's propagated identity.
+func (s *Span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+// SetName renames the span, for example once the route serving a request is
+// known.
+func (s *Span) SetName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.data.Name = name
+	}
+}
+
+// SetAttribute records a key-value attribute.
+func (s *Span) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]any)
+	}
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed with err.
+func (s *Span) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended && err != nil {
+		s.data.Err = err
+	}
+}
+
+// End finishes the span and exports it when it is sampled and the tracer has
+// an exporter. Export errors are dropped; exporters report their own failures.
+func (s *Span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Attributes = maps.Clone(s.data.Attributes)
+	s.mu.Unlock()
+
+	if s.tracer.exporter != nil && data.SpanContext.IsSampled() {
+		_ = s.tracer.exporter.Export(context.Background(), data)
+	}
+}
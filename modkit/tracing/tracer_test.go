@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTracer_StartsRootAndChildSpans(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	childCtx, child := Start(ctx, "child")
+	child.SetAttribute("k", "v")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End()
+	root.End()
+
+	if sc, ok := SpanContextFromContext(childCtx); !ok || sc != child.SpanContext() {
+		t.Fatalf("expected child span context in ctx")
+	}
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	gotChild, gotRoot := spans[0], spans[1]
+	if gotRoot.Parent.IsValid() || !gotRoot.SpanContext.IsSampled() {
+		t.Fatalf("unexpected root span: %+v", gotRoot)
+	}
+	if gotChild.Parent != gotRoot.SpanContext || gotChild.SpanContext.TraceID != gotRoot.SpanContext.TraceID {
+		t.Fatalf("expected child of root, got %+v", gotChild)
+	}
+	if gotChild.Attributes["k"] != "v" || gotChild.Err == nil || gotChild.Duration() < 0 {
+		t.Fatalf("unexpected child data: %+v", gotChild)
+	}
+
+	exporter.Reset()
+	if len(exporter.Spans()) != 0 {
+		t.Fatalf("expected Reset to clear spans")
+	}
+}
+
+func TestTracer_RespectsUnsampledParent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	parent := SpanContext{TraceID: TraceID{1}, SpanID: SpanID{1}, TraceState: "a=b"}
+
+	ctx := ContextWithSpanContext(context.Background(), parent)
+	_, span := NewTracer(exporter).Start(ctx, "op")
+	span.End()
+
+	if got := span.SpanContext(); got.TraceID != parent.TraceID || got.IsSampled() || got.TraceState != "a=b" {
+		t.Fatalf("expected unsampled child with inherited state, got %+v", got)
+	}
+	if len(exporter.Spans()) != 0 {
+		t.Fatalf("expected unsampled span not to be exported")
+	}
+}
+
+func TestStart_WithoutTracerDoesNotExport(t *testing.T) {
+	ctx, span := Start(context.Background(), "op")
+	span.End()
+	if _, ok := SpanContextFromContext(ctx); !ok {
+		t.Fatalf("expected span context to propagate")
+	}
+}